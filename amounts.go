@@ -0,0 +1,93 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+var weiUnits = map[string]int64{
+	"wei":   params.Wei,
+	"gwei":  params.GWei,
+	"ether": params.Ether,
+	"eth":   params.Ether,
+}
+
+// CoinbaseDiffWei returns the CoinbaseDiff in wei.
+func (self Metadata) CoinbaseDiffWei() (*big.Int, error) {
+	return parseWei("coinbase diff", self.CoinbaseDiff)
+}
+
+// EthSentToCoinbaseWei returns the EthSentToCoinbase in wei.
+func (self Metadata) EthSentToCoinbaseWei() (*big.Int, error) {
+	return parseWei("eth sent to coinbase", self.EthSentToCoinbase)
+}
+
+// GasFeesWei returns the GasFees in wei.
+func (self Metadata) GasFeesWei() (*big.Int, error) {
+	return parseWei("gas fees", self.GasFees)
+}
+
+// BundleGasPriceWei returns the BundleGasPrice in wei.
+func (self Result) BundleGasPriceWei() (*big.Int, error) {
+	return parseWei("bundle gas price", self.BundleGasPrice)
+}
+
+// GasPriceWei returns the GasPrice in wei.
+func (self TxResult) GasPriceWei() (*big.Int, error) {
+	return parseWei("gas price", self.GasPrice)
+}
+
+// parseWei parses the decimal amounts returned by the relays
+// and also accepts hex amounts as some relays return them.
+func parseWei(name, value string) (*big.Int, error) {
+	if strings.HasPrefix(value, "0x") {
+		v, err := hexutil.DecodeBig(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %v:%v", name, value)
+		}
+		return v, nil
+	}
+	v, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, errors.Errorf("parsing %v:%v", name, value)
+	}
+	return v, nil
+}
+
+// ParseWei parses an amount like the bundle gas price returned by the relays,
+// either a decimal or hex number of wei or a decimal with a unit,
+// for example "476190476193", "0x6ee0a8b931", "12.5 gwei" or "0.1eth".
+func ParseWei(value string) (*big.Int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for unit, mul := range weiUnits {
+		if !strings.HasSuffix(value, unit) || (unit == "wei" && strings.HasSuffix(value, "gwei")) {
+			continue
+		}
+		amount, ok := new(big.Rat).SetString(strings.TrimSpace(strings.TrimSuffix(value, unit)))
+		if !ok {
+			return nil, errors.Errorf("parsing amount:%v", value)
+		}
+		amount.Mul(amount, new(big.Rat).SetInt64(mul))
+		if !amount.IsInt() {
+			return nil, errors.Errorf("amount has a fraction of a wei:%v", value)
+		}
+		return amount.Num(), nil
+	}
+	return parseWei("amount", value)
+}
+
+// FormatGwei formats the amount in gwei without trailing zeros, for example "12.5".
+func FormatGwei(wei *big.Int) string {
+	gwei := new(big.Rat).SetFrac(wei, big.NewInt(params.GWei)).FloatString(9)
+	if strings.Contains(gwei, ".") {
+		gwei = strings.TrimRight(strings.TrimRight(gwei, "0"), ".")
+	}
+	return gwei
+}
@@ -0,0 +1,72 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+)
+
+func TestAmounts(t *testing.T) {
+	resp := &Response{}
+	testutil.Ok(t, json.Unmarshal([]byte(`{"result":{
+		"bundleGasPrice":"476190476193",
+		"coinbaseDiff":"20000000000126000",
+		"ethSentToCoinbase":"20000000000000000",
+		"gasFees":"0x1ec30",
+		"results":[{"coinbaseDiff":"10000000000063000","gasPrice":"476190476193","gasFees":"63000"}]
+	}}`), resp))
+
+	for _, tc := range []struct {
+		get func() (*big.Int, error)
+		exp string
+	}{
+		{resp.BundleGasPriceWei, "476190476193"},
+		{resp.CoinbaseDiffWei, "20000000000126000"},
+		{resp.EthSentToCoinbaseWei, "20000000000000000"},
+		{resp.GasFeesWei, "126000"},
+		{resp.Results[0].CoinbaseDiffWei, "10000000000063000"},
+		{resp.Results[0].GasPriceWei, "476190476193"},
+		{resp.Results[0].GasFeesWei, "63000"},
+	} {
+		v, err := tc.get()
+		testutil.Ok(t, err)
+		testutil.Equals(t, tc.exp, v.String())
+	}
+
+	_, err := resp.Results[0].EthSentToCoinbaseWei()
+	testutil.NotOk(t, err)
+}
+
+func TestParseWei(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		exp   string
+	}{
+		{"476190476193", "476190476193"},
+		{"0x1ec30", "126000"},
+		{"12.5 gwei", "12500000000"},
+		{"12.5Gwei", "12500000000"},
+		{"0.1eth", "100000000000000000"},
+		{"2 ether", "2000000000000000000"},
+		{"7 wei", "7"},
+	} {
+		v, err := ParseWei(tc.value)
+		testutil.Ok(t, err)
+		testutil.Equals(t, tc.exp, v.String())
+	}
+
+	for _, value := range []string{"", "abc", "1.5 wei", "0.0000000001 gwei", "x gwei"} {
+		_, err := ParseWei(value)
+		testutil.NotOk(t, err)
+	}
+
+	testutil.Equals(t, "12.5", FormatGwei(big.NewInt(12_500_000_000)))
+	testutil.Equals(t, "476.190476193", FormatGwei(big.NewInt(476190476193)))
+	testutil.Equals(t, "3", FormatGwei(big.NewInt(3_000_000_000)))
+	testutil.Equals(t, "0", FormatGwei(big.NewInt(0)))
+}
@@ -0,0 +1,207 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ApisConfig is the content of the file read by LoadApis.
+type ApisConfig struct {
+	// Relays are created in the order of the file.
+	Relays []ApiConfig `json:"relays"`
+}
+
+// ApiConfig describes a relay in a config file.
+// The fields map to the Api fields with the same names.
+type ApiConfig struct {
+	URL                    string               `json:"url"`
+	Headers                map[string]string    `json:"headers,omitempty"`
+	Methods                map[string]string    `json:"methods,omitempty"`
+	SupportedMethods       []string             `json:"supportedMethods,omitempty"`
+	SupportsSimulation     bool                 `json:"supportsSimulation,omitempty"`
+	SupportsStats          bool                 `json:"supportsStats,omitempty"`
+	SkipFlashbotsSignature bool                 `json:"skipFlashbotsSignature,omitempty"`
+	CompressRequests       bool                 `json:"compressRequests,omitempty"`
+	VerifyBundleHash       bool                 `json:"verifyBundleHash,omitempty"`
+	Proxy                  string               `json:"proxy,omitempty"`
+	Priority               int                  `json:"priority,omitempty"`
+	Weight                 int                  `json:"weight,omitempty"`
+	Timeout                Duration             `json:"timeout,omitempty"`
+	RateLimit              *RateLimitFileConfig `json:"rateLimit,omitempty"`
+	Retry                  *RetryFileConfig     `json:"retry,omitempty"`
+	CircuitBreaker         *BreakerFileConfig   `json:"circuitBreaker,omitempty"`
+	// ClientCertificate is presented during the TLS handshake
+	// for relays that authenticate searchers via mTLS.
+	ClientCertificate *ClientCertFileConfig `json:"clientCertificate,omitempty"`
+	// RootCAFile is a PEM file with the certificates used instead of the system roots.
+	RootCAFile string `json:"rootCAFile,omitempty"`
+}
+
+// RateLimitFileConfig is the RateLimitConfig of a config file.
+type RateLimitFileConfig struct {
+	PerSecond float64 `json:"perSecond"`
+	Burst     int     `json:"burst,omitempty"`
+}
+
+// RetryFileConfig is the RetryConfig of a config file.
+type RetryFileConfig struct {
+	MaxAttempts int      `json:"maxAttempts,omitempty"`
+	BackoffMin  Duration `json:"backoffMin,omitempty"`
+	BackoffMax  Duration `json:"backoffMax,omitempty"`
+	StatusCodes []int    `json:"statusCodes,omitempty"`
+}
+
+// BreakerFileConfig is the CircuitBreakerConfig of a config file.
+type BreakerFileConfig struct {
+	Threshold int      `json:"threshold,omitempty"`
+	CoolDown  Duration `json:"coolDown,omitempty"`
+}
+
+// ClientCertFileConfig are the PEM files of the client certificate and key.
+type ClientCertFileConfig struct {
+	// CertFile is the PEM encoded certificate.
+	CertFile string `json:"certFile"`
+	// KeyFile is the PEM encoded private key of the certificate.
+	KeyFile string `json:"keyFile"`
+}
+
+// Duration is a time.Duration written as a string like 1.5s in config files.
+type Duration time.Duration
+
+func (self Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(self).String())
+}
+
+func (self *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.Errorf("duration should be a string like 1.5s:%s", data)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.Wrapf(err, "parsing duration:%v", s)
+	}
+	*self = Duration(d)
+	return nil
+}
+
+// LoadApis reads the relays from a JSON or YAML file chosen by the file extension.
+// Unknown fields are rejected so that typos don't go unnoticed.
+func LoadApis(path string) ([]*Api, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		return nil, errors.Errorf("unsupported config file extension:%v", ext)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading config file")
+	}
+	if ext != ".json" {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := &ApisConfig{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, errors.Wrapf(err, "decoding config file:%v", path)
+	}
+	return cfg.Apis()
+}
+
+// Apis creates the apis of the relays.
+func (self *ApisConfig) Apis() ([]*Api, error) {
+	if len(self.Relays) == 0 {
+		return nil, errors.New("config has no relays")
+	}
+	apis := make([]*Api, 0, len(self.Relays))
+	for i, r := range self.Relays {
+		api, err := r.Api()
+		if err != nil {
+			return nil, errors.Wrapf(err, "relay:%v", i)
+		}
+		apis = append(apis, api)
+	}
+	return apis, nil
+}
+
+// Api creates the api of the relay and loads its certificates.
+func (self ApiConfig) Api() (*Api, error) {
+	if self.URL == "" {
+		return nil, errors.New("url is required")
+	}
+	api := &Api{
+		URL:                    self.URL,
+		CustomHeaders:          self.Headers,
+		Methods:                self.Methods,
+		SupportedMethods:       self.SupportedMethods,
+		SupportsSimulation:     self.SupportsSimulation,
+		SupportsStats:          self.SupportsStats,
+		SkipFlashbotsSignature: self.SkipFlashbotsSignature,
+		CompressRequests:       self.CompressRequests,
+		VerifyBundleHash:       self.VerifyBundleHash,
+		Proxy:                  self.Proxy,
+		Priority:               self.Priority,
+		Weight:                 self.Weight,
+		Timeout:                time.Duration(self.Timeout),
+	}
+	if self.RateLimit != nil {
+		api.RateLimit = &RateLimitConfig{PerSecond: self.RateLimit.PerSecond, Burst: self.RateLimit.Burst}
+	}
+	if self.Retry != nil {
+		api.Retry = &RetryConfig{
+			MaxAttempts: self.Retry.MaxAttempts,
+			BackoffMin:  time.Duration(self.Retry.BackoffMin),
+			BackoffMax:  time.Duration(self.Retry.BackoffMax),
+			StatusCodes: self.Retry.StatusCodes,
+		}
+	}
+	if self.CircuitBreaker != nil {
+		api.CircuitBreaker = &CircuitBreakerConfig{
+			Threshold: self.CircuitBreaker.Threshold,
+			CoolDown:  time.Duration(self.CircuitBreaker.CoolDown),
+		}
+	}
+	if self.ClientCertificate != nil {
+		if err := api.LoadClientCertificate(self.ClientCertificate.CertFile, self.ClientCertificate.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	if self.RootCAFile != "" {
+		pem, err := os.ReadFile(self.RootCAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading root CA file")
+		}
+		api.RootCAs = x509.NewCertPool()
+		if !api.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificates in the root CA file:%v", self.RootCAFile)
+		}
+	}
+	return api, nil
+}
+
+// yamlToJSON converts the YAML so that both formats are decoded with the same field names.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, "decoding yaml")
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting yaml to json")
+	}
+	return data, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+)
+
+func TestLoadApis(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "relays.yaml")
+	testutil.Ok(t, os.WriteFile(yamlPath, []byte(`
+relays:
+  - url: https://relay.flashbots.net
+    supportsSimulation: true
+    supportsStats: true
+    timeout: 2s
+    retry:
+      maxAttempts: 3
+      backoffMin: 100ms
+    rateLimit:
+      perSecond: 5
+      burst: 2
+  - url: https://builder.example
+    headers:
+      x-api-key: key
+    methods:
+      eth_sendBundle: builder_sendBundle
+    supportedMethods: [eth_sendBundle]
+    circuitBreaker:
+      threshold: 3
+      coolDown: 1m
+`), 0o600))
+	jsonPath := filepath.Join(dir, "relays.json")
+	testutil.Ok(t, os.WriteFile(jsonPath, []byte(`{"relays":[
+		{"url":"https://relay.flashbots.net","supportsSimulation":true,"supportsStats":true,"timeout":"2s",
+		 "retry":{"maxAttempts":3,"backoffMin":"100ms"},"rateLimit":{"perSecond":5,"burst":2}},
+		{"url":"https://builder.example","headers":{"x-api-key":"key"},"methods":{"eth_sendBundle":"builder_sendBundle"},
+		 "supportedMethods":["eth_sendBundle"],"circuitBreaker":{"threshold":3,"coolDown":"1m"}}
+	]}`), 0o600))
+
+	expected := []*Api{
+		{
+			URL:                "https://relay.flashbots.net",
+			SupportsSimulation: true,
+			SupportsStats:      true,
+			Timeout:            2 * time.Second,
+			Retry:              &RetryConfig{MaxAttempts: 3, BackoffMin: 100 * time.Millisecond},
+			RateLimit:          &RateLimitConfig{PerSecond: 5, Burst: 2},
+		},
+		{
+			URL:              "https://builder.example",
+			CustomHeaders:    map[string]string{"x-api-key": "key"},
+			Methods:          map[string]string{MethodSendBundle: "builder_sendBundle"},
+			SupportedMethods: []string{MethodSendBundle},
+			CircuitBreaker:   &CircuitBreakerConfig{Threshold: 3, CoolDown: time.Minute},
+		},
+	}
+	for _, path := range []string{yamlPath, jsonPath} {
+		apis, err := LoadApis(path)
+		testutil.Ok(t, err)
+		testutil.Equals(t, expected, apis, "path:%v", path)
+	}
+
+	testutil.Ok(t, os.WriteFile(yamlPath, []byte("relays:\n  - url: https://relay.flashbots.net\n    timeot: 2s\n"), 0o600))
+	_, err := LoadApis(yamlPath)
+	testutil.Assert(t, err != nil && strings.Contains(err.Error(), "timeot"), "unknown fields should be rejected:%v", err)
+
+	testutil.Ok(t, os.WriteFile(jsonPath, []byte(`{"relays":[{"url":"https://relay.flashbots.net","timeout":2}]}`), 0o600))
+	_, err = LoadApis(jsonPath)
+	testutil.NotOk(t, err)
+
+	_, err = LoadApis(filepath.Join(dir, "relays.toml"))
+	testutil.Assert(t, err != nil && strings.Contains(err.Error(), "extension"), "unexpected error:%v", err)
+}
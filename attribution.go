@@ -0,0 +1,207 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// BlockReader is the subset of the ethclient.Client methods used to inspect a mined block.
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// CompetitorSource is where a competing transaction most likely came from.
+type CompetitorSource string
+
+const (
+	SourceUnknown CompetitorSource = "unknown"
+	// SourceBundle is a transaction that wasn't public, for example
+	// one that pays the coinbase directly or has no priority fee.
+	SourceBundle  CompetitorSource = "bundle"
+	SourceMempool CompetitorSource = "mempool"
+)
+
+// ConflictReason is why a block transaction competes with the bundle.
+type ConflictReason string
+
+const (
+	// ConflictNonce is a transaction of a bundle sender with the same nonce,
+	// the bundle can't be included after it.
+	ConflictNonce ConflictReason = "nonce"
+	// ConflictContract is a transaction calling or emitting logs from a contract the bundle calls.
+	ConflictContract ConflictReason = "contract"
+)
+
+// Competitor is a block transaction that took the opportunity of the bundle.
+type Competitor struct {
+	Hash   common.Hash
+	Index  int
+	From   common.Address
+	To     *common.Address
+	Reason ConflictReason
+	// Contract is the contract shared with the bundle for ConflictContract.
+	Contract common.Address
+	Source   CompetitorSource
+}
+
+// Attribution explains the outcome of a bundle for its target block.
+type Attribution struct {
+	BlockNum uint64
+	Coinbase common.Address
+	// Included is set when all bundle transactions are in the block.
+	Included    bool
+	Competitors []Competitor
+}
+
+// AttributionConfig configures AttributeMiss.
+type AttributionConfig struct {
+	// CheckLogs also matches transactions by the contracts that emitted their logs
+	// which catches calls through routers but reads the receipt of every block transaction.
+	CheckLogs bool
+	// SeenInMempool reports whether a transaction was seen in the public mempool,
+	// for example by a pending transactions subscription.
+	// When nil the source is guessed from how the transaction pays the block builder
+	// and a transaction with a priority fee and no coinbase payment counts as mempool.
+	SeenInMempool func(hash common.Hash) bool
+}
+
+// AttributeMiss inspects the target block of a bundle that wasn't included and reports the
+// transactions that used the same sender nonces or called the same contracts
+// and whether they most likely came from a competing bundle or the public mempool.
+func AttributeMiss(ctx context.Context, blocks BlockReader, txsHex []string, blockNum uint64, cfg AttributionConfig) (*Attribution, error) {
+	bundleHashes := make(map[common.Hash]bool)
+	nonces := make(map[common.Address]map[uint64]bool)
+	contracts := make(map[common.Address]bool)
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		sender, err := txSender(tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		bundleHashes[tx.Hash()] = true
+		if nonces[sender] == nil {
+			nonces[sender] = make(map[uint64]bool)
+		}
+		nonces[sender][tx.Nonce()] = true
+		if tx.To() != nil {
+			contracts[*tx.To()] = true
+		}
+	}
+
+	block, err := blocks.BlockByNumber(ctx, new(big.Int).SetUint64(blockNum))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading block:%v", blockNum)
+	}
+	result := &Attribution{BlockNum: blockNum, Coinbase: block.Coinbase()}
+
+	blockTxs := block.Transactions()
+	found := 0
+	for _, tx := range blockTxs {
+		if bundleHashes[tx.Hash()] {
+			found++
+		}
+	}
+	if found == len(bundleHashes) {
+		result.Included = true
+		return result, nil
+	}
+
+	for i, tx := range blockTxs {
+		if bundleHashes[tx.Hash()] {
+			continue
+		}
+		sender, err := txSender(tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "block tx:%v", tx.Hash().Hex())
+		}
+		c := Competitor{Hash: tx.Hash(), Index: i, From: sender, To: tx.To()}
+		switch {
+		case nonces[sender][tx.Nonce()]:
+			c.Reason = ConflictNonce
+		case tx.To() != nil && contracts[*tx.To()]:
+			c.Reason = ConflictContract
+			c.Contract = *tx.To()
+		case cfg.CheckLogs:
+			contract, ok, err := logsTouch(ctx, blocks, tx.Hash(), contracts)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			c.Reason = ConflictContract
+			c.Contract = contract
+		default:
+			continue
+		}
+		c.Source = competitorSource(tx, sender, i, block, cfg)
+		result.Competitors = append(result.Competitors, c)
+	}
+	return result, nil
+}
+
+func logsTouch(ctx context.Context, blocks BlockReader, hash common.Hash, contracts map[common.Address]bool) (common.Address, bool, error) {
+	receipt, err := blocks.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return common.Address{}, false, nil
+	}
+	if err != nil {
+		return common.Address{}, false, errors.Wrapf(err, "reading receipt tx:%v", hash.Hex())
+	}
+	for _, l := range receipt.Logs {
+		if contracts[l.Address] {
+			return l.Address, true, nil
+		}
+	}
+	return common.Address{}, false, nil
+}
+
+// competitorSource guesses that transactions which pay the builder outside of the
+// priority fee are private as such payments aren't prioritized in the public mempool
+// and the rest came from the mempool.
+func competitorSource(tx *types.Transaction, sender common.Address, index int, block *types.Block, cfg AttributionConfig) CompetitorSource {
+	if cfg.SeenInMempool != nil {
+		if cfg.SeenInMempool(tx.Hash()) {
+			return SourceMempool
+		}
+		return SourceBundle
+	}
+
+	baseFee := block.BaseFee()
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	tip, err := tx.EffectiveGasTip(baseFee)
+	if err != nil {
+		return SourceUnknown
+	}
+	if tip.Sign() == 0 {
+		return SourceBundle
+	}
+	// A payment to the coinbase by the same sender later in the block.
+	txs := block.Transactions()
+	for _, next := range txs[index+1:] {
+		if next.To() == nil || *next.To() != block.Coinbase() {
+			continue
+		}
+		if from, err := txSender(next); err == nil && from == sender {
+			return SourceBundle
+		}
+	}
+	if tx.To() != nil && *tx.To() == block.Coinbase() {
+		return SourceBundle
+	}
+	return SourceMempool
+}
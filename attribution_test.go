@@ -0,0 +1,120 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+type blocksMock struct {
+	block    *types.Block
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (self *blocksMock) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return self.block, nil
+}
+
+func (self *blocksMock) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	r, ok := self.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return r, nil
+}
+
+func TestAttributeMiss(t *testing.T) {
+	chainID := big.NewInt(1)
+	signer := types.LatestSignerForChainID(chainID)
+	newTx := func(key *ecdsa.PrivateKey, nonce uint64, to common.Address, tip int64) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID: chainID, Nonce: nonce, To: &to, Gas: 21000,
+			GasTipCap: big.NewInt(tip), GasFeeCap: big.NewInt(100),
+		})
+		testutil.Ok(t, err)
+		return tx
+	}
+	encode := func(tx *types.Transaction) string {
+		raw, err := tx.MarshalBinary()
+		testutil.Ok(t, err)
+		return hexutil.Encode(raw)
+	}
+
+	bot, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	rival, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	user, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	pool := common.HexToAddress("0xaa")
+	router := common.HexToAddress("0xbb")
+	coinbase := common.HexToAddress("0xcc")
+
+	bundleTx := newTx(bot, 5, pool, 1)
+	bundle := []string{encode(bundleTx)}
+
+	replaced := newTx(bot, 5, common.HexToAddress("0x1"), 2)
+	rivalTx := newTx(rival, 0, pool, 0)
+	userTx := newTx(user, 0, router, 2)
+	unrelated := newTx(user, 1, common.HexToAddress("0x2"), 2)
+	blockTxs := []*types.Transaction{rivalTx, replaced, userTx, unrelated}
+	header := &types.Header{Number: big.NewInt(11), Coinbase: coinbase, BaseFee: big.NewInt(10)}
+	blocks := &blocksMock{
+		block: types.NewBlock(header, blockTxs, nil, nil, trie.NewStackTrie(nil)),
+		receipts: map[common.Hash]*types.Receipt{
+			userTx.Hash(): {Logs: []*types.Log{{Address: pool}}},
+		},
+	}
+	ctx := context.Background()
+
+	res, err := AttributeMiss(ctx, blocks, bundle, 11, AttributionConfig{})
+	testutil.Ok(t, err)
+	testutil.Assert(t, !res.Included, "bundle shouldn't be included")
+	testutil.Equals(t, coinbase, res.Coinbase)
+	testutil.Equals(t, 2, len(res.Competitors))
+	testutil.Equals(t, rivalTx.Hash(), res.Competitors[0].Hash)
+	testutil.Equals(t, ConflictContract, res.Competitors[0].Reason)
+	testutil.Equals(t, pool, res.Competitors[0].Contract)
+	testutil.Equals(t, SourceBundle, res.Competitors[0].Source)
+	testutil.Equals(t, replaced.Hash(), res.Competitors[1].Hash)
+	testutil.Equals(t, ConflictNonce, res.Competitors[1].Reason)
+	testutil.Equals(t, SourceMempool, res.Competitors[1].Source)
+
+	// A coinbase payment by the same sender later in the block is private.
+	payment := newTx(bot, 6, coinbase, 2)
+	blocks.block = types.NewBlock(header, []*types.Transaction{replaced, payment}, nil, nil, trie.NewStackTrie(nil))
+	res, err = AttributeMiss(ctx, blocks, bundle, 11, AttributionConfig{})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(res.Competitors))
+	testutil.Equals(t, SourceBundle, res.Competitors[0].Source)
+	blocks.block = types.NewBlock(header, blockTxs, nil, nil, trie.NewStackTrie(nil))
+
+	// Calls through the router are found by the logs.
+	res, err = AttributeMiss(ctx, blocks, bundle, 11, AttributionConfig{
+		CheckLogs:     true,
+		SeenInMempool: func(hash common.Hash) bool { return hash == userTx.Hash() },
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(res.Competitors))
+	testutil.Equals(t, userTx.Hash(), res.Competitors[2].Hash)
+	testutil.Equals(t, SourceMempool, res.Competitors[2].Source)
+	testutil.Equals(t, SourceBundle, res.Competitors[1].Source)
+
+	// Included.
+	blocks.block = types.NewBlock(header, []*types.Transaction{bundleTx}, nil, nil, trie.NewStackTrie(nil))
+	res, err = AttributeMiss(ctx, blocks, bundle, 11, AttributionConfig{})
+	testutil.Ok(t, err)
+	testutil.Assert(t, res.Included, "bundle should be included")
+}
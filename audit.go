@@ -0,0 +1,196 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ErrAuditChainBroken is returned by VerifyAuditLog when a record
+// was changed, removed or inserted.
+var ErrAuditChainBroken = errors.New("audit log hash chain broken")
+
+// AuditRecord is a payload signed with the reputation key.
+// Hash chains the record to the previous one so changing or removing
+// a record breaks the chain of all records after it.
+type AuditRecord struct {
+	Seq    uint64         `json:"seq"`
+	Time   time.Time      `json:"time"`
+	Relay  string         `json:"relay"`
+	Method string         `json:"method"`
+	Signer common.Address `json:"signer"`
+	// Signature is the X-Flashbots-Signature header sent with the payload.
+	Signature string          `json:"signature"`
+	Payload   json.RawMessage `json:"payload"`
+	PrevHash  common.Hash     `json:"prevHash"`
+	Hash      common.Hash     `json:"hash"`
+}
+
+// hash is the keccak256 of the record encoded without its own hash.
+func (self AuditRecord) hash() (common.Hash, error) {
+	self.Hash = common.Hash{}
+	data, err := json.Marshal(self)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "encoding audit record")
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// AuditLog appends every signed payload to a hash-chained file of JSON lines
+// so that it can be proven what was submitted with the reputation key.
+type AuditLog struct {
+	mtx  sync.Mutex
+	file *os.File
+	seq  uint64
+	last common.Hash
+}
+
+// OpenAuditLog opens or creates the audit log and continues its chain.
+// The existing records are verified so that a tampered log isn't extended.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening audit log")
+	}
+	last, err := verifyAuditLog(file)
+	if err != nil {
+		if errClose := file.Close(); errClose != nil {
+			return nil, errors.Wrapf(err, "closing audit log:%v", errClose)
+		}
+		return nil, err
+	}
+	auditLog := &AuditLog{file: file}
+	if last != nil {
+		auditLog.seq = last.Seq + 1
+		auditLog.last = last.Hash
+	}
+	return auditLog, nil
+}
+
+// WithAuditLog records every payload signed with the reputation key before sending it.
+// A request fails without being sent when its payload can't be recorded.
+func WithAuditLog(auditLog *AuditLog) Option {
+	return func(f *Flashbot) {
+		f.auditLog = auditLog
+	}
+}
+
+// Record appends the signed payload to the log and syncs it to the disk.
+func (self *AuditLog) Record(relay, method string, signer common.Address, signature string, payload []byte) (*AuditRecord, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	rec := AuditRecord{
+		Seq:       self.seq,
+		Time:      time.Now().UTC(),
+		Relay:     relay,
+		Method:    method,
+		Signer:    signer,
+		Signature: signature,
+		Payload:   payload,
+		PrevHash:  self.last,
+	}
+	var err error
+	if rec.Hash, err = rec.hash(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding audit record")
+	}
+	if _, err := self.file.Write(append(data, '\n')); err != nil {
+		return nil, errors.Wrap(err, "writing audit record")
+	}
+	if err := self.file.Sync(); err != nil {
+		return nil, errors.Wrap(err, "syncing audit log")
+	}
+	self.seq++
+	self.last = rec.Hash
+	return &rec, nil
+}
+
+// Head returns the sequence number and the hash of the last record.
+// Storing it outside of the log, for example by publishing it periodically,
+// also detects removing the most recent records.
+func (self *AuditLog) Head() (uint64, common.Hash) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if self.seq == 0 {
+		return 0, common.Hash{}
+	}
+	return self.seq - 1, self.last
+}
+
+func (self *AuditLog) Close() error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.file.Close()
+}
+
+// VerifyAuditLog checks the hash chain of the audit log and returns its records.
+func VerifyAuditLog(path string) ([]AuditRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening audit log")
+	}
+	defer file.Close()
+
+	var recs []AuditRecord
+	if err := readAuditLog(file, func(rec AuditRecord) { recs = append(recs, rec) }); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+func verifyAuditLog(file *os.File) (*AuditRecord, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "reading audit log")
+	}
+	var last *AuditRecord
+	err := readAuditLog(file, func(rec AuditRecord) { last = &rec })
+	return last, err
+}
+
+// readAuditLog verifies the records while reading them.
+func readAuditLog(r io.Reader, fn func(AuditRecord)) error {
+	var (
+		prev common.Hash
+		seq  uint64
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), journalMaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var rec AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return errors.Wrapf(err, "decoding audit record line:%v", line)
+		}
+		hash, err := rec.hash()
+		if err != nil {
+			return err
+		}
+		if rec.Seq != seq || rec.PrevHash != prev || rec.Hash != hash {
+			return errors.Wrapf(ErrAuditChainBroken, "line:%v seq:%v", line, rec.Seq)
+		}
+		fn(rec)
+		prev = rec.Hash
+		seq++
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "reading audit log")
+	}
+	return nil
+}
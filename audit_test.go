@@ -0,0 +1,74 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestAuditLog(t *testing.T) {
+	var signatures []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.Header.Get("X-Flashbots-Signature"))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	path := filepath.Join(t.TempDir(), "audit")
+	ctx := context.Background()
+
+	auditLog, err := OpenAuditLog(path)
+	testutil.Ok(t, err)
+	flashbot, err := New(privKey, &Api{URL: srv.URL}, WithAuditLog(auditLog))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(ctx, []string{signedTxHex(t)}, 11, nil)
+	testutil.Ok(t, err)
+	testutil.Ok(t, auditLog.Close())
+
+	// The chain continues after reopening.
+	auditLog, err = OpenAuditLog(path)
+	testutil.Ok(t, err)
+	flashbot, err = New(privKey, &Api{URL: srv.URL}, WithAuditLog(auditLog))
+	testutil.Ok(t, err)
+	_, err = flashbot.CallBundle(ctx, []string{signedTxHex(t)}, 11, nil)
+	testutil.NotOk(t, err) // The relay doesn't support simulations so nothing is sent.
+	_, err = flashbot.SendBundle(ctx, []string{signedTxHex(t)}, 12, nil)
+	testutil.Ok(t, err)
+	seq, head := auditLog.Head()
+	testutil.Ok(t, auditLog.Close())
+
+	recs, err := VerifyAuditLog(path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(recs))
+	testutil.Equals(t, uint64(1), seq)
+	testutil.Equals(t, head, recs[1].Hash)
+	testutil.Equals(t, recs[0].Hash, recs[1].PrevHash)
+	for i, rec := range recs {
+		testutil.Equals(t, MethodSendBundle, rec.Method)
+		testutil.Equals(t, srv.URL, rec.Relay)
+		testutil.Equals(t, crypto.PubkeyToAddress(privKey.PublicKey), rec.Signer)
+		testutil.Equals(t, signatures[i], rec.Signature)
+	}
+
+	// Tampering breaks the chain.
+	data, err := os.ReadFile(path)
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.WriteFile(path, bytes.Replace(data, []byte("0xb"), []byte("0xc"), 1), 0o600))
+	_, err = VerifyAuditLog(path)
+	testutil.Assert(t, errors.Is(err, ErrAuditChainBroken), "unexpected error:%v", err)
+	_, err = OpenAuditLog(path)
+	testutil.Assert(t, errors.Is(err, ErrAuditChainBroken), "a broken log shouldn't be extended:%v", err)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Package awskms signs the relay requests with a secp256k1 key kept in AWS KMS
+// so the searcher reputation key never leaves the KMS.
+// The key must be created with the ECC_SECG_P256K1 key spec and the SIGN_VERIFY usage.
+package awskms
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const signTimeoutDefault = 10 * time.Second
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// Client is the part of *kms.Client used by the signer.
+type Client interface {
+	GetPublicKey(ctx context.Context, params *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error)
+	Sign(ctx context.Context, params *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error)
+}
+
+// Signer implements flashbot.Signer with a KMS key.
+type Signer struct {
+	client Client
+	keyID  string
+	pubKey []byte
+	addr   common.Address
+
+	// Timeout of a single KMS signing request, defaults to 10s.
+	Timeout time.Duration
+}
+
+var _ flashbot.Signer = &Signer{}
+
+// NewSigner fetches the public key of the KMS key to derive its address.
+func NewSigner(ctx context.Context, client Client, keyID string) (*Signer, error) {
+	out, err := client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting public key:%v", keyID)
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(out.PublicKey, &spki); err != nil {
+		return nil, errors.Wrap(err, "parsing public key")
+	}
+	pubKey, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing secp256k1 public key")
+	}
+
+	return &Signer{
+		client:  client,
+		keyID:   keyID,
+		pubKey:  spki.PublicKey.Bytes,
+		addr:    crypto.PubkeyToAddress(*pubKey),
+		Timeout: signTimeoutDefault,
+	}, nil
+}
+
+func (self *Signer) Address() common.Address {
+	return self.addr
+}
+
+// Sign returns the signature in the Ethereum format.
+// KMS returns DER encoded signatures without the recovery id
+// so S is normalized to the lower half of the curve order
+// and the recovery id is found by recovering the public key.
+func (self *Signer) Sign(digest []byte) ([]byte, error) {
+	ctx, cncl := context.WithTimeout(context.Background(), self.Timeout)
+	defer cncl()
+
+	out, err := self.client.Sign(ctx, &kms.SignInput{
+		KeyId:            aws.String(self.keyID),
+		Message:          digest,
+		MessageType:      types.MessageTypeDigest,
+		SigningAlgorithm: types.SigningAlgorithmSpecEcdsaSha256,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "kms sign")
+	}
+
+	var sigDER struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(out.Signature, &sigDER); err != nil {
+		return nil, errors.Wrap(err, "parsing kms signature")
+	}
+	if sigDER.S.Cmp(secp256k1HalfN) > 0 {
+		sigDER.S = new(big.Int).Sub(secp256k1N, sigDER.S)
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	sigDER.R.FillBytes(sig[0:32])
+	sigDER.S.FillBytes(sig[32:64])
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		pubKey, err := crypto.Ecrecover(digest, sig)
+		if err == nil && bytes.Equal(pubKey, self.pubKey) {
+			return sig, nil
+		}
+	}
+	return nil, errors.New("kms signature doesn't match the public key")
+}
@@ -0,0 +1,84 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package awskms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+type clientMock struct {
+	prvKey *ecdsa.PrivateKey
+	highS  bool
+}
+
+func (self *clientMock) GetPublicKey(ctx context.Context, params *kms.GetPublicKeyInput, optFns ...func(*kms.Options)) (*kms.GetPublicKeyOutput, error) {
+	pubKey := crypto.FromECDSAPub(&self.prvKey.PublicKey)
+	der, err := asn1.Marshal(struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1},
+			Parameters: asn1.RawValue{FullBytes: []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}},
+		},
+		PublicKey: asn1.BitString{Bytes: pubKey, BitLength: len(pubKey) * 8},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GetPublicKeyOutput{PublicKey: der}, nil
+}
+
+func (self *clientMock) Sign(ctx context.Context, params *kms.SignInput, optFns ...func(*kms.Options)) (*kms.SignOutput, error) {
+	if params.MessageType != types.MessageTypeDigest {
+		return nil, errors.Errorf("unexpected message type:%v", params.MessageType)
+	}
+	sig, err := crypto.Sign(params.Message, self.prvKey)
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if self.highS {
+		s.Sub(secp256k1N, s)
+	}
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		return nil, err
+	}
+	return &kms.SignOutput{Signature: der}, nil
+}
+
+func TestSigner(t *testing.T) {
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	addr := crypto.PubkeyToAddress(prvKey.PublicKey)
+
+	for _, highS := range []bool{false, true} {
+		signer, err := NewSigner(context.Background(), &clientMock{prvKey: prvKey, highS: highS}, "key")
+		testutil.Ok(t, err)
+		testutil.Equals(t, addr, signer.Address())
+
+		for i := 0; i < 10; i++ {
+			digest := crypto.Keccak256([]byte{byte(i)})
+			sig, err := signer.Sign(digest)
+			testutil.Ok(t, err)
+			pubKey, err := crypto.SigToPub(digest, sig)
+			testutil.Ok(t, err)
+			testutil.Equals(t, addr, crypto.PubkeyToAddress(*pubKey))
+			testutil.Assert(t, crypto.ValidateSignatureValues(sig[64], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), true), "signature should be valid with a low S")
+		}
+	}
+}
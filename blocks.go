@@ -0,0 +1,143 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// SendBundleForBlocksOpts configures SendBundleForBlocks.
+type SendBundleForBlocksOpts struct {
+	// SendBundleOpts are used for every block except the ReplacementUuid
+	// which is derived for each block with BlockReplacementUuid when sending for more than one block
+	// as with the same uuid every submission would replace the previous one.
+	SendBundleOpts
+	// Concurrency limits the number of submissions in flight, 0 sends to all blocks at once.
+	Concurrency int
+	// Included is checked before each submission and
+	// once it reports true the remaining blocks are skipped.
+	// It is only useful with a low concurrency so that
+	// the later submissions wait for the earlier blocks.
+	Included func(ctx context.Context) (bool, error)
+}
+
+type BlockBundleResult struct {
+	BlockNum uint64
+	// ReplacementUuid is the uuid the bundle of this block was sent with.
+	ReplacementUuid string
+	Resp            *Response
+	Err             error
+	// Skipped is set when the bundle wasn't sent because it was already included.
+	Skipped bool
+}
+
+// SendBundleForBlocks sends the same bundle for count blocks starting at fromBlock
+// and returns the results ordered by block.
+// An error is returned only when all submissions failed.
+func SendBundleForBlocks(ctx context.Context, flashbot Flashboter, txsHex []string, fromBlock, count uint64, opts *SendBundleForBlocksOpts) ([]BlockBundleResult, error) {
+	if count == 0 {
+		return nil, errors.New("block count can't be zero")
+	}
+	if opts == nil {
+		opts = &SendBundleForBlocksOpts{}
+	}
+	concurrency := int(count)
+	if opts.Concurrency > 0 && opts.Concurrency < concurrency {
+		concurrency = opts.Concurrency
+	}
+
+	var base string
+	if count > 1 && opts.ReplacementUuid != "" {
+		base = opts.ReplacementUuid
+		if _, err := uuid.Parse(base); err != nil {
+			return nil, errors.Wrapf(err, "parsing replacement uuid:%v", base)
+		}
+	}
+
+	ctx, _ = ensureCorrelationID(ctx)
+
+	var (
+		included bool
+		mtx      sync.Mutex
+	)
+	isIncluded := func() (bool, error) {
+		if opts.Included == nil {
+			return false, nil
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		if included {
+			return true, nil
+		}
+		ok, err := opts.Included(ctx)
+		if err != nil {
+			return false, errors.Wrap(err, "checking inclusion")
+		}
+		included = ok
+		return ok, nil
+	}
+
+	results := make([]BlockBundleResult, count)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := uint64(0); i < count; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i uint64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			blockNum := fromBlock + i
+			results[i].BlockNum = blockNum
+
+			ok, err := isIncluded()
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+			if ok {
+				results[i].Skipped = true
+				return
+			}
+			sendOpts := opts.SendBundleOpts
+			if base != "" {
+				sendOpts.ReplacementUuid, _ = BlockReplacementUuid(base, blockNum)
+			}
+			results[i].ReplacementUuid = sendOpts.ReplacementUuid
+			results[i].Resp, results[i].Err = flashbot.SendBundle(ctx, txsHex, blockNum, &sendOpts)
+		}(i)
+	}
+	wg.Wait()
+
+	var (
+		lastErr error
+		failed  int
+	)
+	for _, r := range results {
+		if r.Err != nil {
+			lastErr = r.Err
+			failed++
+		}
+	}
+	if failed == len(results) {
+		return results, errors.Wrap(lastErr, "all submissions failed")
+	}
+
+	return results, nil
+}
+
+// BlockReplacementUuid derives the uuid used by SendBundleForBlocks for the bundle of a block
+// so that it can be replaced or cancelled later.
+func BlockReplacementUuid(replacementUuid string, blockNum uint64) (string, error) {
+	base, err := uuid.Parse(replacementUuid)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing replacement uuid:%v", replacementUuid)
+	}
+	return uuid.NewSHA1(base, []byte(strconv.FormatUint(blockNum, 10))).String(), nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSendBundleForBlocks(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		blocks []string
+		uuids  = make(map[string]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		var params []ParamsSend
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		mtx.Lock()
+		blocks = append(blocks, params[0].BlockNum)
+		uuids[params[0].BlockNum] = params[0].ReplacementUuid
+		mtx.Unlock()
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	ctx := context.Background()
+	results, err := SendBundleForBlocks(ctx, flashbot, []string{"0x1"}, 10, 3, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(results))
+	for i, r := range results {
+		testutil.Ok(t, r.Err)
+		testutil.Equals(t, uint64(10+i), r.BlockNum)
+		testutil.Equals(t, "0x1", r.Resp.BundleHash)
+	}
+	sort.Strings(blocks)
+	testutil.Equals(t, []string{"0xa", "0xb", "0xc"}, blocks)
+
+	// Stops once the bundle is reported as included.
+	blocks = nil
+	checks := 0
+	results, err = SendBundleForBlocks(ctx, flashbot, []string{"0x1"}, 10, 4, &SendBundleForBlocksOpts{
+		Concurrency: 1,
+		Included: func(context.Context) (bool, error) {
+			checks++
+			return checks > 2, nil
+		},
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"0xa", "0xb"}, blocks)
+	testutil.Assert(t, !results[1].Skipped, "second block should be sent")
+	testutil.Assert(t, results[2].Skipped && results[3].Skipped, "blocks after inclusion should be skipped")
+
+	// Each block gets its own uuid so the submissions don't replace each other.
+	base := "2ba8c8c7-9b52-4e1a-9d33-7f5f2a6a8c11"
+	results, err = SendBundleForBlocks(ctx, flashbot, []string{"0x1"}, 10, 2, &SendBundleForBlocksOpts{
+		SendBundleOpts: SendBundleOpts{ReplacementUuid: base},
+	})
+	testutil.Ok(t, err)
+	for _, r := range results {
+		expected, err := BlockReplacementUuid(base, r.BlockNum)
+		testutil.Ok(t, err)
+		testutil.Equals(t, expected, r.ReplacementUuid)
+		testutil.Equals(t, expected, uuids[hexutil.EncodeUint64(r.BlockNum)])
+	}
+	testutil.Assert(t, uuids["0xa"] != uuids["0xb"] && uuids["0xa"] != base, "uuids should differ per block:%v", uuids)
+
+	_, err = SendBundleForBlocks(ctx, flashbot, []string{"0x1"}, 10, 2, &SendBundleForBlocksOpts{
+		SendBundleOpts: SendBundleOpts{ReplacementUuid: "not-a-uuid"},
+	})
+	testutil.NotOk(t, err)
+
+	// A single block keeps the uuid of the caller.
+	results, err = SendBundleForBlocks(ctx, flashbot, []string{"0x1"}, 12, 1, &SendBundleForBlocksOpts{
+		SendBundleOpts: SendBundleOpts{ReplacementUuid: base},
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, base, results[0].ReplacementUuid)
+	testutil.Equals(t, base, uuids["0xc"])
+}
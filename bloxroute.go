@@ -0,0 +1,116 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	BloxrouteURL = "https://mev.api.blxrbdn.com"
+	// BloxrouteCloudURL is the bloXroute Cloud API which serves the BSC and Polygon bundles.
+	BloxrouteCloudURL = "https://api.blxrbdn.com"
+
+	MethodBloxrouteSubmitBundle   = "blxr_submit_bundle"
+	MethodBloxrouteSimulateBundle = "blxr_simulate_bundle"
+)
+
+// ParamsBloxrouteBundle is the request schema used by the bloXroute bundle methods.
+// The transactions are sent without the 0x prefix.
+type ParamsBloxrouteBundle struct {
+	Transaction      []string `json:"transaction"`
+	BlockNumber      string   `json:"block_number,omitempty"`
+	StateBlockNumber string   `json:"state_block_number,omitempty"`
+	Timestamp        uint64   `json:"timestamp,omitempty"`
+	MinTimestamp     uint64   `json:"min_timestamp,omitempty"`
+	MaxTimestamp     uint64   `json:"max_timestamp,omitempty"`
+	RevertingHashes  []string `json:"reverting_hashes,omitempty"`
+	Uuid             string   `json:"uuid,omitempty"`
+	// BlockchainNetwork selects the chain on the Cloud API.
+	BlockchainNetwork string `json:"blockchain_network,omitempty"`
+}
+
+// BloxrouteApi creates the api for the bloXroute BDN.
+// bloXroute authenticates with the account authorization header
+// instead of the flashbots signature.
+func BloxrouteApi(authHeader string) *Api {
+	return &Api{
+		URL:                BloxrouteURL,
+		SupportsSimulation: true,
+		Methods: map[string]string{
+			MethodSendBundle: MethodBloxrouteSubmitBundle,
+			MethodCallBundle: MethodBloxrouteSimulateBundle,
+		},
+		SupportedMethods:       []string{MethodSendBundle, MethodCallBundle},
+		CustomHeaders:          map[string]string{"Authorization": authHeader},
+		SkipFlashbotsSignature: true,
+		ParamsTransform:        bloxrouteParams,
+	}
+}
+
+var bloxrouteNetworks = map[int64]string{
+	56:  "BSC-Mainnet",
+	137: "Polygon-Mainnet",
+}
+
+// BloxrouteNetworkApi creates the api for submitting bundles
+// on BSC (56) or Polygon (137) through the bloXroute Cloud API.
+// Polygon has no relay which accepts bundles without an account
+// so it isn't a registered network and this is the only way to send its bundles.
+func BloxrouteNetworkApi(authHeader string, netID int64) (*Api, error) {
+	network, ok := bloxrouteNetworks[netID]
+	if !ok {
+		return nil, errors.Errorf("network not supported by bloxroute id:%v", netID)
+	}
+	api := BloxrouteApi(authHeader)
+	api.URL = BloxrouteCloudURL
+	api.SupportsSimulation = false
+	api.SupportedMethods = []string{MethodSendBundle}
+	api.ParamsTransform = func(method string, params []interface{}) (interface{}, error) {
+		p, err := bloxrouteParams(method, params)
+		if err != nil {
+			return nil, err
+		}
+		bundle := p.(ParamsBloxrouteBundle)
+		bundle.BlockchainNetwork = network
+		return bundle, nil
+	}
+	return api, nil
+}
+
+func bloxrouteParams(method string, params []interface{}) (interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.Errorf("expected a single param got:%v", len(params))
+	}
+	switch p := params[0].(type) {
+	case ParamsSend:
+		return ParamsBloxrouteBundle{
+			Transaction:     bloxrouteTxs(p.Txs),
+			BlockNumber:     p.BlockNum,
+			MinTimestamp:    p.MinTimestamp,
+			MaxTimestamp:    p.MaxTimestamp,
+			RevertingHashes: p.RevertingTxHashes,
+			Uuid:            p.ReplacementUuid,
+		}, nil
+	case ParamsCall:
+		return ParamsBloxrouteBundle{
+			Transaction:      bloxrouteTxs(p.Txs),
+			BlockNumber:      p.BlockNum,
+			StateBlockNumber: p.StateBlockNum,
+			Timestamp:        p.Timestamp,
+		}, nil
+	default:
+		return nil, errors.Errorf("method not supported by bloxroute:%v", method)
+	}
+}
+
+func bloxrouteTxs(txsHex []string) []string {
+	txs := make([]string, len(txsHex))
+	for i, tx := range txsHex {
+		txs[i] = strings.TrimPrefix(tx, "0x")
+	}
+	return txs
+}
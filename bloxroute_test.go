@@ -0,0 +1,103 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBloxroute(t *testing.T) {
+	var (
+		msg    *jsonrpcMessage
+		header http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg = &jsonrpcMessage{}
+		header = r.Header
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	api := BloxrouteApi("auth")
+	api.URL = srv.URL
+
+	// No signing key is needed.
+	flashbot, err := New(nil, api)
+	testutil.Ok(t, err)
+
+	revertHash := common.HexToHash("0x2")
+	resp, err := flashbot.SendBundle(context.Background(), []string{"0xaa"}, 10, &SendBundleOpts{
+		RevertingTxHashes: []common.Hash{revertHash},
+		ReplacementUuid:   "uuid",
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.BundleHash)
+
+	testutil.Equals(t, MethodBloxrouteSubmitBundle, msg.Method)
+	testutil.Equals(t, "auth", header.Get("Authorization"))
+	testutil.Equals(t, "", header.Get("X-Flashbots-Signature"))
+
+	params := ParamsBloxrouteBundle{}
+	testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+	testutil.Equals(t, ParamsBloxrouteBundle{
+		Transaction:     []string{"aa"},
+		BlockNumber:     "0xa",
+		RevertingHashes: []string{revertHash.Hex()},
+		Uuid:            "uuid",
+	}, params)
+
+	_, err = flashbot.CallBundle(context.Background(), []string{"0xaa"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, MethodBloxrouteSimulateBundle, msg.Method)
+	params = ParamsBloxrouteBundle{}
+	testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+	testutil.Equals(t, ParamsBloxrouteBundle{
+		Transaction:      []string{"aa"},
+		BlockNumber:      "0xb",
+		StateBlockNumber: "0xa",
+	}, params)
+
+	_, err = flashbot.CancelBundle(context.Background(), "uuid")
+	testutil.NotOk(t, err)
+}
+
+func TestBloxrouteNetwork(t *testing.T) {
+	var msg *jsonrpcMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg = &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	_, err := BloxrouteNetworkApi("auth", 1)
+	testutil.NotOk(t, err)
+
+	api, err := BloxrouteNetworkApi("auth", 137)
+	testutil.Ok(t, err)
+	testutil.Equals(t, BloxrouteCloudURL, api.URL)
+	api.URL = srv.URL
+	flashbot, err := New(nil, api)
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0xaa"}, 10, nil)
+	testutil.Ok(t, err)
+	params := ParamsBloxrouteBundle{}
+	testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+	testutil.Equals(t, ParamsBloxrouteBundle{
+		Transaction:       []string{"aa"},
+		BlockNumber:       "0xa",
+		BlockchainNetwork: "Polygon-Mainnet",
+	}, params)
+}
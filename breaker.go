@@ -0,0 +1,112 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	circuitBreakerThresholdDefault = 5
+	circuitBreakerCoolDownDefault  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting the relay
+// while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("relay circuit breaker is open")
+
+type CircuitBreakerConfig struct {
+	// Threshold is the number of consecutive failures which opens the circuit, defaults to 5.
+	Threshold int
+	// CoolDown is how long the circuit stays open, defaults to 30s.
+	// After it a single request is let through while the others
+	// still fail with ErrCircuitOpen until it completes.
+	// The circuit closes when it succeeds and opens again right away when it fails.
+	CoolDown time.Duration
+}
+
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+
+	mtx       sync.Mutex
+	failures  int
+	openUntil time.Time
+	// probing is set while the single request of the half-open circuit is in flight.
+	probing bool
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = circuitBreakerThresholdDefault
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = circuitBreakerCoolDownDefault
+	}
+	return &circuitBreaker{cfg: cfg}
+}
+
+// allow reports whether the request can be sent and
+// whether it is the probe of the half-open circuit.
+// Every allowed request must be completed with record or release.
+func (self *circuitBreaker) allow() (allowed bool, probe bool) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if time.Now().Before(self.openUntil) {
+		return false, false
+	}
+	if self.failures < self.cfg.Threshold {
+		return true, false
+	}
+	if self.probing {
+		return false, false
+	}
+	self.probing = true
+	return true, true
+}
+
+// release completes a request which says nothing about the relay,
+// for example one canceled by the caller.
+func (self *circuitBreaker) release(probe bool) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if probe {
+		self.probing = false
+	}
+}
+
+func (self *circuitBreaker) record(failed bool, probe bool) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if probe {
+		self.probing = false
+	}
+	if !failed {
+		self.failures = 0
+		return
+	}
+	self.failures++
+	if self.failures >= self.cfg.Threshold {
+		self.openUntil = time.Now().Add(self.cfg.CoolDown)
+	}
+}
+
+// isRelayFailure reports whether the error means that the relay is unavailable
+// as opposed to rejecting the request or the caller canceling it.
+func isRelayFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	coolDown := 50 * time.Millisecond
+	flashbot, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		CircuitBreaker:         &CircuitBreakerConfig{Threshold: 2, CoolDown: coolDown},
+	})
+	testutil.Ok(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+		testutil.NotOk(t, err)
+		testutil.Assert(t, !errors.Is(err, ErrCircuitOpen), "circuit shouldn't be open yet")
+	}
+
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.Is(err, ErrCircuitOpen), "unexpected error:%v", err)
+	testutil.Equals(t, 2, calls)
+
+	// After the cool-down a single failure opens it again.
+	time.Sleep(coolDown)
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, !errors.Is(err, ErrCircuitOpen), "circuit should be half open")
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.Is(err, ErrCircuitOpen), "unexpected error:%v", err)
+	testutil.Equals(t, 3, calls)
+}
+
+func TestCircuitBreakerHalfOpenConcurrent(t *testing.T) {
+	var (
+		calls   int32
+		fail    int32 = 1
+		arrived       = make(chan struct{}, 1)
+		release       = make(chan struct{})
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		arrived <- struct{}{}
+		<-release
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	coolDown := 20 * time.Millisecond
+	flashbot, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		CircuitBreaker:         &CircuitBreakerConfig{Threshold: 1, CoolDown: coolDown},
+	})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+	time.Sleep(coolDown)
+
+	// The probe is held by the relay while the other requests are sent.
+	atomic.StoreInt32(&fail, 0)
+	probeErr := make(chan error)
+	go func() {
+		_, err := flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+		probeErr <- err
+	}()
+	<-arrived
+
+	var wg sync.WaitGroup
+	var rejected int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil); errors.Is(err, ErrCircuitOpen) {
+				atomic.AddInt32(&rejected, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	testutil.Equals(t, int32(10), rejected)
+	testutil.Equals(t, int32(2), atomic.LoadInt32(&calls))
+
+	// The successful probe closes the circuit.
+	close(release)
+	testutil.Ok(t, <-probeErr)
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int32(3), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,203 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+const bribeSearchIterationsDefault = 20
+
+// BribeSearch configures the search done by FindMinBribe.
+type BribeSearch struct {
+	// Build returns the signed bundle transactions which pay the given bribe,
+	// either as a direct coinbase transfer or as a priority fee.
+	Build func(bribe *big.Int) ([]string, error)
+	// Min and Max bound the search.
+	// Max should be the highest bribe that still keeps the bundle profitable.
+	Min *big.Int
+	Max *big.Int
+	// TargetGasPrice is the minimum effective bundle gas price (coinbase diff / gas used) to reach.
+	TargetGasPrice *big.Int
+	// Precision stops the search once the bounds are closer than this, defaults to 1 wei.
+	Precision *big.Int
+	// MaxIterations caps the number of simulations, defaults to 20.
+	MaxIterations int
+	// BlockNumState and CallOpts are passed to CallBundle, 0 uses the latest state.
+	BlockNumState uint64
+	CallOpts      *CallBundleOpts
+}
+
+// FindMinBribe binary searches the bribe with repeated CallBundle simulations
+// and returns the lowest one that reaches the target effective gas price
+// together with its simulation result.
+func FindMinBribe(ctx context.Context, flashbot Flashboter, search BribeSearch) (*big.Int, *Response, error) {
+	if search.Build == nil || search.Max == nil || search.TargetGasPrice == nil {
+		return nil, nil, errors.New("build func, max bribe and target gas price are required")
+	}
+	lo := big.NewInt(0)
+	if search.Min != nil {
+		lo.Set(search.Min)
+	}
+	hi := new(big.Int).Set(search.Max)
+	if lo.Cmp(hi) > 0 {
+		return nil, nil, errors.Errorf("min bribe:%v is higher than max bribe:%v", lo, hi)
+	}
+	precision := big.NewInt(1)
+	if search.Precision != nil && search.Precision.Sign() > 0 {
+		precision = search.Precision
+	}
+	iterations := search.MaxIterations
+	if iterations <= 0 {
+		iterations = bribeSearchIterationsDefault
+	}
+
+	ctx, _ = ensureCorrelationID(ctx)
+
+	// Bundles that revert with the bribe count as not reaching the target.
+	simulate := func(bribe *big.Int) (*Response, bool, error) {
+		txs, err := search.Build(bribe)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "building bundle for bribe:%v", bribe)
+		}
+		resp, err := flashbot.CallBundle(ctx, txs, search.BlockNumState, search.CallOpts)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "simulating bundle for bribe:%v", bribe)
+		}
+		if resp.Reverted() {
+			return resp, false, nil
+		}
+		gasPrice, err := resp.EffectiveGasPrice()
+		if err != nil {
+			return nil, false, err
+		}
+		return resp, gasPrice.Cmp(search.TargetGasPrice) >= 0, nil
+	}
+
+	best, ok, err := simulate(hi)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		if err := best.RevertErr(); err != nil {
+			return nil, best, errors.Wrapf(err, "simulating bundle for max bribe:%v", hi)
+		}
+		return nil, best, errors.Errorf("target gas price:%v not reachable with max bribe:%v", search.TargetGasPrice, hi)
+	}
+	if lo.Cmp(hi) == 0 {
+		return hi, best, nil
+	}
+
+	// The search below only narrows towards the min so check it first.
+	resp, ok, err := simulate(lo)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		return lo, resp, nil
+	}
+
+	diff := new(big.Int)
+	for i := 2; i < iterations; i++ {
+		if diff.Sub(hi, lo).Cmp(precision) <= 0 {
+			break
+		}
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+
+		resp, ok, err := simulate(mid)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			hi, best = mid, resp
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi, best, nil
+}
+
+// EffectiveGasPrice returns the bundle gas price reported by the relay or
+// calculates it from the coinbase diff and the gas used by all transactions.
+func (self *Response) EffectiveGasPrice() (*big.Int, error) {
+	if price, err := self.BundleGasPriceWei(); err == nil {
+		return price, nil
+	}
+	diff, err := self.CoinbaseDiffWei()
+	if err != nil {
+		return nil, err
+	}
+	var gasUsed uint64
+	for _, r := range self.Results {
+		gasUsed += r.GasUsed
+	}
+	if gasUsed == 0 {
+		return nil, errors.New("bundle didn't use any gas")
+	}
+	return diff.Div(diff, new(big.Int).SetUint64(gasUsed)), nil
+}
+
+// BribeEstimate is the payment a simulated bundle needs to reach a target bundle gas price.
+type BribeEstimate struct {
+	GasUsed uint64
+	// GasPrice is the bundle gas price of the simulation.
+	GasPrice *big.Int
+	// PriorityFee is the tip per gas all bundle transactions need
+	// when the direct transfers to the coinbase stay the same.
+	PriorityFee *big.Int
+	// CoinbaseBribe is the transfer to the coinbase that needs to be added with the current fees,
+	// it includes the gas used by the bribe transaction itself.
+	CoinbaseBribe *big.Int
+}
+
+// EstimateBribe calculates the priority fee or the coinbase transfer
+// needed for the bundle to reach the target bundle gas price.
+// bribeGas is the gas used by the transaction that pays the coinbase bribe,
+// 0 when the transfer is made by one of the existing transactions.
+// The amounts are zero when the bundle already reaches the target.
+func (self *Response) EstimateBribe(targetGasPrice *big.Int, bribeGas uint64) (*BribeEstimate, error) {
+	var gasUsed uint64
+	for _, r := range self.Results {
+		gasUsed += r.GasUsed
+	}
+	if gasUsed == 0 {
+		return nil, errors.New("bundle didn't use any gas")
+	}
+	diff, err := self.CoinbaseDiffWei()
+	if err != nil {
+		return nil, err
+	}
+	sent, err := self.EthSentToCoinbaseWei()
+	if err != nil {
+		return nil, err
+	}
+	gas := new(big.Int).SetUint64(gasUsed)
+
+	// Ceiling division so that the fee reaches the target after the relay rounds down.
+	fee := new(big.Int).Mul(targetGasPrice, gas)
+	fee.Sub(fee, sent)
+	fee.Add(fee, new(big.Int).Sub(gas, big.NewInt(1)))
+	fee.Div(fee, gas)
+
+	bribe := new(big.Int).Mul(targetGasPrice, new(big.Int).SetUint64(gasUsed+bribeGas))
+	bribe.Sub(bribe, diff)
+
+	if fee.Sign() < 0 {
+		fee = new(big.Int)
+	}
+	if bribe.Sign() < 0 {
+		bribe = new(big.Int)
+	}
+	return &BribeEstimate{
+		GasUsed:       gasUsed,
+		GasPrice:      new(big.Int).Div(diff, gas),
+		PriorityFee:   fee,
+		CoinbaseBribe: bribe,
+	}, nil
+}
@@ -0,0 +1,164 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+func TestFindMinBribe(t *testing.T) {
+	coinbase := common.HexToAddress("0xc0ffee")
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{Coinbase: coinbase}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL, SupportsSimulation: true})
+	testutil.Ok(t, err)
+
+	build := func(bribe *big.Int) ([]string, error) {
+		tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			To:        &coinbase,
+			Value:     bribe,
+			Gas:       21000,
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return []string{hexutil.Encode(raw)}, nil
+	}
+
+	bribe, resp, err := FindMinBribe(context.Background(), flashbot, BribeSearch{
+		Build:          build,
+		Max:            big.NewInt(1_000_000),
+		TargetGasPrice: big.NewInt(10),
+		MaxIterations:  64,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(210_000), bribe)
+	testutil.Equals(t, "210000", resp.CoinbaseDiff)
+
+	_, _, err = FindMinBribe(context.Background(), flashbot, BribeSearch{
+		Build:          build,
+		Max:            big.NewInt(1000),
+		TargetGasPrice: big.NewInt(10),
+	})
+	testutil.NotOk(t, err)
+
+	// The min is returned as is when it already reaches the target.
+	bribe, _, err = FindMinBribe(context.Background(), flashbot, BribeSearch{
+		Build:          build,
+		Min:            big.NewInt(300_000),
+		Max:            big.NewInt(1_000_000),
+		TargetGasPrice: big.NewInt(10),
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(300_000), bribe)
+}
+
+func TestFindMinBribeRevert(t *testing.T) {
+	coinbase := common.HexToAddress("0xc0ffee")
+	reverter := common.HexToAddress("0xdead")
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{
+		Coinbase:        coinbase,
+		RevertAddresses: []common.Address{reverter},
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	flashbot, err := New(privKey, &Api{URL: srv.URL, SupportsSimulation: true})
+	testutil.Ok(t, err)
+
+	// Low bribes revert like a contract that checks its profit.
+	build := func(bribe *big.Int) ([]string, error) {
+		to := coinbase
+		if bribe.Cmp(big.NewInt(100_000)) < 0 {
+			to = reverter
+		}
+		tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			To:        &to,
+			Value:     bribe,
+			Gas:       21000,
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return []string{hexutil.Encode(raw)}, nil
+	}
+
+	bribe, _, err := FindMinBribe(context.Background(), flashbot, BribeSearch{
+		Build:          build,
+		Max:            big.NewInt(1_000_000),
+		TargetGasPrice: big.NewInt(10),
+		MaxIterations:  64,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(210_000), bribe)
+
+	_, _, err = FindMinBribe(context.Background(), flashbot, BribeSearch{
+		Build:          build,
+		Max:            big.NewInt(50_000),
+		TargetGasPrice: big.NewInt(1),
+	})
+	testutil.Assert(t, errors.Is(err, ErrBundleReverted), "unexpected error:%v", err)
+}
+
+func TestEstimateBribe(t *testing.T) {
+	resp := &Response{}
+	resp.CoinbaseDiff = "1000000000000000"
+	resp.EthSentToCoinbase = "500000000000000"
+	resp.Results = []TxResult{{GasUsed: 21000}, {GasUsed: 79000}}
+
+	est, err := resp.EstimateBribe(big.NewInt(20*params.GWei), 60000)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(100000), est.GasUsed)
+	testutil.Equals(t, big.NewInt(10*params.GWei), est.GasPrice)
+	testutil.Equals(t, big.NewInt(15*params.GWei), est.PriorityFee)
+	testutil.Equals(t, big.NewInt(2_200_000*params.GWei), est.CoinbaseBribe)
+
+	est, err = resp.EstimateBribe(big.NewInt(5*params.GWei), 60000)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, est.PriorityFee.Sign())
+	testutil.Equals(t, 0, est.CoinbaseBribe.Sign())
+
+	// The priority fee is rounded up.
+	resp.EthSentToCoinbase = "1"
+	resp.Results = []TxResult{{GasUsed: 3}}
+	est, err = resp.EstimateBribe(big.NewInt(1), 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, big.NewInt(1), est.PriorityFee)
+
+	resp.Results = nil
+	_, err = resp.EstimateBribe(big.NewInt(1), 0)
+	testutil.NotOk(t, err)
+}
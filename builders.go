@@ -0,0 +1,34 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+// BuilderPreset describes the public endpoint of a block builder.
+type BuilderPreset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Methods are the flashbots methods accepted by the builder, nil means all.
+	Methods []string `json:"methods,omitempty"`
+	// Configure adapts the api to builders with their own schema or authentication.
+	Configure func(*Api) `json:"-"`
+}
+
+// Api creates a relay api for the builder.
+func (self BuilderPreset) Api() *Api {
+	api := &Api{
+		URL:              self.URL,
+		SupportedMethods: self.Methods,
+	}
+	api.SupportsSimulation = api.Supports(MethodCallBundle)
+	api.SupportsStats = api.Supports(MethodGetBundleStats)
+	if self.Configure != nil {
+		self.Configure(api)
+	}
+	return api
+}
+
+// BuilderPresets returns the relays of the network in the default catalog
+// without the deprecated ones.
+func BuilderPresets(netID int64) []BuilderPreset {
+	return DefaultCatalog().Presets(netID, false)
+}
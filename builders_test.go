@@ -0,0 +1,61 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestNewAllBuilders(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbots, err := NewAll(1, privKey, &Api{URL: "http://localhost"})
+	testutil.Ok(t, err)
+
+	var urls []string
+	for _, f := range flashbots {
+		urls = append(urls, f.Api().URL)
+	}
+	testutil.Equals(t, []string{
+		"https://relay.flashbots.net",
+		"https://rpc.titanbuilder.xyz",
+		"https://rpc.beaverbuild.org",
+		"https://rsync-builder.xyz",
+		"http://localhost",
+	}, urls)
+	testutil.Assert(t, flashbots[0].Api().SupportsSimulation, "flashbots relay should support simulations")
+	testutil.Assert(t, !flashbots[1].Api().SupportsSimulation, "builders shouldn't support simulations")
+}
+
+func TestSupportedMethods(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL, SupportedMethods: []string{MethodSendBundle}})
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+
+	_, err = flashbot.CancelBundle(context.Background(), "uuid")
+	testutil.NotOk(t, err)
+	testutil.Assert(t, errors.Is(err, ErrMethodNotSupported), "unexpected error:%v", err)
+	testutil.Equals(t, 1, calls)
+}
@@ -0,0 +1,157 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// BundleBuilder collects the transactions and sendBundle parameters of a bundle.
+// The first error of a chained call is kept and returned by Sign.
+//
+//	req, err := NewBundle().AddTx(tx).AllowRevert(tx.Hash()).TargetBlock(n).ValidFor(3).Sign(chainID)
+type BundleBuilder struct {
+	txs      []BundleTx
+	txsHex   []string
+	opts     SendBundleOpts
+	blockNum uint64
+	blocks   uint64
+	err      error
+}
+
+// NewBundle returns an empty bundle valid for a single block.
+func NewBundle() *BundleBuilder {
+	return &BundleBuilder{blocks: 1}
+}
+
+// AddTx adds a signed transaction.
+func (self *BundleBuilder) AddTx(tx *types.Transaction) *BundleBuilder {
+	if self.err != nil {
+		return self
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		self.err = errors.Wrapf(err, "encoding tx index:%v", len(self.txs))
+		return self
+	}
+	return self.AddRawTx(hexutil.Encode(raw))
+}
+
+// AddRawTx adds a signed transaction in the hex encoded format of SendBundle.
+func (self *BundleBuilder) AddRawTx(txHex string) *BundleBuilder {
+	self.txs = append(self.txs, BundleTx{})
+	self.txsHex = append(self.txsHex, txHex)
+	return self
+}
+
+// AddUnsignedTx adds a transaction which is signed with the given key by Sign.
+func (self *BundleBuilder) AddUnsignedTx(tx types.TxData, prvKey *ecdsa.PrivateKey) *BundleBuilder {
+	self.txs = append(self.txs, BundleTx{Tx: tx, PrvKey: prvKey})
+	self.txsHex = append(self.txsHex, "")
+	return self
+}
+
+// AllowRevert allows the transaction with the given hash to revert
+// without invalidating the bundle.
+func (self *BundleBuilder) AllowRevert(hash common.Hash) *BundleBuilder {
+	self.opts.RevertingTxHashes = append(self.opts.RevertingTxHashes, hash)
+	return self
+}
+
+// TargetBlock sets the first block for which the bundle is sent.
+func (self *BundleBuilder) TargetBlock(blockNum uint64) *BundleBuilder {
+	self.blockNum = blockNum
+	return self
+}
+
+// ValidFor sends the bundle for the given number of consecutive blocks starting at the target block.
+func (self *BundleBuilder) ValidFor(blocks uint64) *BundleBuilder {
+	if blocks == 0 && self.err == nil {
+		self.err = errors.New("bundle must be valid for at least one block")
+	}
+	self.blocks = blocks
+	return self
+}
+
+// Timestamps bounds the timestamps of the blocks for which the bundle is valid in unix seconds,
+// zero leaves a bound unset.
+func (self *BundleBuilder) Timestamps(min, max uint64) *BundleBuilder {
+	if max != 0 && min > max && self.err == nil {
+		self.err = errors.Errorf("min timestamp after max timestamp min:%v max:%v", min, max)
+	}
+	self.opts.MinTimestamp = min
+	self.opts.MaxTimestamp = max
+	return self
+}
+
+// ReplacementUuid allows replacing or cancelling the bundle with a later submission.
+// The bundles sent for more than one block use the uuids from BlockReplacementUuid.
+func (self *BundleBuilder) ReplacementUuid(replacementUuid string) *BundleBuilder {
+	self.opts.ReplacementUuid = replacementUuid
+	return self
+}
+
+// Sign signs the unsigned transactions for the given chain and returns the request ready to be sent.
+func (self *BundleBuilder) Sign(chainID *big.Int) (*BundleRequest, error) {
+	if self.err != nil {
+		return nil, self.err
+	}
+	if len(self.txs) == 0 {
+		return nil, errors.New("bundle has no transactions")
+	}
+	if self.blockNum == 0 {
+		return nil, errors.New("bundle has no target block")
+	}
+
+	txsHex := make([]string, len(self.txsHex))
+	for i, tx := range self.txs {
+		if tx.Tx == nil {
+			txsHex[i] = self.txsHex[i]
+			continue
+		}
+		txHex, err := SignTx(chainID, tx.Tx, tx.PrvKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		txsHex[i] = txHex
+	}
+
+	hash, err := BundleHash(txsHex)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := self.opts
+	opts.RevertingTxHashes = append([]common.Hash(nil), self.opts.RevertingTxHashes...)
+	return &BundleRequest{
+		Txs:      txsHex,
+		BlockNum: self.blockNum,
+		Blocks:   self.blocks,
+		Opts:     opts,
+		Hash:     hash,
+	}, nil
+}
+
+// BundleRequest is a signed bundle with its sendBundle parameters.
+type BundleRequest struct {
+	Txs      []string
+	BlockNum uint64
+	// Blocks is the number of consecutive blocks for which the bundle is sent.
+	Blocks uint64
+	Opts   SendBundleOpts
+	// Hash is the bundle hash expected from the relay.
+	Hash common.Hash
+}
+
+// Send submits the bundle for all its blocks.
+func (self *BundleRequest) Send(ctx context.Context, flashbot Flashboter) ([]BlockBundleResult, error) {
+	return SendBundleForBlocks(ctx, flashbot, self.Txs, self.BlockNum, self.Blocks, &SendBundleForBlocksOpts{SendBundleOpts: self.Opts})
+}
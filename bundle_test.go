@@ -0,0 +1,117 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBundleBuilder(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		params []ParamsSend
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		var p []ParamsSend
+		testutil.Ok(t, json.Unmarshal(msg.Params, &p))
+		mtx.Lock()
+		params = append(params, p[0])
+		mtx.Unlock()
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	chainID := big.NewInt(1)
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	to := common.HexToAddress("0x1")
+	signed, err := types.SignNewTx(prvKey, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+	})
+	testutil.Ok(t, err)
+	rawTx := signedTxHex(t)
+
+	req, err := NewBundle().
+		AddTx(signed).
+		AddUnsignedTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, To: &to, Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)}, prvKey).
+		AddRawTx(rawTx).
+		AllowRevert(signed.Hash()).
+		TargetBlock(10).
+		ValidFor(3).
+		Timestamps(100, 200).
+		ReplacementUuid("2ba8c8c7-9b52-4e1a-9d33-7f5f2a6a8c11").
+		Sign(chainID)
+	testutil.Ok(t, err)
+
+	testutil.Equals(t, 3, len(req.Txs))
+	signedRaw, err := signed.MarshalBinary()
+	testutil.Ok(t, err)
+	testutil.Equals(t, hexutil.Encode(signedRaw), req.Txs[0])
+	testutil.Equals(t, rawTx, req.Txs[2])
+	hash, err := BundleHash(req.Txs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, hash, req.Hash)
+
+	results, err := req.Send(context.Background(), flashbot)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(results))
+
+	sort.Slice(params, func(i, j int) bool { return params[i].BlockNum < params[j].BlockNum })
+	testutil.Equals(t, 3, len(params))
+	for i, p := range params {
+		testutil.Equals(t, hexutil.EncodeUint64(uint64(10+i)), p.BlockNum)
+		testutil.Equals(t, req.Txs, p.Txs)
+		testutil.Equals(t, []string{signed.Hash().Hex()}, p.RevertingTxHashes)
+		testutil.Equals(t, uint64(100), p.MinTimestamp)
+		testutil.Equals(t, uint64(200), p.MaxTimestamp)
+		replacementUuid, err := BlockReplacementUuid("2ba8c8c7-9b52-4e1a-9d33-7f5f2a6a8c11", uint64(10+i))
+		testutil.Ok(t, err)
+		testutil.Equals(t, replacementUuid, p.ReplacementUuid)
+	}
+}
+
+func TestBundleBuilderErrors(t *testing.T) {
+	chainID := big.NewInt(1)
+
+	_, err := NewBundle().TargetBlock(10).Sign(chainID)
+	testutil.NotOk(t, err)
+
+	_, err = NewBundle().AddRawTx(signedTxHex(t)).Sign(chainID)
+	testutil.NotOk(t, err)
+
+	_, err = NewBundle().AddRawTx(signedTxHex(t)).TargetBlock(10).ValidFor(0).Sign(chainID)
+	testutil.NotOk(t, err)
+
+	_, err = NewBundle().AddRawTx(signedTxHex(t)).TargetBlock(10).Timestamps(200, 100).Sign(chainID)
+	testutil.NotOk(t, err)
+
+	_, err = NewBundle().AddUnsignedTx(&types.LegacyTx{}, nil).TargetBlock(10).Sign(chainID)
+	testutil.NotOk(t, err)
+
+	_, err = NewBundle().AddRawTx("0x1").TargetBlock(10).Sign(chainID)
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// ErrBundleHashMismatch is returned when the bundle hash returned by the relay
+// doesn't match the hash computed from the transactions of the bundle.
+var ErrBundleHashMismatch = errors.New("bundle hash mismatch")
+
+// BundleHash computes the hash of a bundle the way flashbots defines it,
+// the keccak256 of the concatenated hashes of its transactions.
+// It allows correlating submissions across relays and
+// looking up the bundle stats before the relay replies.
+func BundleHash(txsHex []string) (common.Hash, error) {
+	if len(txsHex) == 0 {
+		return common.Hash{}, errors.New("bundle has no transactions")
+	}
+	hashes := make([]common.Hash, len(txsHex))
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return common.Hash{}, errors.Wrapf(err, "index:%v", i)
+		}
+		hashes[i] = tx.Hash()
+	}
+	return BundleHashFromTxHashes(hashes), nil
+}
+
+// BundleHashFromTxHashes is BundleHash for the hashes of the bundle transactions
+// for bundles that were sent by another process.
+func BundleHashFromTxHashes(txHashes []common.Hash) common.Hash {
+	hashes := make([]byte, 0, len(txHashes)*common.HashLength)
+	for _, h := range txHashes {
+		hashes = append(hashes, h.Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// VerifyBundleHash checks the bundle hash returned by the relay against the transactions of the bundle.
+// The returned error wraps ErrBundleHashMismatch when the hashes differ.
+func VerifyBundleHash(txsHex []string, bundleHash string) error {
+	expected, err := BundleHash(txsHex)
+	if err != nil {
+		return errors.Wrap(err, "computing bundle hash")
+	}
+	if common.HexToHash(bundleHash) != expected {
+		return errors.Wrapf(ErrBundleHashMismatch, "expected:%v got:%v", expected.Hex(), bundleHash)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+func TestBundleHash(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	flashbot, err := New(privKey, &Api{URL: srv.URL, VerifyBundleHash: true})
+	testutil.Ok(t, err)
+
+	txsHex := []string{signedTxHex(t), signedTxHex(t)}
+	hash, err := BundleHash(txsHex)
+	testutil.Ok(t, err)
+
+	resp, err := flashbot.SendBundle(ctx, txsHex, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, hash.Hex(), resp.BundleHash)
+	testutil.Ok(t, VerifyBundleHash(txsHex, resp.BundleHash))
+
+	var txHashes []common.Hash
+	for _, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		testutil.Ok(t, err)
+		txHashes = append(txHashes, tx.Hash())
+	}
+	testutil.Equals(t, hash, BundleHashFromTxHashes(txHashes))
+
+	// The order of the transactions is part of the hash.
+	err = VerifyBundleHash([]string{txsHex[1], txsHex[0]}, resp.BundleHash)
+	testutil.Assert(t, errors.Is(err, ErrBundleHashMismatch), "unexpected error:%v", err)
+
+	_, err = BundleHash(nil)
+	testutil.NotOk(t, err)
+	_, err = BundleHash([]string{"0x1"})
+	testutil.NotOk(t, err)
+}
+
+func TestVerifyBundleHash(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	logs := &bytes.Buffer{}
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true, VerifyBundleHash: true}, WithLogger(log.NewLogfmtLogger(logs)))
+	testutil.Ok(t, err)
+
+	// A mismatch is only flagged as the relay already accepted the bundle.
+	resp, err := flashbot.SendBundle(WithCorrelationID(context.Background(), "id"), []string{signedTxHex(t)}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.BundleHash)
+	testutil.Assert(t, strings.Contains(logs.String(), "unexpected bundle hash"), "missing warning:%v", logs.String())
+	testutil.Assert(t, strings.Contains(logs.String(), "correlationID=id"), "missing correlation id:%v", logs.String())
+}
@@ -0,0 +1,44 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Request sends a request for any method and returns the raw response body.
+// The method name and the params go through Api.Methods and Api.ParamsTransform
+// the same way as for the methods implemented by this package.
+func (self *Flashbot) Request(ctx context.Context, method string, params ...interface{}) ([]byte, error) {
+	return self.req(ctx, method, params...)
+}
+
+// Call sends a request to the relay and decodes the result into T,
+// for example to use relay methods which aren't implemented by this package.
+// JSON-RPC errors are returned as *RPCError.
+func Call[T any](ctx context.Context, flashbot Flashboter, method string, params ...interface{}) (*T, error) {
+	resp, err := flashbot.Request(ctx, method, params...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "flashbot request method:%v", method)
+	}
+
+	msg := &jsonrpcMessage{}
+	if err := json.Unmarshal(resp, msg); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal flashbot response:%v", string(resp))
+	}
+	if msg.Error != nil {
+		return nil, errors.Wrapf(&RPCError{Code: msg.Error.Code, Message: msg.Error.Message}, "flashbot request returned an error method:%v", method)
+	}
+
+	res := new(T)
+	if len(msg.Result) > 0 {
+		if err := json.Unmarshal(msg.Result, res); err != nil {
+			return nil, errors.Wrapf(err, "unmarshal flashbot result:%v", string(msg.Result))
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestCall(t *testing.T) {
+	var msg *jsonrpcMessage
+	var resp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg = &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		_, err := w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		Methods:                map[string]string{"custom_method": "relay_customMethod"},
+	})
+	testutil.Ok(t, err)
+
+	type custom struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"name":"relay","count":2}}`
+	res, err := Call[custom](context.Background(), flashbot, "custom_method", "0x1", 2)
+	testutil.Ok(t, err)
+	testutil.Equals(t, &custom{Name: "relay", Count: 2}, res)
+	testutil.Equals(t, "relay_customMethod", msg.Method)
+	testutil.Equals(t, `["0x1",2]`, string(msg.Params))
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":"0x1"}`
+	str, err := Call[string](context.Background(), flashbot, "other_method")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", *str)
+	testutil.Equals(t, "", string(msg.Params))
+
+	resp = `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`
+	_, err = Call[custom](context.Background(), flashbot, "custom_method")
+	var rpcErr *RPCError
+	testutil.Assert(t, errors.As(err, &rpcErr), "expected an rpc error got:%v", err)
+	testutil.Equals(t, -32601, rpcErr.Code)
+	testutil.Assert(t, errors.Is(err, ErrUnknownMethod), "unexpected error:%v", err)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// cancelBumpPercentDefault is the minimum price bump nodes accept for a replacement.
+const cancelBumpPercentDefault = 10
+
+// TxSender broadcasts a transaction, an ethclient.Client can be used directly.
+type TxSender interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// CancelTxOpts configures CancelTx.
+type CancelTxOpts struct {
+	// BumpPercent raises the gas price of the original transaction, defaults to 10.
+	BumpPercent uint64
+	// Node broadcasts the cancellation to the public mempool.
+	Node TxSender
+	// Flashbot sends the cancellation with eth_sendPrivateRawTransaction.
+	Flashbot    Flashboter
+	Preferences *PrivateTxPreferences
+}
+
+// NewCancelTx builds a self transfer with the same nonce as the original transaction
+// and a higher gas price so that it replaces it.
+// It is the fallback when the relay doesn't honor CancelPrivateTransaction.
+func NewCancelTx(original *types.Transaction, bumpPercent uint64) (types.TxData, error) {
+	if bumpPercent == 0 {
+		bumpPercent = cancelBumpPercentDefault
+	}
+	sender, err := txSender(original)
+	if err != nil {
+		return nil, err
+	}
+	if original.Type() == types.DynamicFeeTxType {
+		return &types.DynamicFeeTx{
+			ChainID:   original.ChainId(),
+			Nonce:     original.Nonce(),
+			GasTipCap: bumpPrice(original.GasTipCap(), bumpPercent),
+			GasFeeCap: bumpPrice(original.GasFeeCap(), bumpPercent),
+			Gas:       21000,
+			To:        &sender,
+		}, nil
+	}
+	return &types.LegacyTx{
+		Nonce:    original.Nonce(),
+		GasPrice: bumpPrice(original.GasPrice(), bumpPercent),
+		Gas:      21000,
+		To:       &sender,
+	}, nil
+}
+
+// bumpPrice raises the price by the percent rounded up and at least by 1 wei.
+func bumpPrice(price *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(price, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(price) <= 0 {
+		bumped.Add(price, big.NewInt(1))
+	}
+	return bumped
+}
+
+// CancelTx signs the cancellation of the original transaction and submits it
+// to the node, the relay or both.
+// The signed cancellation is returned even when a submission fails so it can be resent.
+func CancelTx(ctx context.Context, original *types.Transaction, prvKey *ecdsa.PrivateKey, opts CancelTxOpts) (*types.Transaction, error) {
+	if opts.Node == nil && opts.Flashbot == nil {
+		return nil, errors.New("node or flashbot is required to submit the cancellation")
+	}
+	if prvKey == nil {
+		return nil, errors.New("private key can't be empty")
+	}
+	sender, err := txSender(original)
+	if err != nil {
+		return nil, err
+	}
+	if addr := crypto.PubkeyToAddress(prvKey.PublicKey); addr != sender {
+		return nil, errors.Errorf("key doesn't match the tx sender expected:%v got:%v", sender.Hex(), addr.Hex())
+	}
+
+	txData, err := NewCancelTx(original, opts.BumpPercent)
+	if err != nil {
+		return nil, err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if original.Protected() {
+		signer = types.LatestSignerForChainID(original.ChainId())
+	}
+	tx, err := types.SignNewTx(prvKey, signer, txData)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing cancel tx")
+	}
+
+	// Submit to both even when one fails as either can get the cancellation included.
+	var sendErr error
+	if opts.Flashbot != nil {
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return tx, errors.Wrap(err, "encoding cancel tx")
+		}
+		if _, err := opts.Flashbot.SendPrivateRawTransaction(ctx, hexutil.Encode(raw), opts.Preferences); err != nil {
+			sendErr = errors.Wrapf(err, "sending cancel tx to the relay nonce:%v", tx.Nonce())
+		}
+	}
+	if opts.Node != nil {
+		if err := opts.Node.SendTransaction(ctx, tx); err != nil {
+			if sendErr != nil {
+				return tx, errors.Wrapf(err, "sending cancel tx to the node nonce:%v relayErr:%v", tx.Nonce(), sendErr)
+			}
+			sendErr = errors.Wrapf(err, "sending cancel tx to the node nonce:%v", tx.Nonce())
+		}
+	}
+	return tx, sendErr
+}
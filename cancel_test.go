@@ -0,0 +1,124 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+type txSenderFunc func(ctx context.Context, tx *types.Transaction) error
+
+func (self txSenderFunc) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return self(ctx, tx)
+}
+
+func TestNewCancelTx(t *testing.T) {
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	sender := crypto.PubkeyToAddress(prvKey.PublicKey)
+	to := common.HexToAddress("0x1")
+
+	original, err := types.SignNewTx(prvKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     4,
+		To:        &to,
+		Value:     big.NewInt(1),
+		Gas:       100000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1000),
+	})
+	testutil.Ok(t, err)
+	txData, err := NewCancelTx(original, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     4,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(1100),
+		Gas:       21000,
+		To:        &sender,
+	}, txData)
+
+	legacy, err := types.SignNewTx(prvKey, types.HomesteadSigner{}, &types.LegacyTx{Nonce: 2, To: &to, Gas: 100000, GasPrice: big.NewInt(1000)})
+	testutil.Ok(t, err)
+	txData, err = NewCancelTx(legacy, 25)
+	testutil.Ok(t, err)
+	testutil.Equals(t, &types.LegacyTx{Nonce: 2, GasPrice: big.NewInt(1250), Gas: 21000, To: &sender}, txData)
+}
+
+func TestCancelTx(t *testing.T) {
+	ctx := context.Background()
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	to := common.HexToAddress("0x1")
+	original, err := types.SignNewTx(prvKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     4,
+		To:        &to,
+		Gas:       100000,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+	})
+	testutil.Ok(t, err)
+
+	var msg *jsonrpcMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg = &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(prvKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	var broadcast *types.Transaction
+	node := txSenderFunc(func(ctx context.Context, tx *types.Transaction) error {
+		broadcast = tx
+		return nil
+	})
+
+	tx, err := CancelTx(ctx, original, prvKey, CancelTxOpts{BumpPercent: 20, Node: node, Flashbot: flashbot})
+	testutil.Ok(t, err)
+	testutil.Equals(t, tx.Hash(), broadcast.Hash())
+	testutil.Equals(t, uint64(4), tx.Nonce())
+	testutil.Equals(t, big.NewInt(120), tx.GasTipCap())
+	testutil.Equals(t, big.NewInt(1200), tx.GasFeeCap())
+	testutil.Equals(t, crypto.PubkeyToAddress(prvKey.PublicKey), *tx.To())
+
+	raw, err := tx.MarshalBinary()
+	testutil.Ok(t, err)
+	testutil.Equals(t, MethodSendPrivateRawTransaction, msg.Method)
+	testutil.Equals(t, `["`+hexutil.Encode(raw)+`"]`, string(msg.Params))
+
+	// The relay is still tried when the node fails.
+	msg = nil
+	nodeErr := errors.New("node down")
+	tx, err = CancelTx(ctx, original, prvKey, CancelTxOpts{
+		Node:     txSenderFunc(func(context.Context, *types.Transaction) error { return nodeErr }),
+		Flashbot: flashbot,
+	})
+	testutil.Assert(t, errors.Is(err, nodeErr), "unexpected error:%v", err)
+	testutil.Assert(t, tx != nil, "the signed cancellation should be returned")
+	testutil.Assert(t, msg != nil, "the cancellation should be sent to the relay")
+
+	otherKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	_, err = CancelTx(ctx, original, otherKey, CancelTxOpts{Node: node})
+	testutil.NotOk(t, err)
+	_, err = CancelTx(ctx, original, prvKey, CancelTxOpts{})
+	testutil.NotOk(t, err)
+}
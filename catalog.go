@@ -0,0 +1,174 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/ecdsa"
+
+	"github.com/pkg/errors"
+)
+
+// RelayCatalog lists the known relays and builders of every network.
+// Relays which stop working are marked as deprecated rather than removed
+// so that instances created from the catalog skip them
+// while callers can still see why a relay is no longer used.
+type RelayCatalog struct {
+	// Version changes whenever a relay is added, removed or deprecated.
+	Version string         `json:"version"`
+	Relays  []CatalogRelay `json:"relays"`
+}
+
+// CatalogRelay is a relay of the catalog.
+type CatalogRelay struct {
+	BuilderPreset
+	// NetIDs are the networks served by the relay.
+	NetIDs     []int64 `json:"netIDs"`
+	Deprecated bool    `json:"deprecated,omitempty"`
+	// Note is the reason for the deprecation.
+	Note string `json:"note,omitempty"`
+}
+
+var catalogDefault = RelayCatalog{
+	Version: "1",
+	Relays: []CatalogRelay{
+		{
+			BuilderPreset: BuilderPreset{
+				Name: "flashbots",
+				URL:  "https://relay.flashbots.net",
+			},
+			NetIDs: []int64{1},
+		},
+		{
+			BuilderPreset: BuilderPreset{
+				Name: "titanbuilder",
+				URL:  "https://rpc.titanbuilder.xyz",
+				Methods: []string{
+					MethodSendBundle,
+					MethodCancelBundle,
+					MethodSendPrivateTransaction,
+					MethodSendPrivateRawTransaction,
+				},
+			},
+			NetIDs: []int64{1},
+		},
+		{
+			BuilderPreset: BuilderPreset{
+				Name: "beaverbuild",
+				URL:  "https://rpc.beaverbuild.org",
+				Methods: []string{
+					MethodSendBundle,
+					MethodCancelBundle,
+					MethodSendPrivateRawTransaction,
+				},
+			},
+			NetIDs: []int64{1},
+		},
+		{
+			BuilderPreset: BuilderPreset{
+				Name: "rsync-builder",
+				URL:  "https://rsync-builder.xyz",
+				Methods: []string{
+					MethodSendBundle,
+					MethodCancelBundle,
+					MethodSendPrivateRawTransaction,
+				},
+			},
+			NetIDs: []int64{1},
+		},
+		{
+			BuilderPreset: BuilderPreset{
+				Name:      "48club",
+				URL:       Club48URL,
+				Methods:   []string{MethodSendBundle},
+				Configure: configureClub48,
+			},
+			NetIDs: []int64{56},
+		},
+	},
+}
+
+// DefaultCatalog returns a copy of the builtin catalog which can be changed
+// and passed to NewAllWithOpts.
+func DefaultCatalog() *RelayCatalog {
+	catalog := &RelayCatalog{
+		Version: catalogDefault.Version,
+		Relays:  make([]CatalogRelay, len(catalogDefault.Relays)),
+	}
+	for i, r := range catalogDefault.Relays {
+		r.Methods = append([]string(nil), r.Methods...)
+		r.NetIDs = append([]int64(nil), r.NetIDs...)
+		catalog.Relays[i] = r
+	}
+	return catalog
+}
+
+// Presets returns the relays of the network in the catalog order.
+func (self *RelayCatalog) Presets(netID int64, includeDeprecated bool) []BuilderPreset {
+	var presets []BuilderPreset
+	for _, r := range self.Relays {
+		if r.Deprecated && !includeDeprecated {
+			continue
+		}
+		for _, id := range r.NetIDs {
+			if id == netID {
+				presets = append(presets, r.BuilderPreset)
+				break
+			}
+		}
+	}
+	return presets
+}
+
+// Deprecate marks the relay with the name as deprecated.
+func (self *RelayCatalog) Deprecate(name, note string) error {
+	for i := range self.Relays {
+		if self.Relays[i].Name == name {
+			self.Relays[i].Deprecated = true
+			self.Relays[i].Note = note
+			return nil
+		}
+	}
+	return errors.Errorf("relay not in the catalog:%v", name)
+}
+
+// NewAllOpts are the options of NewAllWithOpts.
+type NewAllOpts struct {
+	// Catalog replaces the default catalog.
+	Catalog *RelayCatalog
+	// IncludeDeprecated also creates instances for the deprecated relays of the catalog.
+	IncludeDeprecated bool
+}
+
+// NewAllWithOpts is NewAll with a custom catalog or with the deprecated relays.
+func NewAllWithOpts(netID int64, prvKey *ecdsa.PrivateKey, opts NewAllOpts, additional ...*Api) ([]Flashboter, error) {
+	apis, err := catalogApis(netID, opts)
+	if err != nil {
+		return nil, err
+	}
+	apis = append(apis, additional...)
+	return NewMulti(netID, prvKey, apis...)
+}
+
+// catalogApis returns the default api of the network and the apis of its catalog relays.
+func catalogApis(netID int64, opts NewAllOpts) ([]*Api, error) {
+	catalog := opts.Catalog
+	if catalog == nil {
+		catalog = DefaultCatalog()
+	}
+
+	var apis []*Api
+	ep, err := DefaultApi(netID)
+	if err != nil {
+		return nil, errors.Wrap(err, "create default api")
+	}
+	apis = append(apis, ep)
+
+	for _, b := range catalog.Presets(netID, opts.IncludeDeprecated) {
+		if b.URL == ep.URL {
+			continue
+		}
+		apis = append(apis, b.Api())
+	}
+	return apis, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCatalog(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	catalog := DefaultCatalog()
+	testutil.Ok(t, catalog.Deprecate("beaverbuild", "endpoint offline"))
+	testutil.NotOk(t, catalog.Deprecate("unknown", ""))
+	testutil.Assert(t, !DefaultCatalog().Relays[2].Deprecated, "changes to the copy shouldn't change the default catalog")
+	catalog.Relays = append(catalog.Relays, CatalogRelay{
+		BuilderPreset: BuilderPreset{Name: "devnet", URL: "http://devnet"},
+		NetIDs:        []int64{1, 5},
+	})
+
+	urls := func(flashbots []Flashboter) []string {
+		var urls []string
+		for _, f := range flashbots {
+			urls = append(urls, f.Api().URL)
+		}
+		return urls
+	}
+
+	flashbots, err := NewAllWithOpts(1, privKey, NewAllOpts{Catalog: catalog})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{
+		"https://relay.flashbots.net",
+		"https://rpc.titanbuilder.xyz",
+		"https://rsync-builder.xyz",
+		"http://devnet",
+	}, urls(flashbots))
+
+	flashbots, err = NewAllWithOpts(1, privKey, NewAllOpts{Catalog: catalog, IncludeDeprecated: true})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 5, len(flashbots))
+
+	flashbots, err = NewAllWithOpts(5, privKey, NewAllOpts{Catalog: catalog}, &Api{URL: "http://localhost"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"https://relay-goerli.flashbots.net", "http://devnet", "http://localhost"}, urls(flashbots))
+}
@@ -0,0 +1,75 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// Club48URL is the 48 Club Puissant builder on BSC.
+const Club48URL = "https://puissant-builder.48.club"
+
+// ParamsClub48Bundle is the eth_sendBundle request schema of 48 Club
+// which takes the last block of the bundle as a number.
+type ParamsClub48Bundle struct {
+	Txs               []string `json:"txs"`
+	MaxBlockNumber    uint64   `json:"maxBlockNumber,omitempty"`
+	MinTimestamp      uint64   `json:"minTimestamp,omitempty"`
+	MaxTimestamp      uint64   `json:"maxTimestamp,omitempty"`
+	RevertingTxHashes []string `json:"revertingTxHashes,omitempty"`
+}
+
+// Club48Api creates the api for the 48 Club builder.
+// 48 Club doesn't use the flashbots signature and
+// returns the bundle hash as a plain string.
+func Club48Api() *Api {
+	api := &Api{URL: Club48URL}
+	configureClub48(api)
+	return api
+}
+
+func configureClub48(api *Api) {
+	api.SupportedMethods = []string{MethodSendBundle}
+	api.SupportsSimulation = false
+	api.SupportsStats = false
+	api.SkipFlashbotsSignature = true
+	api.ParamsTransform = club48Params
+	api.ResultTransform = club48Result
+}
+
+func club48Params(method string, params []interface{}) (interface{}, error) {
+	if len(params) != 1 {
+		return nil, errors.Errorf("expected a single param got:%v", len(params))
+	}
+	p, ok := params[0].(ParamsSend)
+	if !ok {
+		return nil, errors.Errorf("method not supported by 48 club:%v", method)
+	}
+	var maxBlock uint64
+	if p.BlockNum != "" {
+		var err error
+		if maxBlock, err = hexutil.DecodeUint64(p.BlockNum); err != nil {
+			return nil, errors.Wrapf(err, "decoding block number:%v", p.BlockNum)
+		}
+	}
+	return []interface{}{ParamsClub48Bundle{
+		Txs:               p.Txs,
+		MaxBlockNumber:    maxBlock,
+		MinTimestamp:      p.MinTimestamp,
+		MaxTimestamp:      p.MaxTimestamp,
+		RevertingTxHashes: p.RevertingTxHashes,
+	}}, nil
+}
+
+func club48Result(method string, result json.RawMessage) (json.RawMessage, error) {
+	var hash string
+	if err := json.Unmarshal(result, &hash); err != nil {
+		// Not a plain hash so keep it for the default decoding.
+		return result, nil
+	}
+	return json.Marshal(Result{BundleHash: hash})
+}
@@ -0,0 +1,63 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+func TestClub48(t *testing.T) {
+	var (
+		msg    *jsonrpcMessage
+		header http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg = &jsonrpcMessage{}
+		header = r.Header
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	api, err := DefaultApi(56)
+	testutil.Ok(t, err)
+	testutil.Equals(t, Club48URL, api.URL)
+	api.URL = srv.URL
+
+	flashbot, err := New(nil, api)
+	testutil.Ok(t, err)
+
+	revertHash := common.HexToHash("0x2")
+	resp, err := flashbot.SendBundle(context.Background(), []string{"0xaa"}, 10, &SendBundleOpts{
+		RevertingTxHashes: []common.Hash{revertHash},
+		MaxTimestamp:      100,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.BundleHash)
+	testutil.Equals(t, MethodSendBundle, msg.Method)
+	testutil.Equals(t, "", header.Get("X-Flashbots-Signature"))
+
+	var params []ParamsClub48Bundle
+	testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+	testutil.Equals(t, []ParamsClub48Bundle{{
+		Txs:               []string{"0xaa"},
+		MaxBlockNumber:    10,
+		MaxTimestamp:      100,
+		RevertingTxHashes: []string{revertHash.Hex()},
+	}}, params)
+
+	_, err = flashbot.CallBundle(context.Background(), []string{"0xaa"}, 10, nil)
+	testutil.NotOk(t, err)
+	_, err = flashbot.SendPrivateTransaction(context.Background(), "0xaa", 10, nil)
+	testutil.Assert(t, errors.Is(err, ErrMethodNotSupported), "unexpected error:%v", err)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Command flashbot sends requests to the relays for ad-hoc operations.
+//
+// The relays and the signing key are configured with the FLASHBOT_ environment
+// variables read by flashbot.FromEnv and the flags override them.
+//
+//	FLASHBOT_SIGNING_KEY=... flashbot send-bundle -network 1 -block 15000000 0x02f8...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/pkg/errors"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = []command{
+	{
+		name:  "send-bundle",
+		usage: "send a bundle of signed transactions for a target block",
+		run:   sendBundle,
+	},
+	{
+		name:  "simulate",
+		usage: "simulate a bundle and print the gas, reverts and coinbase diff of its transactions",
+		run:   simulate,
+	},
+	{
+		name:  "bundle-stats",
+		usage: "print the bundle stats reported by the relays",
+		run:   bundleStats,
+	},
+	{
+		name:  "user-stats",
+		usage: "print the reputation of the signing key reported by the relays",
+		run:   userStats,
+	},
+	{
+		name:  "send-private-tx",
+		usage: "send a signed transaction with eth_sendPrivateRawTransaction",
+		run:   sendPrivateTx,
+	},
+	{
+		name:  "cancel-private-tx",
+		usage: "cancel a private transaction",
+		run:   cancelPrivateTx,
+	},
+	{
+		name:  "private-tx-status",
+		usage: "print or wait for the Protect status of a private transaction",
+		run:   privateTxStatus,
+	},
+	{
+		name:  "watch",
+		usage: "follow the inclusion of a bundle and stream its events",
+		run:   watch,
+	},
+}
+
+// cli holds the process io so the commands can be tested.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+	dial   func(ctx context.Context, url string) (flashbot.ChainReader, error)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := &cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+		dial:   dialChain,
+	}
+	if err := c.run(ctx, os.Args[1:]); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(c.stderr, "error: %v\n", err)
+		}
+		stop()
+		os.Exit(1)
+	}
+}
+
+func (self *cli) run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		self.usage()
+		return flag.ErrHelp
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, self, args[1:])
+		}
+	}
+	self.usage()
+	return errors.Errorf("unknown command:%v", args[0])
+}
+
+func (self *cli) usage() {
+	fmt.Fprintf(self.stderr, "Usage: flashbot <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(self.stderr, "  %-14v %v\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(self.stderr, "\nRun flashbot <command> -h for the flags of a command.\n")
+}
+
+func (self *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(self.stderr)
+	return fs
+}
+
+// output writes v as indented JSON.
+func (self *cli) output(v interface{}) error {
+	enc := json.NewEncoder(self.stdout)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(v), "encoding output")
+}
+
+// relayFlags are the flags of the commands which send requests to the relays.
+// The signing key is only read from the environment to keep it out of the shell history.
+type relayFlags struct {
+	network    string
+	relays     string
+	relaysFile string
+	keyFile    string
+	timeout    string
+}
+
+func (self *relayFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&self.network, "network", "", "network id selecting the default relays, overrides "+flashbot.EnvNetworkID)
+	fs.StringVar(&self.relays, "relays", "", "comma separated relay urls, overrides "+flashbot.EnvRelays)
+	fs.StringVar(&self.relaysFile, "relays-file", "", "JSON or YAML relays config, overrides "+flashbot.EnvRelaysFile)
+	fs.StringVar(&self.keyFile, "key-file", "", "keystore file of the signing key decrypted with "+flashbot.EnvSigningKeyPassphrase+", overrides "+flashbot.EnvSigningKeyFile)
+	fs.StringVar(&self.timeout, "timeout", "", "timeout of the relay requests, overrides "+flashbot.EnvTimeout)
+}
+
+func (self *relayFlags) multi(c *cli) (*flashbot.Multi, error) {
+	overrides := map[string]string{
+		flashbot.EnvNetworkID:      self.network,
+		flashbot.EnvRelays:         self.relays,
+		flashbot.EnvRelaysFile:     self.relaysFile,
+		flashbot.EnvSigningKeyFile: self.keyFile,
+		flashbot.EnvTimeout:        self.timeout,
+	}
+	return flashbot.FromEnvFunc(func(key string) string {
+		if v := overrides[key]; v != "" {
+			return v
+		}
+		return c.getenv(key)
+	})
+}
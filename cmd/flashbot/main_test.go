@@ -0,0 +1,115 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/log"
+)
+
+func newTestCLI(t *testing.T, stdin string) (*cli, *bytes.Buffer) {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	env := map[string]string{
+		flashbot.EnvSigningKey: hexutil.Encode(crypto.FromECDSA(privKey)),
+	}
+
+	stdout := &bytes.Buffer{}
+	return &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: &bytes.Buffer{},
+		getenv: func(key string) string { return env[key] },
+	}, stdout
+}
+
+func signedTxHex(t *testing.T) string {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	to := common.HexToAddress("0x1")
+	tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+	})
+	testutil.Ok(t, err)
+	raw, err := tx.MarshalBinary()
+	testutil.Ok(t, err)
+	return hexutil.Encode(raw)
+}
+
+func TestSendBundle(t *testing.T) {
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+
+	txHex := signedTxHex(t)
+	txsFile := filepath.Join(t.TempDir(), "txs")
+	testutil.Ok(t, os.WriteFile(txsFile, []byte("# bundle\n"+txHex+"\n\n"), 0o600))
+
+	for name, tc := range map[string]struct {
+		args  []string
+		stdin string
+	}{
+		"args":  {args: []string{txHex}},
+		"file":  {args: []string{"-txs-file", txsFile}},
+		"stdin": {stdin: txHex + "\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, stdout := newTestCLI(t, tc.stdin)
+			args := append([]string{"send-bundle", "-relays", srv.URL, "-block", "10"}, tc.args...)
+			testutil.Ok(t, c.run(context.Background(), args))
+
+			var results []relayResult
+			testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &results))
+			testutil.Equals(t, 1, len(results))
+			testutil.Equals(t, srv.URL, results[0].Relay)
+			testutil.Equals(t, "", results[0].Error)
+			testutil.Assert(t, results[0].Response.BundleHash != "", "missing bundle hash")
+		})
+	}
+}
+
+func TestSendBundleErrors(t *testing.T) {
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+
+	c, _ := newTestCLI(t, "")
+	testutil.NotOk(t, c.run(context.Background(), []string{"send-bundle", "-relays", srv.URL, signedTxHex(t)}))
+
+	c, _ = newTestCLI(t, "")
+	testutil.NotOk(t, c.run(context.Background(), []string{"send-bundle", "-relays", srv.URL, "-block", "10"}))
+
+	// A relay which is down fails the command only when no relay accepted the bundle.
+	c, stdout := newTestCLI(t, "")
+	testutil.Ok(t, c.run(context.Background(), []string{"send-bundle", "-relays", srv.URL + ",http://127.0.0.1:0", "-block", "10", signedTxHex(t)}))
+	var results []relayResult
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &results))
+	testutil.Equals(t, 2, len(results))
+	testutil.Assert(t, results[1].Error != "", "missing error of the failed relay")
+
+	c, _ = newTestCLI(t, "")
+	testutil.NotOk(t, c.run(context.Background(), []string{"send-bundle", "-relays", "http://127.0.0.1:0", "-block", "10", signedTxHex(t)}))
+
+	c, _ = newTestCLI(t, "")
+	testutil.NotOk(t, c.run(context.Background(), []string{"unknown"}))
+}
@@ -0,0 +1,284 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// privateTxResult is the reply of a single relay to a private transaction request.
+type privateTxResult struct {
+	Relay     string `json:"relay"`
+	TxHash    string `json:"txHash,omitempty"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// statusFlags select the Protect status API polled for the private transactions.
+type statusFlags struct {
+	statusURL    string
+	wait         bool
+	pollInterval time.Duration
+}
+
+func (self *statusFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&self.statusURL, "status-url", "", "Protect status API, defaults to the one of the network")
+	fs.BoolVar(&self.wait, "wait", false, "poll the status until the transaction is included, failed or cancelled")
+	fs.DurationVar(&self.pollInterval, "poll-interval", 2*time.Second, "interval of the status polling")
+}
+
+// url returns the status API of the flag or of the network.
+func (self *statusFlags) url(c *cli, network string) (string, error) {
+	if self.statusURL != "" {
+		return self.statusURL, nil
+	}
+	if network == "" {
+		network = c.getenv(flashbot.EnvNetworkID)
+	}
+	if network == "" {
+		return "", errors.Errorf("-status-url, -network or %v is required", flashbot.EnvNetworkID)
+	}
+	netID, err := strconv.ParseInt(network, 10, 64)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing network:%v", network)
+	}
+	return flashbot.ProtectStatusURLDefault(netID)
+}
+
+func sendPrivateTx(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("send-private-tx")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot send-private-tx [flags] [tx hex]\n\n" +
+			"The signed transaction is read from the argument or stdin.\n\n"))
+		fs.PrintDefaults()
+	}
+	var (
+		rf       relayFlags
+		sf       statusFlags
+		hints    = fs.String("hints", "", "comma separated MEV-Share hints: hash, calldata, logs, default_logs, function_selector, contract_address, tx_hash")
+		builders = fs.String("builders", "", "comma separated builders allowed to receive the transaction, all when empty")
+		fast     = fs.Bool("fast", false, "share the transaction with all builders for faster inclusion")
+	)
+	rf.register(fs)
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	txs, err := readTxs(fs.Args(), "", c.stdin)
+	if err != nil {
+		return err
+	}
+	if len(txs) != 1 {
+		return errors.Errorf("expected a single transaction got:%v", len(txs))
+	}
+
+	var prefs *flashbot.PrivateTxPreferences
+	if *hints != "" || *builders != "" || *fast {
+		prefs = &flashbot.PrivateTxPreferences{Fast: *fast}
+		privacy := &flashbot.PrivateTxPrivacy{}
+		for _, h := range splitList(*hints) {
+			privacy.Hints = append(privacy.Hints, flashbot.PrivacyHint(h))
+		}
+		privacy.Builders = splitList(*builders)
+		if len(privacy.Hints) > 0 || len(privacy.Builders) > 0 {
+			prefs.Privacy = privacy
+		}
+	}
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+	results, err := privateTxAll(multi, func(f flashbot.Flashboter) (privateTxResult, error) {
+		resp, err := f.SendPrivateRawTransaction(ctx, txs[0], prefs)
+		if err != nil {
+			return privateTxResult{}, err
+		}
+		return privateTxResult{TxHash: resp.Result}, nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.outputPrivateTx(results); err != nil || !sf.wait {
+		return err
+	}
+
+	url, err := sf.url(c, rf.network)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Error == "" {
+			return c.waitPrivateTx(ctx, url, common.HexToHash(r.TxHash), sf.pollInterval)
+		}
+	}
+	return nil
+}
+
+func cancelPrivateTx(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("cancel-private-tx")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot cancel-private-tx [flags] <tx hash>\n\n"))
+		fs.PrintDefaults()
+	}
+	var rf relayFlags
+	rf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("tx hash is required")
+	}
+	hash := common.HexToHash(fs.Arg(0))
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+	results, err := privateTxAll(multi, func(f flashbot.Flashboter) (privateTxResult, error) {
+		resp, err := f.CancelPrivateTransaction(ctx, hash)
+		if err != nil {
+			return privateTxResult{}, err
+		}
+		return privateTxResult{TxHash: hash.Hex(), Cancelled: resp.Result}, nil
+	})
+	if err != nil {
+		return err
+	}
+	return c.outputPrivateTx(results)
+}
+
+func privateTxStatus(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("private-tx-status")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot private-tx-status [flags] <tx hash>\n\n"))
+		fs.PrintDefaults()
+	}
+	var sf statusFlags
+	network := fs.String("network", "", "network id selecting the status API, overrides "+flashbot.EnvNetworkID)
+	sf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("tx hash is required")
+	}
+	hash := common.HexToHash(fs.Arg(0))
+
+	url, err := sf.url(c, *network)
+	if err != nil {
+		return err
+	}
+	if sf.wait {
+		return c.waitPrivateTx(ctx, url, hash, sf.pollInterval)
+	}
+	status, err := flashbot.GetProtectTxStatus(ctx, nil, url, hash)
+	if err != nil {
+		return err
+	}
+	return c.output(status)
+}
+
+// waitPrivateTx polls the status API until the transaction is in a terminal state
+// and fails when it isn't included.
+// The status changes are written to stderr and the final status to stdout.
+func (self *cli) waitPrivateTx(ctx context.Context, url string, hash common.Hash, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var last flashbot.PrivateTxStatus
+	for {
+		status, err := flashbot.GetProtectTxStatus(ctx, nil, url, hash)
+		if err != nil {
+			// The status API is polled again so temporary failures don't stop the wait.
+			fmt.Fprintf(self.stderr, "reading status tx:%v err:%v\n", hash.Hex(), err)
+		} else {
+			if status.Status != last {
+				fmt.Fprintf(self.stderr, "tx:%v status:%v\n", hash.Hex(), status.Status)
+				last = status.Status
+			}
+			switch status.Status {
+			case flashbot.PrivateTxIncluded:
+				return self.output(status)
+			case flashbot.PrivateTxFailed, flashbot.PrivateTxCancelled:
+				if err := self.output(status); err != nil {
+					return err
+				}
+				return errors.Errorf("private tx not included status:%v", status.Status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// privateTxAll concurrently sends the request to the relays which support private transactions.
+func privateTxAll(multi *flashbot.Multi, send func(flashbot.Flashboter) (privateTxResult, error)) ([]privateTxResult, error) {
+	var flashbots []flashbot.Flashboter
+	for _, f := range multi.Flashbots() {
+		if f.Api().Supports(flashbot.MethodSendPrivateRawTransaction) {
+			flashbots = append(flashbots, f)
+		}
+	}
+	if len(flashbots) == 0 {
+		return nil, errors.New("none of the relays support private transactions")
+	}
+
+	results := make([]privateTxResult, len(flashbots))
+	var wg sync.WaitGroup
+	for i, f := range flashbots {
+		wg.Add(1)
+		go func(i int, f flashbot.Flashboter) {
+			defer wg.Done()
+			r, err := send(f)
+			if err != nil {
+				r.Error = err.Error()
+			}
+			r.Relay = f.Api().URL
+			results[i] = r
+		}(i, f)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// outputPrivateTx fails when none of the relays accepted the request.
+func (self *cli) outputPrivateTx(results []privateTxResult) error {
+	if err := self.output(results); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Error == "" {
+			return nil
+		}
+	}
+	return errors.Errorf("all %v relays failed", len(results))
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
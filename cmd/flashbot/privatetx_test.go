@@ -0,0 +1,106 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+// statusServer replies with the statuses in order and repeats the last one.
+func statusServer(t *testing.T, statuses ...flashbot.PrivateTxStatus) *httptest.Server {
+	var calls int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(atomic.AddInt32(&calls, 1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		hash := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		testutil.Ok(t, json.NewEncoder(w).Encode(map[string]interface{}{"status": statuses[i], "hash": hash}))
+	}))
+}
+
+func TestSendPrivateTx(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+	status := statusServer(t, flashbot.PrivateTxPending, flashbot.PrivateTxIncluded)
+	defer status.Close()
+
+	c, stdout := newTestCLI(t, signedTxHex(t))
+	testutil.Ok(t, c.run(ctx, []string{"send-private-tx", "-relays", srv.URL, "-wait", "-status-url", status.URL, "-poll-interval", "1ms"}))
+
+	dec := json.NewDecoder(stdout)
+	var results []privateTxResult
+	testutil.Ok(t, dec.Decode(&results))
+	testutil.Equals(t, 1, len(results))
+	testutil.Equals(t, "", results[0].Error)
+	var final flashbot.ProtectTxStatus
+	testutil.Ok(t, dec.Decode(&final))
+	testutil.Equals(t, flashbot.PrivateTxIncluded, final.Status)
+	testutil.Equals(t, results[0].TxHash, final.Hash.Hex())
+
+	stdout.Reset()
+	testutil.Ok(t, c.run(ctx, []string{"cancel-private-tx", "-relays", srv.URL, results[0].TxHash}))
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &results))
+	testutil.Assert(t, results[0].Cancelled, "tx should be cancelled")
+}
+
+func TestSendPrivateTxPreferences(t *testing.T) {
+	var params []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		var msg struct{ Params []json.RawMessage }
+		testutil.Ok(t, json.Unmarshal(body, &msg))
+		params = msg.Params
+		_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestCLI(t, "")
+	testutil.Ok(t, c.run(context.Background(), []string{"send-private-tx", "-relays", srv.URL, "-hints", "calldata, logs", "-builders", "flashbots", "-fast", signedTxHex(t)}))
+	testutil.Equals(t, 2, len(params))
+	testutil.Equals(t, `{"fast":true,"privacy":{"hints":["calldata","logs"],"builders":["flashbots"]}}`, string(params[1]))
+}
+
+func TestPrivateTxStatus(t *testing.T) {
+	ctx := context.Background()
+	hash := "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+	status := statusServer(t, flashbot.PrivateTxPending, flashbot.PrivateTxFailed)
+	defer status.Close()
+
+	c, stdout := newTestCLI(t, "")
+	testutil.Ok(t, c.run(ctx, []string{"private-tx-status", "-status-url", status.URL, hash}))
+	var s flashbot.ProtectTxStatus
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &s))
+	testutil.Equals(t, flashbot.PrivateTxPending, s.Status)
+
+	stdout.Reset()
+	err := c.run(ctx, []string{"private-tx-status", "-status-url", status.URL, "-wait", "-poll-interval", "1ms", hash})
+	testutil.NotOk(t, err)
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &s))
+	testutil.Equals(t, flashbot.PrivateTxFailed, s.Status)
+
+	// The status API of the network is used without -status-url.
+	testutil.NotOk(t, c.run(ctx, []string{"private-tx-status", hash}))
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = c.run(ctx, []string{"private-tx-status", "-network", "1", "-wait", hash})
+	testutil.Assert(t, errors.Is(err, context.Canceled), "unexpected error:%v", err)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// relayResult is the outcome of the request to a single relay.
+type relayResult struct {
+	Relay    string             `json:"relay"`
+	Response *flashbot.Response `json:"response,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+func sendBundle(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("send-bundle")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot send-bundle -block <number> [flags] [tx hex...]\n\n" +
+			"The signed transactions are read from the arguments, -txs-file or stdin, one per line.\n\n"))
+		fs.PrintDefaults()
+	}
+	var (
+		rf              relayFlags
+		block           = fs.Uint64("block", 0, "target block number, required")
+		txsFile         = fs.String("txs-file", "", "file with the signed transactions, - for stdin")
+		minTimestamp    = fs.Uint64("min-timestamp", 0, "minimum block timestamp for which the bundle is valid")
+		maxTimestamp    = fs.Uint64("max-timestamp", 0, "maximum block timestamp for which the bundle is valid")
+		reverting       = fs.String("reverting-tx-hashes", "", "comma separated hashes of the transactions allowed to revert")
+		replacementUuid = fs.String("replacement-uuid", "", "uuid to replace or cancel the bundle with a later submission")
+	)
+	rf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *block == 0 {
+		fs.Usage()
+		return errors.New("-block is required")
+	}
+
+	txs, err := readTxs(fs.Args(), *txsFile, c.stdin)
+	if err != nil {
+		return err
+	}
+
+	opts := &flashbot.SendBundleOpts{
+		MinTimestamp:    *minTimestamp,
+		MaxTimestamp:    *maxTimestamp,
+		ReplacementUuid: *replacementUuid,
+	}
+	for _, h := range splitList(*reverting) {
+		opts.RevertingTxHashes = append(opts.RevertingTxHashes, common.HexToHash(h))
+	}
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+
+	resps, err := multi.SendBundleAll(ctx, txs, *block, opts)
+	results := make([]relayResult, len(resps))
+	for i, r := range resps {
+		results[i] = relayResult{Relay: r.Relay, Response: r.Resp}
+		if r.Err != nil {
+			results[i].Error = r.Err.Error()
+		}
+	}
+	if len(results) > 0 {
+		if err := c.output(results); err != nil {
+			return err
+		}
+	}
+	// The bundle was sent when at least one of the relays accepted it.
+	if multiErr, ok := err.(*flashbot.MultiError); ok && multiErr.Partial() {
+		return nil
+	}
+	return err
+}
+
+// readTxs returns the transactions from the arguments or from the file,
+// stdin is used when the file is - or when there are no arguments.
+func readTxs(args []string, file string, stdin io.Reader) ([]string, error) {
+	if file == "" && len(args) > 0 && !(len(args) == 1 && args[0] == "-") {
+		return args, nil
+	}
+
+	r := stdin
+	if file != "" && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening txs file")
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var txs []string
+	scanner := bufio.NewScanner(r)
+	// Blob and large contract creation transactions exceed the default token size.
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		txs = append(txs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading txs")
+	}
+	if len(txs) == 0 {
+		return nil, errors.New("no transactions")
+	}
+	return txs, nil
+}
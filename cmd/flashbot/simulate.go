@@ -0,0 +1,209 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// simulation is the CallBundle result of a bundle
+// with the gas estimates of the transactions when the relay supports them.
+type simulation struct {
+	Relay             string         `json:"relay"`
+	BundleHash        string         `json:"bundleHash"`
+	BundleGasPrice    string         `json:"bundleGasPrice"`
+	CoinbaseDiff      string         `json:"coinbaseDiff"`
+	EthSentToCoinbase string         `json:"ethSentToCoinbase"`
+	GasFees           string         `json:"gasFees"`
+	Txs               []simulationTx `json:"txs"`
+}
+
+type simulationTx struct {
+	TxHash       string `json:"txHash"`
+	From         string `json:"from"`
+	GasUsed      uint64 `json:"gasUsed"`
+	GasEstimate  uint64 `json:"gasEstimate,omitempty"`
+	GasPrice     string `json:"gasPrice"`
+	CoinbaseDiff string `json:"coinbaseDiff"`
+	Error        string `json:"error,omitempty"`
+	Revert       string `json:"revert,omitempty"`
+}
+
+func simulate(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("simulate")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot simulate [flags] [tx hex...]\n\n" +
+			"The bundle is simulated with the first relay which supports simulations.\n" +
+			"The signed transactions are read from the arguments, -txs-file or stdin, one per line.\n\n"))
+		fs.PrintDefaults()
+	}
+	var (
+		rf          relayFlags
+		block       = fs.Uint64("block", 0, "state block number of the simulation, defaults to the latest block")
+		targetBlock = fs.Uint64("target-block", 0, "block number of the simulated block, defaults to the block after the state block")
+		timestamp   = fs.Uint64("timestamp", 0, "timestamp of the simulated block in unix seconds")
+		txsFile     = fs.String("txs-file", "", "file with the signed transactions, - for stdin")
+		format      = fs.String("format", "table", "output format: table or json")
+	)
+	rf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "table" && *format != "json" {
+		return errors.Errorf("unknown format:%v", *format)
+	}
+
+	txs, err := readTxs(fs.Args(), *txsFile, c.stdin)
+	if err != nil {
+		return err
+	}
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+	var relay flashbot.Flashboter
+	for _, f := range multi.Flashbots() {
+		if f.Api().SupportsSimulation {
+			relay = f
+			break
+		}
+	}
+	if relay == nil {
+		return errors.New("none of the relays support simulations")
+	}
+
+	resp, err := relay.CallBundle(ctx, txs, *block, &flashbot.CallBundleOpts{
+		TargetBlockNum: *targetBlock,
+		Timestamp:      *timestamp,
+	})
+	if err != nil {
+		return errors.Wrap(err, "simulating bundle")
+	}
+
+	estimates, err := estimateGas(ctx, relay, txs, *block)
+	if err != nil {
+		return err
+	}
+
+	sim := newSimulation(relay.Api().URL, resp, estimates)
+	if *format == "json" {
+		err = c.output(sim)
+	} else {
+		err = c.outputSimulation(sim)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Fail the command so scripts can check whether the bundle reverted.
+	return resp.RevertErr()
+}
+
+// estimateGas returns nil when the relay doesn't implement eth_estimateGasBundle.
+func estimateGas(ctx context.Context, relay flashbot.Flashboter, txsHex []string, blockNumState uint64) ([]uint64, error) {
+	if !relay.Api().Supports(flashbot.MethodEstimateGasBundle) {
+		return nil, nil
+	}
+
+	txs := make([]flashbot.Tx, len(txsHex))
+	for i, txHex := range txsHex {
+		raw, err := hexutil.Decode(txHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding tx index:%v", i)
+		}
+		tx := &types.Transaction{}
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, errors.Wrapf(err, "decoding tx index:%v", i)
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "recovering tx sender index:%v", i)
+		}
+		txs[i] = flashbot.Tx{From: from, Data: tx.Data()}
+		if tx.To() != nil {
+			txs[i].To = *tx.To()
+		}
+	}
+
+	resp, err := relay.EstimateGasBundle(ctx, txs, blockNumState, nil)
+	if err != nil {
+		if errors.Is(err, flashbot.ErrUnknownMethod) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "estimating bundle gas")
+	}
+	estimates := make([]uint64, len(resp.Results))
+	for i, r := range resp.Results {
+		estimates[i] = r.GasUsed
+	}
+	return estimates, nil
+}
+
+func newSimulation(relay string, resp *flashbot.Response, estimates []uint64) *simulation {
+	sim := &simulation{
+		Relay:             relay,
+		BundleHash:        resp.BundleHash,
+		BundleGasPrice:    resp.BundleGasPrice,
+		CoinbaseDiff:      resp.CoinbaseDiff,
+		EthSentToCoinbase: resp.EthSentToCoinbase,
+		GasFees:           resp.GasFees,
+	}
+	for i, r := range resp.Results {
+		tx := simulationTx{
+			TxHash:       r.TxHash,
+			From:         r.FromAddress,
+			GasUsed:      r.GasUsed,
+			GasPrice:     r.GasPrice,
+			CoinbaseDiff: r.CoinbaseDiff,
+			Error:        r.Error,
+			Revert:       r.Revert,
+		}
+		if i < len(estimates) {
+			tx.GasEstimate = estimates[i]
+		}
+		sim.Txs = append(sim.Txs, tx)
+	}
+	return sim
+}
+
+func (self *cli) outputSimulation(sim *simulation) error {
+	w := tabwriter.NewWriter(self.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "relay:\t%v\n", sim.Relay)
+	fmt.Fprintf(w, "bundle hash:\t%v\n", sim.BundleHash)
+	fmt.Fprintf(w, "bundle gas price:\t%v\n", sim.BundleGasPrice)
+	fmt.Fprintf(w, "coinbase diff:\t%v\n", sim.CoinbaseDiff)
+	fmt.Fprintf(w, "eth sent to coinbase:\t%v\n", sim.EthSentToCoinbase)
+	fmt.Fprintf(w, "gas fees:\t%v\n", sim.GasFees)
+	if err := w.Flush(); err != nil {
+		return errors.Wrap(err, "writing output")
+	}
+
+	fmt.Fprintln(self.stdout)
+	w = tabwriter.NewWriter(self.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "#\tTX HASH\tFROM\tGAS USED\tGAS ESTIMATE\tGAS PRICE\tCOINBASE DIFF\tREVERT\n")
+	for i, tx := range sim.Txs {
+		estimate := "-"
+		if tx.GasEstimate != 0 {
+			estimate = fmt.Sprint(tx.GasEstimate)
+		}
+		revert := "-"
+		if tx.Error != "" {
+			revert = tx.Error
+			if tx.Revert != "" {
+				revert += ": " + tx.Revert
+			}
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			i, tx.TxHash, tx.From, tx.GasUsed, estimate, tx.GasPrice, tx.CoinbaseDiff, revert)
+	}
+	return errors.Wrap(w.Flush(), "writing output")
+}
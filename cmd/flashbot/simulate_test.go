@@ -0,0 +1,69 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+// simulationRelaysFile writes a relays config for a relay which supports simulations.
+func simulationRelaysFile(t *testing.T, url string) string {
+	cfg, err := json.Marshal(flashbot.ApisConfig{
+		Relays: []flashbot.ApiConfig{{URL: url, SupportsSimulation: true, SupportsStats: true}},
+	})
+	testutil.Ok(t, err)
+	path := filepath.Join(t.TempDir(), "relays.json")
+	testutil.Ok(t, os.WriteFile(path, cfg, 0o600))
+	return path
+}
+
+func TestSimulate(t *testing.T) {
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+	relaysFile := simulationRelaysFile(t, srv.URL)
+	txHex := signedTxHex(t)
+
+	c, stdout := newTestCLI(t, "")
+	testutil.Ok(t, c.run(context.Background(), []string{"simulate", "-relays-file", relaysFile, "-format", "json", txHex}))
+	var sim simulation
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &sim))
+	testutil.Equals(t, srv.URL, sim.Relay)
+	testutil.Equals(t, 1, len(sim.Txs))
+	testutil.Equals(t, uint64(21000), sim.Txs[0].GasUsed)
+	testutil.Equals(t, "", sim.Txs[0].Error)
+
+	c, stdout = newTestCLI(t, "")
+	testutil.Ok(t, c.run(context.Background(), []string{"simulate", "-relays-file", relaysFile, txHex}))
+	testutil.Assert(t, strings.Contains(stdout.String(), "GAS USED"), "missing table header:%v", stdout.String())
+	testutil.Assert(t, strings.Contains(stdout.String(), sim.Txs[0].TxHash), "missing tx hash:%v", stdout.String())
+}
+
+func TestSimulateRevert(t *testing.T) {
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{
+		RevertAddresses: []common.Address{common.HexToAddress("0x1")},
+	}))
+	defer srv.Close()
+
+	c, stdout := newTestCLI(t, "")
+	err := c.run(context.Background(), []string{"simulate", "-relays-file", simulationRelaysFile(t, srv.URL), signedTxHex(t)})
+	testutil.Assert(t, errors.Is(err, flashbot.ErrBundleReverted), "unexpected error:%v", err)
+	testutil.Assert(t, strings.Contains(stdout.String(), "mockrelay: revert"), "missing revert reason:%v", stdout.String())
+
+	// Relays without simulations can't be used.
+	c, _ = newTestCLI(t, "")
+	testutil.NotOk(t, c.run(context.Background(), []string{"simulate", "-relays", srv.URL, signedTxHex(t)}))
+}
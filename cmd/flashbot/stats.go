@@ -0,0 +1,144 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/pkg/errors"
+)
+
+// relayStats is the reply of a single relay to a stats request.
+type relayStats struct {
+	Relay string      `json:"relay"`
+	Stats interface{} `json:"stats,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+func bundleStats(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("bundle-stats")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot bundle-stats [flags] <bundle hash> <block>\n\n"))
+		fs.PrintDefaults()
+	}
+	var rf relayFlags
+	v2 := fs.Bool("v2", false, "use flashbots_getBundleStatsV2 which reports the builders that considered the bundle")
+	rf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errors.New("bundle hash and block are required")
+	}
+	bundleHash := fs.Arg(0)
+	block, err := strconv.ParseUint(fs.Arg(1), 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "parsing block:%v", fs.Arg(1))
+	}
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+	return c.outputStats(statsAll(ctx, multi, func(f flashbot.Flashboter) (interface{}, error) {
+		get := f.GetBundleStats
+		if *v2 {
+			get = f.GetBundleStatsV2
+		}
+		resp, err := get(ctx, bundleHash, block)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result, nil
+	}))
+}
+
+func userStats(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("user-stats")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot user-stats [flags] <block>\n\n"))
+		fs.PrintDefaults()
+	}
+	var rf relayFlags
+	v2 := fs.Bool("v2", false, "use flashbots_getUserStatsV2 which reports the payments to validators")
+	rf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("block is required")
+	}
+	block, err := strconv.ParseUint(fs.Arg(0), 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "parsing block:%v", fs.Arg(0))
+	}
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+	return c.outputStats(statsAll(ctx, multi, func(f flashbot.Flashboter) (interface{}, error) {
+		if *v2 {
+			resp, err := f.GetUserStatsV2(ctx, block)
+			if err != nil {
+				return nil, err
+			}
+			return resp.Result, nil
+		}
+		resp, err := f.GetUserStats(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Result, nil
+	}))
+}
+
+// statsAll concurrently queries the relays which support stats.
+func statsAll(ctx context.Context, multi *flashbot.Multi, get func(flashbot.Flashboter) (interface{}, error)) []relayStats {
+	var flashbots []flashbot.Flashboter
+	for _, f := range multi.Flashbots() {
+		if f.Api().SupportsStats {
+			flashbots = append(flashbots, f)
+		}
+	}
+
+	stats := make([]relayStats, len(flashbots))
+	var wg sync.WaitGroup
+	for i, f := range flashbots {
+		wg.Add(1)
+		go func(i int, f flashbot.Flashboter) {
+			defer wg.Done()
+			stats[i].Relay = f.Api().URL
+			s, err := get(f)
+			if err != nil {
+				stats[i].Error = err.Error()
+				return
+			}
+			stats[i].Stats = s
+		}(i, f)
+	}
+	wg.Wait()
+	return stats
+}
+
+// outputStats fails when none of the relays replied.
+func (self *cli) outputStats(stats []relayStats) error {
+	if len(stats) == 0 {
+		return errors.New("none of the relays support stats")
+	}
+	if err := self.output(stats); err != nil {
+		return err
+	}
+	for _, s := range stats {
+		if s.Error == "" {
+			return nil
+		}
+	}
+	return errors.Errorf("all %v relays failed", len(stats))
+}
@@ -0,0 +1,57 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log"
+)
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+	relaysFile := simulationRelaysFile(t, srv.URL)
+
+	c, stdout := newTestCLI(t, "")
+	testutil.Ok(t, c.run(ctx, []string{"send-bundle", "-relays-file", relaysFile, "-block", "10", signedTxHex(t)}))
+	var sent []relayResult
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &sent))
+	bundleHash := sent[0].Response.BundleHash
+
+	var stats []struct {
+		Relay string
+		Stats map[string]interface{}
+		Error string
+	}
+	for _, tc := range []struct {
+		args  []string
+		field string
+	}{
+		{args: []string{"bundle-stats", bundleHash, "10"}, field: "IsSimulated"},
+		{args: []string{"bundle-stats", "-v2", bundleHash, "10"}, field: "ConsideredByBuildersAt"},
+		{args: []string{"user-stats", "10"}, field: "all_time_miner_payments"},
+		{args: []string{"user-stats", "-v2", "10"}, field: "allTimeValidatorPayments"},
+	} {
+		stdout.Reset()
+		args := append([]string{tc.args[0], "-relays-file", relaysFile}, tc.args[1:]...)
+		testutil.Ok(t, c.run(ctx, args))
+		testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &stats))
+		testutil.Equals(t, 1, len(stats))
+		testutil.Equals(t, "", stats[0].Error)
+		_, ok := stats[0].Stats[tc.field]
+		testutil.Assert(t, ok, "missing field:%v in %v", tc.field, stdout.String())
+	}
+
+	testutil.NotOk(t, c.run(ctx, []string{"bundle-stats", "-relays-file", relaysFile, bundleHash}))
+	testutil.NotOk(t, c.run(ctx, []string{"user-stats", "-relays-file", relaysFile, "latest"}))
+	// Relays without stats can't be used.
+	testutil.NotOk(t, c.run(ctx, []string{"user-stats", "-relays", srv.URL, "10"}))
+}
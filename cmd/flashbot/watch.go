@@ -0,0 +1,138 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+)
+
+// watchEvent is a flashbot.Event with the error as a string so it can be encoded.
+type watchEvent struct {
+	Type        flashbot.EventType    `json:"type"`
+	BundleHash  string                `json:"bundleHash,omitempty"`
+	BlockNum    uint64                `json:"blockNumber"`
+	Relay       string                `json:"relay"`
+	Builder     string                `json:"builder,omitempty"`
+	Error       string                `json:"error,omitempty"`
+	Attribution *flashbot.Attribution `json:"attribution,omitempty"`
+	Time        time.Time             `json:"time"`
+}
+
+func dialChain(ctx context.Context, url string) (flashbot.ChainReader, error) {
+	return ethclient.DialContext(ctx, url)
+}
+
+func watch(ctx context.Context, c *cli, args []string) error {
+	fs := c.flagSet("watch")
+	fs.Usage = func() {
+		fs.Output().Write([]byte("Usage: flashbot watch -block <number> -rpc-url <url> [flags] [tx hash...]\n\n" +
+			"Follows a bundle until its block passes and writes its events to stdout, one JSON object per line.\n" +
+			"The bundle hash is computed from the transaction hashes when -bundle-hash isn't set\n" +
+			"and without the transaction hashes the inclusion can't be checked.\n\n"))
+		fs.PrintDefaults()
+	}
+	var (
+		rf         relayFlags
+		block      = fs.Uint64("block", 0, "target block number of the bundle, required")
+		bundleHash = fs.String("bundle-hash", "", "hash of the bundle")
+		rpcURL     = fs.String("rpc-url", "", "node used to follow the chain, required")
+		interval   = fs.Duration("interval", 2*time.Second, "interval between the checks of the bundle")
+	)
+	rf.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *block == 0 || *rpcURL == "" {
+		fs.Usage()
+		return errors.New("-block and -rpc-url are required")
+	}
+	if *bundleHash == "" && fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("bundle hash or tx hashes are required")
+	}
+	var txHashes []common.Hash
+	for _, h := range fs.Args() {
+		txHashes = append(txHashes, common.HexToHash(h))
+	}
+
+	multi, err := rf.multi(c)
+	if err != nil {
+		return err
+	}
+	// The stats of a single relay are enough to follow the bundle
+	// and the inclusion is read from the chain.
+	relay := multi.Flashbots()[0]
+	for _, f := range multi.Flashbots() {
+		if f.Api().SupportsStats {
+			relay = f
+			break
+		}
+	}
+
+	chain, err := c.dial(ctx, *rpcURL)
+	if err != nil {
+		return errors.Wrap(err, "connecting to the node")
+	}
+
+	// The tracker is stopped once the bundle is resolved.
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	enc := json.NewEncoder(c.stdout)
+	var (
+		encErr   error
+		included bool
+		tracker  *flashbot.Tracker
+	)
+	tracker = flashbot.NewTracker(relay, chain, flashbot.TrackerConfig{
+		Interval: *interval,
+		OnEvent: func(e flashbot.Event) {
+			included = included || e.Type == flashbot.EventIncluded
+			we := watchEvent{
+				Type:        e.Type,
+				BundleHash:  e.BundleHash,
+				BlockNum:    e.BlockNum,
+				Relay:       e.Relay,
+				Builder:     e.Builder,
+				Attribution: e.Attribution,
+				Time:        e.Time,
+			}
+			if e.Err != nil {
+				we.Error = e.Err.Error()
+			}
+			if err := enc.Encode(we); err != nil && encErr == nil {
+				encErr = errors.Wrap(err, "writing event")
+			}
+			if encErr != nil || tracker.Tracked() == 0 {
+				cancel()
+			}
+		},
+	})
+	if err := tracker.WatchBundle(*bundleHash, *block, txHashes); err != nil {
+		return err
+	}
+
+	// Temporary relay or node failures don't stop the tracker.
+	_ = tracker.Run(watchCtx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if encErr != nil {
+		return encErr
+	}
+	if !included {
+		if lastErr := tracker.Status().LastCheckErr; lastErr != "" {
+			fmt.Fprintf(c.stderr, "last check err:%v\n", lastErr)
+		}
+		return errors.New("bundle not included")
+	}
+	return nil
+}
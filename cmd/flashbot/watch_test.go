@@ -0,0 +1,88 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/log"
+)
+
+type chainMock struct {
+	head     uint64
+	included map[common.Hash]bool
+}
+
+func (self *chainMock) BlockNumber(ctx context.Context) (uint64, error) {
+	return self.head, nil
+}
+
+func (self *chainMock) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if !self.included[txHash] {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{TxHash: txHash}, nil
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+	relaysFile := simulationRelaysFile(t, srv.URL)
+
+	txHex := signedTxHex(t)
+	tx := &types.Transaction{}
+	testutil.Ok(t, tx.UnmarshalBinary(hexutil.MustDecode(txHex)))
+	txHash := tx.Hash().Hex()
+
+	c, stdout := newTestCLI(t, "")
+	testutil.Ok(t, c.run(ctx, []string{"send-bundle", "-relays-file", relaysFile, "-block", "10", txHex}))
+	var sent []relayResult
+	testutil.Ok(t, json.Unmarshal(stdout.Bytes(), &sent))
+
+	chain := &chainMock{head: 10, included: map[common.Hash]bool{common.HexToHash(txHash): true}}
+	c.dial = func(ctx context.Context, url string) (flashbot.ChainReader, error) {
+		return chain, nil
+	}
+	events := func() []watchEvent {
+		var events []watchEvent
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			var e watchEvent
+			testutil.Ok(t, json.Unmarshal(scanner.Bytes(), &e))
+			events = append(events, e)
+		}
+		return events
+	}
+
+	stdout.Reset()
+	testutil.Ok(t, c.run(ctx, []string{"watch", "-relays-file", relaysFile, "-rpc-url", "mock", "-interval", "1ms", "-block", "10", txHash}))
+	got := events()
+	testutil.Equals(t, 2, len(got))
+	testutil.Equals(t, flashbot.EventSimulated, got[0].Type)
+	testutil.Equals(t, flashbot.EventIncluded, got[1].Type)
+	testutil.Equals(t, sent[0].Response.BundleHash, got[1].BundleHash)
+	testutil.Equals(t, srv.URL, got[1].Relay)
+
+	// Without the tx hashes the inclusion is unknown.
+	stdout.Reset()
+	testutil.NotOk(t, c.run(ctx, []string{"watch", "-relays-file", relaysFile, "-rpc-url", "mock", "-interval", "1ms", "-block", "10", "-bundle-hash", sent[0].Response.BundleHash}))
+	got = events()
+	testutil.Equals(t, flashbot.EventExpired, got[len(got)-1].Type)
+	testutil.Equals(t, flashbot.ErrInclusionUnknown.Error(), got[len(got)-1].Error)
+
+	testutil.NotOk(t, c.run(ctx, []string{"watch", "-relays-file", relaysFile, "-rpc-url", "mock", "-interval", "1ms", "-block", "10"}))
+	testutil.NotOk(t, c.run(ctx, []string{"watch", "-relays-file", relaysFile, "-block", "10", txHash}))
+}
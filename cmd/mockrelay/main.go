@@ -0,0 +1,87 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Command mockrelay runs a flashbots compatible relay for integration testing of bots.
+package main
+
+import (
+	"context"
+	"flag"
+	"math/big"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+func main() {
+	listen := flag.String("listen", ":8545", "address to listen on")
+	baseFee := flag.Int64("base-fee", 0, "base fee in wei used for the simulations")
+	coinbase := flag.String("coinbase", "", "coinbase address that receives direct payments")
+	reverts := flag.String("revert-addresses", "", "comma separated contract addresses that always revert")
+	skipSig := flag.Bool("skip-signature-check", false, "accept requests without a valid X-Flashbots-Signature")
+	logLevel := flag.String("log-level", "info", "log level: debug, info, warn, error")
+	flag.Parse()
+
+	logger := log.With(
+		log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr)),
+		"ts", log.DefaultTimestampUTC,
+		"caller", log.DefaultCaller,
+	)
+	logger = level.NewFilter(logger, levelOption(*logLevel))
+
+	cfg := mockrelay.Config{
+		BaseFee:            big.NewInt(*baseFee),
+		Coinbase:           common.HexToAddress(*coinbase),
+		SkipSignatureCheck: *skipSig,
+	}
+	for _, addr := range strings.Split(*reverts, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.RevertAddresses = append(cfg.RevertAddresses, common.HexToAddress(addr))
+		}
+	}
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           mockrelay.New(logger, cfg),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctxShutdown); err != nil {
+			level.Error(logger).Log("msg", "shutting down", "err", err)
+		}
+	}()
+
+	level.Info(logger).Log("msg", "mock relay listening", "addr", *listen)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		level.Error(logger).Log("msg", "serving", "err", err)
+		os.Exit(1)
+	}
+}
+
+func levelOption(lvl string) level.Option {
+	switch lvl {
+	case "debug":
+		return level.AllowDebug()
+	case "warn":
+		return level.AllowWarn()
+	case "error":
+		return level.AllowError()
+	default:
+		return level.AllowInfo()
+	}
+}
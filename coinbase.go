@@ -0,0 +1,151 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// coinbaseBribeGasDefault covers the transfer even when the coinbase account doesn't exist yet.
+const coinbaseBribeGasDefault = 100_000
+
+// coinbaseTransferCode is the init code of a contract creation which forwards
+// its value to block.coinbase and reverts when the transfer fails so the value isn't stuck.
+// It returns no runtime code so nothing is deployed.
+//
+//	PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 CALLVALUE COINBASE GAS CALL
+//	PUSH1 0x13 JUMPI PUSH1 0 DUP1 REVERT JUMPDEST STOP
+var coinbaseTransferCode = common.FromHex("0x600060006000600034415af1601357600080fd5b00")
+
+// BundleSimulator simulates bundles, implemented by Flashbot and localsim.Simulator.
+type BundleSimulator interface {
+	CallBundle(ctx context.Context, txsHex []string, blockNumState uint64, opts *CallBundleOpts) (*Response, error)
+}
+
+// CoinbaseBribe is a transaction which pays the block coinbase directly.
+type CoinbaseBribe struct {
+	ChainID *big.Int
+	Nonce   uint64
+	// Amount is transferred to the block coinbase.
+	Amount    *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	// Gas defaults to 100000.
+	Gas uint64
+	// Contract and Data call a deployed contract which forwards the value to block.coinbase.
+	// When nil the value is forwarded by a contract creation that doesn't deploy any code.
+	Contract *common.Address
+	Data     []byte
+}
+
+// Tx returns the unsigned bribe transaction.
+func (self *CoinbaseBribe) Tx() (types.TxData, error) {
+	if self.ChainID == nil {
+		return nil, errors.New("chain id is required")
+	}
+	if self.Amount == nil || self.Amount.Sign() <= 0 {
+		return nil, errors.New("bribe amount must be positive")
+	}
+	if self.GasFeeCap == nil {
+		return nil, errors.New("gas fee cap is required")
+	}
+	tip := self.GasTipCap
+	if tip == nil {
+		tip = big.NewInt(0)
+	}
+	gas := self.Gas
+	if gas == 0 {
+		gas = coinbaseBribeGasDefault
+	}
+	data := self.Data
+	if self.Contract == nil {
+		data = coinbaseTransferCode
+	}
+	return &types.DynamicFeeTx{
+		ChainID:   self.ChainID,
+		Nonce:     self.Nonce,
+		GasTipCap: tip,
+		GasFeeCap: self.GasFeeCap,
+		Gas:       gas,
+		To:        self.Contract,
+		Value:     self.Amount,
+		Data:      data,
+	}, nil
+}
+
+// AppendCoinbaseBribe signs the bribe and appends it as the last transaction of the bundle.
+func AppendCoinbaseBribe(txsHex []string, bribe *CoinbaseBribe, prvKey *ecdsa.PrivateKey) ([]string, error) {
+	tx, err := bribe.Tx()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating bribe tx")
+	}
+	txHex, err := SignTx(bribe.ChainID, tx, prvKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing bribe tx")
+	}
+	return append(append(make([]string, 0, len(txsHex)+1), txsHex...), txHex), nil
+}
+
+// AddCoinbaseBribe adds the bribe transaction signed with the given key by Sign.
+func (self *BundleBuilder) AddCoinbaseBribe(bribe *CoinbaseBribe, prvKey *ecdsa.PrivateKey) *BundleBuilder {
+	tx, err := bribe.Tx()
+	if err != nil {
+		if self.err == nil {
+			self.err = errors.Wrap(err, "creating bribe tx")
+		}
+		return self
+	}
+	return self.AddUnsignedTx(tx, prvKey)
+}
+
+// BribeSimulation is the result of SimulateCoinbaseBribe.
+type BribeSimulation struct {
+	// Txs is the bundle with the bribe appended.
+	Txs  []string
+	Resp *Response
+	// CoinbaseDiff is the payment to the coinbase of the whole bundle including gas fees.
+	CoinbaseDiff *big.Int
+	// Paid is the amount the bribe transaction transferred to the coinbase.
+	Paid *big.Int
+}
+
+// SimulateCoinbaseBribe appends the bribe to the bundle and simulates it
+// to check that the coinbase receives the bribe.
+// An error is returned when the bribe transaction fails or pays less than its amount,
+// for example when the coinbase is a contract that rejects transfers.
+func SimulateCoinbaseBribe(ctx context.Context, sim BundleSimulator, txsHex []string, bribe *CoinbaseBribe, prvKey *ecdsa.PrivateKey, blockNumState uint64) (*BribeSimulation, error) {
+	txs, err := AppendCoinbaseBribe(txsHex, bribe, prvKey)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sim.CallBundle(ctx, txs, blockNumState, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "simulating bundle with bribe")
+	}
+	if len(resp.Results) != len(txs) {
+		return nil, errors.Errorf("unexpected number of simulation results expected:%v got:%v", len(txs), len(resp.Results))
+	}
+	result := &BribeSimulation{Txs: txs, Resp: resp}
+
+	last := resp.Results[len(resp.Results)-1]
+	if last.Error != "" {
+		return result, errors.Errorf("bribe tx failed error:%v revert:%v", last.Error, last.Revert)
+	}
+	if result.CoinbaseDiff, err = resp.CoinbaseDiffWei(); err != nil {
+		return result, err
+	}
+	if result.Paid, err = last.EthSentToCoinbaseWei(); err != nil {
+		return result, err
+	}
+	if result.Paid.Cmp(bribe.Amount) < 0 {
+		return result, errors.Errorf("bribe not paid to the coinbase expected:%v paid:%v", bribe.Amount, result.Paid)
+	}
+	return result, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type simulatorFunc func(ctx context.Context, txsHex []string, blockNumState uint64, opts *CallBundleOpts) (*Response, error)
+
+func (self simulatorFunc) CallBundle(ctx context.Context, txsHex []string, blockNumState uint64, opts *CallBundleOpts) (*Response, error) {
+	return self(ctx, txsHex, blockNumState, opts)
+}
+
+func TestCoinbaseBribeTx(t *testing.T) {
+	bribe := &CoinbaseBribe{ChainID: big.NewInt(1), Nonce: 3, Amount: big.NewInt(100), GasFeeCap: big.NewInt(10)}
+	tx, err := bribe.Tx()
+	testutil.Ok(t, err)
+	dynTx := tx.(*types.DynamicFeeTx)
+	testutil.Assert(t, dynTx.To == nil, "the transfer should be a contract creation")
+	testutil.Equals(t, coinbaseTransferCode, dynTx.Data)
+	testutil.Equals(t, uint64(coinbaseBribeGasDefault), dynTx.Gas)
+	testutil.Equals(t, uint64(3), dynTx.Nonce)
+	testutil.Equals(t, big.NewInt(0), dynTx.GasTipCap)
+
+	contract := common.HexToAddress("0x1")
+	bribe.Contract = &contract
+	bribe.Data = []byte{1}
+	bribe.Gas = 50000
+	tx, err = bribe.Tx()
+	testutil.Ok(t, err)
+	dynTx = tx.(*types.DynamicFeeTx)
+	testutil.Equals(t, &contract, dynTx.To)
+	testutil.Equals(t, []byte{1}, dynTx.Data)
+	testutil.Equals(t, uint64(50000), dynTx.Gas)
+
+	_, err = (&CoinbaseBribe{ChainID: big.NewInt(1), GasFeeCap: big.NewInt(10)}).Tx()
+	testutil.NotOk(t, err)
+	_, err = (&CoinbaseBribe{ChainID: big.NewInt(1), Amount: big.NewInt(1)}).Tx()
+	testutil.NotOk(t, err)
+	_, err = (&CoinbaseBribe{Amount: big.NewInt(1), GasFeeCap: big.NewInt(10)}).Tx()
+	testutil.NotOk(t, err)
+
+	_, err = NewBundle().AddCoinbaseBribe(&CoinbaseBribe{}, nil).TargetBlock(10).Sign(big.NewInt(1))
+	testutil.NotOk(t, err)
+}
+
+func TestSimulateCoinbaseBribe(t *testing.T) {
+	ctx := context.Background()
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	bribe := &CoinbaseBribe{ChainID: big.NewInt(1), Amount: big.NewInt(100), GasFeeCap: big.NewInt(10)}
+
+	var (
+		simulated []string
+		paid      string
+		txErr     string
+	)
+	sim := simulatorFunc(func(ctx context.Context, txsHex []string, blockNumState uint64, opts *CallBundleOpts) (*Response, error) {
+		simulated = txsHex
+		resp := &Response{}
+		resp.CoinbaseDiff = "150"
+		resp.Results = []TxResult{{Metadata: Metadata{EthSentToCoinbase: "0"}}, {Metadata: Metadata{EthSentToCoinbase: paid}, Error: txErr}}
+		return resp, nil
+	})
+
+	paid = "100"
+	res, err := SimulateCoinbaseBribe(ctx, sim, []string{"0x1"}, bribe, prvKey, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, simulated, res.Txs)
+	testutil.Equals(t, "0x1", res.Txs[0])
+	testutil.Equals(t, big.NewInt(150), res.CoinbaseDiff)
+	testutil.Equals(t, big.NewInt(100), res.Paid)
+
+	raw, err := hexutil.Decode(res.Txs[1])
+	testutil.Ok(t, err)
+	tx := &types.Transaction{}
+	testutil.Ok(t, tx.UnmarshalBinary(raw))
+	testutil.Equals(t, big.NewInt(100), tx.Value())
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), tx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, crypto.PubkeyToAddress(prvKey.PublicKey), sender)
+
+	paid = "50"
+	res, err = SimulateCoinbaseBribe(ctx, sim, []string{"0x1"}, bribe, prvKey, 0)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, big.NewInt(50), res.Paid)
+
+	paid, txErr = "0", "execution reverted"
+	_, err = SimulateCoinbaseBribe(ctx, sim, []string{"0x1"}, bribe, prvKey, 0)
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const acceptEncoding = "gzip, deflate"
+
+func gzipBody(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(payload); err != nil {
+		return nil, errors.Wrap(err, "compressing request body")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "compressing request body")
+	}
+	return buf.Bytes(), nil
+}
+
+// readBody reads the response body and decompresses it
+// according to its Content-Encoding.
+func readBody(resp *http.Response) ([]byte, error) {
+	var body io.Reader = resp.Body
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "decompressing gzip response")
+		}
+		defer r.Close()
+		body = r
+	case "deflate":
+		// HTTP deflate is zlib wrapped but some servers send raw deflate
+		// so that is used when the zlib header doesn't match.
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		r, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			if !errors.Is(err, zlib.ErrHeader) {
+				return nil, errors.Wrap(err, "decompressing deflate response")
+			}
+			r = flate.NewReader(bytes.NewReader(raw))
+		}
+		defer r.Close()
+		body = r
+	default:
+		return nil, errors.Errorf("unsupported response encoding:%v", resp.Header.Get("Content-Encoding"))
+	}
+	return io.ReadAll(body)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCompression(t *testing.T) {
+	const resp = `{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`
+
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate", ""} {
+		encoding := encoding
+		t.Run(encoding, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				testutil.Equals(t, "gzip", r.Header.Get("Content-Encoding"))
+				testutil.Equals(t, acceptEncoding, r.Header.Get("Accept-Encoding"))
+				body, err := gzip.NewReader(r.Body)
+				testutil.Ok(t, err)
+				var msg jsonrpcMessage
+				testutil.Ok(t, json.NewDecoder(body).Decode(&msg))
+				testutil.Equals(t, MethodSendBundle, msg.Method)
+
+				var out io.Writer = w
+				switch encoding {
+				case "gzip":
+					w.Header().Set("Content-Encoding", "gzip")
+					gw := gzip.NewWriter(w)
+					defer gw.Close()
+					out = gw
+				case "deflate":
+					w.Header().Set("Content-Encoding", "deflate")
+					zw := zlib.NewWriter(w)
+					defer zw.Close()
+					out = zw
+				case "raw-deflate":
+					// Some servers send raw deflate without the zlib header.
+					w.Header().Set("Content-Encoding", "deflate")
+					fw, err := flate.NewWriter(w, flate.DefaultCompression)
+					testutil.Ok(t, err)
+					defer fw.Close()
+					out = fw
+				}
+				_, err = out.Write([]byte(resp))
+				testutil.Ok(t, err)
+			}))
+			defer srv.Close()
+
+			privKey, err := crypto.GenerateKey()
+			testutil.Ok(t, err)
+			flashbot, err := New(privKey, &Api{URL: srv.URL, CompressRequests: true})
+			testutil.Ok(t, err)
+
+			res, err := flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+			testutil.Ok(t, err)
+			testutil.Equals(t, "0x1", res.BundleHash)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type correlationIDKey struct{}
+
+// WithCorrelationID attaches an id to the context so that every relay request
+// made with it, including retries and fan-outs to multiple relays,
+// can be traced back to the same logical submission.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the id attached to the context and
+// false when the context doesn't carry one.
+func CorrelationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// NewCorrelationID generates a random id suitable for WithCorrelationID.
+func NewCorrelationID() string {
+	return uuid.NewString()
+}
+
+// ensureCorrelationID returns a context that always carries a correlation id,
+// generating a new one when the caller didn't provide it.
+func ensureCorrelationID(ctx context.Context) (context.Context, string) {
+	if id, ok := CorrelationID(ctx); ok {
+		return ctx, id
+	}
+	id := NewCorrelationID()
+	return WithCorrelationID(ctx, id), id
+}
+
+// RequestError is returned when a request to a relay fails
+// and carries enough context to correlate it with other requests
+// from the same logical submission.
+type RequestError struct {
+	CorrelationID string
+	Relay         string
+	Method        string
+	Err           error
+}
+
+func (self *RequestError) Error() string {
+	return fmt.Sprintf("correlationID:%v relay:%v method:%v: %v", self.CorrelationID, self.Relay, self.Method, self.Err)
+}
+
+func (self *RequestError) Unwrap() error {
+	return self.Err
+}
+
+func (self *RequestError) Cause() error {
+	return self.Err
+}
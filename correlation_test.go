@@ -0,0 +1,39 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestCorrelationIDInErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	id := NewCorrelationID()
+	_, err = flashbot.CancelPrivateTransaction(WithCorrelationID(context.Background(), id), common.HexToHash("0"))
+	testutil.NotOk(t, err)
+
+	var reqErr *RequestError
+	testutil.Assert(t, errors.As(err, &reqErr), "error should be a RequestError")
+	testutil.Equals(t, id, reqErr.CorrelationID)
+	testutil.Equals(t, "eth_cancelPrivateTransaction", reqErr.Method)
+	testutil.Equals(t, srv.URL, reqErr.Relay)
+}
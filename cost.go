@@ -0,0 +1,107 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// TxCost is the cost of a single bundle transaction.
+type TxCost struct {
+	Hash   common.Hash
+	Sender common.Address
+	// Tip is the effective priority fee per gas at the base fee.
+	Tip *big.Int
+	// Worst is the cost when the transaction uses its whole gas limit
+	// and Expected when it uses the expected gas.
+	WorstBurn            *big.Int
+	WorstMinerPayment    *big.Int
+	ExpectedBurn         *big.Int
+	ExpectedMinerPayment *big.Int
+}
+
+// CostEstimate is the cost of a bundle at a projected base fee.
+// Miner payments include only the priority fees as
+// direct transfers to the coinbase are known only after a simulation.
+type CostEstimate struct {
+	BaseFee              *big.Int
+	Txs                  []TxCost
+	WorstBurn            *big.Int
+	WorstMinerPayment    *big.Int
+	ExpectedBurn         *big.Int
+	ExpectedMinerPayment *big.Int
+	// RequiredBalance is the balance each sender needs for its transactions to be valid,
+	// the value plus the gas limit at the fee cap, without funds received within the bundle.
+	RequiredBalance map[common.Address]*big.Int
+}
+
+// EstimateCost calculates the fees of the bundle so that
+// the affordability can be checked before simulating it.
+// gasUsed is the expected gas of each transaction, for example from EstimateGasBundle,
+// nil expects the transactions to use their whole gas limit.
+// An error is returned when a fee cap is below the base fee as the bundle can't be included.
+func EstimateCost(txsHex []string, baseFee *big.Int, gasUsed []uint64) (*CostEstimate, error) {
+	if gasUsed != nil && len(gasUsed) != len(txsHex) {
+		return nil, errors.Errorf("gas used count:%v doesn't match txs count:%v", len(gasUsed), len(txsHex))
+	}
+
+	est := &CostEstimate{
+		BaseFee:              new(big.Int).Set(baseFee),
+		WorstBurn:            new(big.Int),
+		WorstMinerPayment:    new(big.Int),
+		ExpectedBurn:         new(big.Int),
+		ExpectedMinerPayment: new(big.Int),
+		RequiredBalance:      make(map[common.Address]*big.Int),
+	}
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		sender, err := txSender(tx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		if tx.GasFeeCap().Cmp(baseFee) < 0 {
+			return nil, errors.Errorf("tx:%v fee cap:%v below the base fee:%v", i, tx.GasFeeCap(), baseFee)
+		}
+
+		tip := new(big.Int).Sub(tx.GasFeeCap(), baseFee)
+		if tip.Cmp(tx.GasTipCap()) > 0 {
+			tip.Set(tx.GasTipCap())
+		}
+		gasLimit := new(big.Int).SetUint64(tx.Gas())
+		gas := gasLimit
+		if gasUsed != nil {
+			gas = new(big.Int).SetUint64(gasUsed[i])
+		}
+
+		cost := TxCost{
+			Hash:                 tx.Hash(),
+			Sender:               sender,
+			Tip:                  tip,
+			WorstBurn:            new(big.Int).Mul(baseFee, gasLimit),
+			WorstMinerPayment:    new(big.Int).Mul(tip, gasLimit),
+			ExpectedBurn:         new(big.Int).Mul(baseFee, gas),
+			ExpectedMinerPayment: new(big.Int).Mul(tip, gas),
+		}
+		est.Txs = append(est.Txs, cost)
+		est.WorstBurn.Add(est.WorstBurn, cost.WorstBurn)
+		est.WorstMinerPayment.Add(est.WorstMinerPayment, cost.WorstMinerPayment)
+		est.ExpectedBurn.Add(est.ExpectedBurn, cost.ExpectedBurn)
+		est.ExpectedMinerPayment.Add(est.ExpectedMinerPayment, cost.ExpectedMinerPayment)
+
+		balance, ok := est.RequiredBalance[sender]
+		if !ok {
+			balance = new(big.Int)
+			est.RequiredBalance[sender] = balance
+		}
+		balance.Add(balance, tx.Value())
+		balance.Add(balance, new(big.Int).Mul(tx.GasFeeCap(), gasLimit))
+	}
+	return est, nil
+}
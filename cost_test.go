@@ -0,0 +1,59 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEstimateCost(t *testing.T) {
+	chainID := big.NewInt(1)
+	bot, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	user, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	to := common.HexToAddress("0x1")
+
+	botTx1, err := SignTx(chainID, &types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 0, To: &to, Value: big.NewInt(1000), Gas: 100_000,
+		GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(20),
+	}, bot)
+	testutil.Ok(t, err)
+	// The tip is capped by the fee cap minus the base fee.
+	botTx2, err := SignTx(chainID, &types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 1, To: &to, Gas: 50_000,
+		GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(11),
+	}, bot)
+	testutil.Ok(t, err)
+	userTx, err := SignTx(chainID, &types.LegacyTx{Nonce: 3, To: &to, Gas: 21_000, GasPrice: big.NewInt(13)}, user)
+	testutil.Ok(t, err)
+	txs := []string{botTx1, botTx2, userTx}
+
+	est, err := EstimateCost(txs, big.NewInt(10), []uint64{60_000, 50_000, 21_000})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []*big.Int{big.NewInt(2), big.NewInt(1), big.NewInt(3)}, []*big.Int{est.Txs[0].Tip, est.Txs[1].Tip, est.Txs[2].Tip})
+	testutil.Equals(t, big.NewInt(10*171_000), est.WorstBurn)
+	testutil.Equals(t, big.NewInt(2*100_000+50_000+3*21_000), est.WorstMinerPayment)
+	testutil.Equals(t, big.NewInt(10*131_000), est.ExpectedBurn)
+	testutil.Equals(t, big.NewInt(2*60_000+50_000+3*21_000), est.ExpectedMinerPayment)
+	testutil.Equals(t, big.NewInt(1000+20*100_000+11*50_000), est.RequiredBalance[crypto.PubkeyToAddress(bot.PublicKey)])
+	testutil.Equals(t, big.NewInt(13*21_000), est.RequiredBalance[crypto.PubkeyToAddress(user.PublicKey)])
+
+	// Without the gas used the expected cost is the worst case.
+	est, err = EstimateCost(txs, big.NewInt(10), nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, est.WorstBurn, est.ExpectedBurn)
+	testutil.Equals(t, est.WorstMinerPayment, est.ExpectedMinerPayment)
+
+	_, err = EstimateCost(txs, big.NewInt(12), nil)
+	testutil.NotOk(t, err)
+	_, err = EstimateCost(txs, big.NewInt(10), []uint64{1})
+	testutil.NotOk(t, err)
+}
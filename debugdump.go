@@ -0,0 +1,85 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+)
+
+// DebugDumpConfig configures the dumps of the raw relay requests and responses.
+// The signature, authorization and custom headers and the raw transactions
+// are redacted unless Unsafe is set.
+type DebugDumpConfig struct {
+	// Dir writes every dump to its own file in the directory,
+	// when empty the dumps are logged at the debug level.
+	Dir string
+	// All dumps the successful requests as well, by default only failed ones are dumped.
+	All bool
+	// Unsafe keeps the credentials and the raw transactions in the dumps
+	// which then shouldn't leave the machine.
+	Unsafe bool
+}
+
+// WithDebugDumps dumps the relay requests and responses
+// for troubleshooting relays that reject requests.
+// The dumps are written to the logger or to files and never added to the returned errors.
+func WithDebugDumps(cfg DebugDumpConfig) Option {
+	return func(f *Flashbot) {
+		f.debugDump = &cfg
+	}
+}
+
+// dumpRequest is called before sending the request as the body can't be read after it.
+func (self *Flashbot) dumpRequest(req *http.Request) []byte {
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return []byte(fmt.Sprintf("dumping request:%v", err))
+	}
+	return dump
+}
+
+// dumpResponse writes the dumps of the request and of the response or of the request error.
+func (self *Flashbot) dumpResponse(ctx context.Context, method string, reqDump []byte, resp *http.Response, reqErr error) {
+	failed := reqErr != nil || resp.StatusCode/100 != 2
+	if !failed && !self.debugDump.All {
+		return
+	}
+
+	var respDump []byte
+	if resp != nil {
+		// The dump restores the body so it can still be read.
+		var err error
+		if respDump, err = httputil.DumpResponse(resp, true); err != nil {
+			respDump = []byte(fmt.Sprintf("dumping response:%v", err))
+		}
+	} else {
+		respDump = []byte(fmt.Sprintf("request failed:%v", reqErr))
+	}
+
+	if !self.debugDump.Unsafe {
+		reqDump = self.redactDump(reqDump)
+		respDump = self.redactDump(respDump)
+	}
+
+	correlationID, _ := CorrelationID(ctx)
+	if self.debugDump.Dir == "" {
+		level.Debug(self.logger).Log("msg", "relay dump", "correlationID", correlationID, "relay", self.Api().URL, "method", method, "request", string(reqDump), "response", string(respDump))
+		return
+	}
+
+	name := fmt.Sprintf("%v-%v-%v.txt", time.Now().UnixNano(), correlationID, strings.ReplaceAll(method, "/", "_"))
+	content := fmt.Sprintf("relay:%v\n\n%s\n\n%s\n", self.Api().URL, reqDump, respDump)
+	if err := os.WriteFile(filepath.Join(self.debugDump.Dir, name), []byte(content), 0o600); err != nil {
+		level.Warn(self.logger).Log("msg", "writing relay dump", "correlationID", correlationID, "relay", self.Api().URL, "err", err)
+	}
+}
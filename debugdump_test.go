@@ -0,0 +1,66 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/log"
+)
+
+func TestDebugDumps(t *testing.T) {
+	status := http.StatusTooManyRequests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	ctx := WithCorrelationID(context.Background(), "corr")
+
+	// Dumps to files.
+	dir := t.TempDir()
+	flashbot, err := New(privKey, &Api{URL: srv.URL}, WithDebugDumps(DebugDumpConfig{Dir: dir}))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, !strings.Contains(err.Error(), "X-Flashbots-Signature"), "error shouldn't include the dump:%v", err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-corr-"+MethodSendBundle+".txt"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(files))
+	dump, err := os.ReadFile(files[0])
+	testutil.Ok(t, err)
+	testutil.Assert(t, bytes.Contains(dump, []byte("X-Flashbots-Signature: [redacted]")), "dump should include the redacted signature:%s", dump)
+	testutil.Assert(t, bytes.Contains(dump, []byte(`"method":"eth_sendBundle"`)), "dump should include the request body:%s", dump)
+	testutil.Assert(t, bytes.Contains(dump, []byte("429 Too Many Requests")), "dump should include the response:%s", dump)
+
+	// Successful requests are dumped only with All.
+	status = http.StatusOK
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	files, err = filepath.Glob(filepath.Join(dir, "*"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(files))
+
+	// Dumps to the logger.
+	var logs bytes.Buffer
+	flashbot, err = New(privKey, &Api{URL: srv.URL}, WithLogger(log.NewLogfmtLogger(&logs)), WithDebugDumps(DebugDumpConfig{All: true}))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(logs.String(), `msg="relay dump" correlationID=corr`), "dump should be logged:%v", logs.String())
+	testutil.Assert(t, strings.Contains(logs.String(), "200 OK"), "dump should include the response:%v", logs.String())
+}
@@ -0,0 +1,100 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// DecodeMode controls how responses with fields unknown to this package are handled.
+type DecodeMode int
+
+const (
+	// DecodeLenient silently ignores unknown fields.
+	DecodeLenient DecodeMode = iota
+	// DecodeWarn logs a warning for unknown fields and decodes the rest of the response.
+	DecodeWarn
+	// DecodeStrict fails the request when the result has unknown fields.
+	DecodeStrict
+)
+
+// WithDecodeMode enables detecting fields in the relay responses
+// which aren't part of the response types so that
+// changes in the relay api don't go unnoticed.
+// Only the first unknown field of a response is reported.
+func WithDecodeMode(mode DecodeMode) Option {
+	return func(f *Flashbot) {
+		f.decodeMode = mode
+	}
+}
+
+func (self *Flashbot) unmarshal(data []byte, v interface{}) error {
+	if self.decodeMode != DecodeLenient {
+		if err := unknownFields(data, v); err != nil {
+			if self.decodeMode == DecodeStrict {
+				return self.decodeError(errors.Wrap(err, "strict decoding"))
+			}
+			level.Warn(self.logger).Log("msg", "relay response has unknown fields", "err", err)
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return self.decodeError(err)
+	}
+	return nil
+}
+
+// DecodeError is returned when the relay response can't be decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (self *DecodeError) Error() string {
+	return self.Err.Error()
+}
+
+func (self *DecodeError) Unwrap() error {
+	return self.Err
+}
+
+func (self *DecodeError) Is(target error) bool {
+	return target == ErrDecode
+}
+
+func (self *Flashbot) decodeError(err error) error {
+	decodeErr := &DecodeError{Err: err}
+	self.stats.recordError(decodeErr)
+	return decodeErr
+}
+
+// unknownFields checks the result of the response against the Result field of v.
+// The jsonrpc envelope isn't checked as the response types don't include the id and version.
+func unknownFields(data []byte, v interface{}) error {
+	msg := &jsonrpcMessage{}
+	if err := json.Unmarshal(data, msg); err != nil || len(msg.Result) == 0 {
+		return nil // Decoding errors are reported by the regular decoding.
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	field := rv.Elem().FieldByName("Result")
+	if !field.IsValid() {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(msg.Result))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(reflect.New(field.Type()).Interface())
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field") {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log"
+)
+
+func TestDecodeMode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1","smart":true}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	api := &Api{URL: srv.URL, SkipFlashbotsSignature: true}
+
+	flashbot, err := New(nil, api)
+	testutil.Ok(t, err)
+	resp, err := flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.BundleHash)
+
+	logs := &bytes.Buffer{}
+	flashbot, err = New(nil, api, WithDecodeMode(DecodeWarn), WithLogger(log.NewLogfmtLogger(logs)))
+	testutil.Ok(t, err)
+	resp, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.BundleHash)
+	testutil.Assert(t, strings.Contains(logs.String(), `unknown field \"smart\"`), "missing warning:%v", logs.String())
+
+	flashbot, err = New(nil, api, WithDecodeMode(DecodeStrict))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "strict decoding"), "unexpected error:%v", err)
+}
+
+func TestUnknownFields(t *testing.T) {
+	// The jsonrpc envelope and known fields of nested types are accepted.
+	testutil.Ok(t, unknownFields([]byte(`{"jsonrpc":"2.0","id":1,"result":{"results":[{"txHash":"0x1","gasUsed":21000}]}}`), &Response{}))
+	testutil.Ok(t, unknownFields([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"err"}}`), &Response{}))
+
+	err := unknownFields([]byte(`{"jsonrpc":"2.0","id":1,"result":{"results":[{"txHash":"0x1","toAddress":"0x2"}]}}`), &Response{})
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "toAddress"), "unexpected error:%v", err)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// The environment variables read by FromEnv.
+const (
+	// EnvNetworkID selects the relays created by NewAll for the network
+	// when neither EnvRelays nor EnvRelaysFile is set.
+	EnvNetworkID = "FLASHBOT_NETWORK_ID"
+	// EnvRelays is a comma separated list of relay urls.
+	EnvRelays = "FLASHBOT_RELAYS"
+	// EnvRelaysFile is a JSON or YAML file read with LoadApis.
+	EnvRelaysFile = "FLASHBOT_RELAYS_FILE"
+	// EnvSigningKey is the hex encoded private key which signs the relay requests.
+	EnvSigningKey = "FLASHBOT_SIGNING_KEY"
+	// EnvSigningKeyFile is a go-ethereum keystore file used instead of EnvSigningKey.
+	EnvSigningKeyFile = "FLASHBOT_SIGNING_KEYFILE"
+	// EnvSigningKeyPassphrase decrypts EnvSigningKeyFile.
+	EnvSigningKeyPassphrase = "FLASHBOT_SIGNING_KEYFILE_PASSPHRASE"
+	// EnvTimeout is the Api.Timeout of the relays which don't set one, for example 2s.
+	EnvTimeout = "FLASHBOT_TIMEOUT"
+)
+
+// FromEnv creates the relays configured with the FLASHBOT_ environment variables
+// for deployments which are configured through the environment.
+// The options are applied to all relays.
+func FromEnv(opts ...Option) (*Multi, error) {
+	return FromEnvFunc(os.Getenv, opts...)
+}
+
+// FromEnvFunc is FromEnv with the variables read by getenv,
+// for example to let command line flags override the environment.
+func FromEnvFunc(getenv func(string) string, opts ...Option) (*Multi, error) {
+	signer, err := signerFromEnv(getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	apis, err := apisFromEnv(getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	if v := getenv(EnvTimeout); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %v", EnvTimeout)
+		}
+		for _, api := range apis {
+			if api.Timeout == 0 {
+				api.Timeout = timeout
+			}
+		}
+	}
+
+	flashbots := make([]Flashboter, 0, len(apis))
+	for _, api := range apis {
+		f, err := NewWithSigner(signer, api, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "create flashbot instance:%v", api.URL)
+		}
+		flashbots = append(flashbots, f)
+	}
+	return NewMultiRelay(flashbots...), nil
+}
+
+func signerFromEnv(getenv func(string) string) (Signer, error) {
+	if path := getenv(EnvSigningKeyFile); path != "" {
+		return LoadKeystoreSigner(path, getenv(EnvSigningKeyPassphrase))
+	}
+	key := getenv(EnvSigningKey)
+	if key == "" {
+		return nil, errors.Errorf("%v or %v is required", EnvSigningKey, EnvSigningKeyFile)
+	}
+	prvKey, err := crypto.HexToECDSA(strings.TrimPrefix(key, "0x"))
+	if err != nil {
+		// The error of HexToECDSA doesn't include the key.
+		return nil, errors.Wrapf(err, "parsing %v", EnvSigningKey)
+	}
+	return NewECDSASigner(prvKey)
+}
+
+func apisFromEnv(getenv func(string) string) ([]*Api, error) {
+	if path := getenv(EnvRelaysFile); path != "" {
+		return LoadApis(path)
+	}
+
+	var netID int64
+	if v := getenv(EnvNetworkID); v != "" {
+		var err error
+		if netID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, errors.Wrapf(err, "parsing %v", EnvNetworkID)
+		}
+	}
+
+	relays := getenv(EnvRelays)
+	if relays == "" {
+		if netID == 0 {
+			return nil, errors.Errorf("%v, %v or %v is required", EnvRelays, EnvRelaysFile, EnvNetworkID)
+		}
+		return catalogApis(netID, NewAllOpts{})
+	}
+
+	var apis []*Api
+	for _, url := range strings.Split(relays, ",") {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+		apis = append(apis, apiForURL(netID, url))
+	}
+	return apis, nil
+}
+
+// apiForURL uses the capabilities of a known relay
+// when the url is the default relay of the network or in the catalog.
+func apiForURL(netID int64, url string) *Api {
+	for _, n := range Networks() {
+		if n.RelayURL == url && (netID == 0 || n.ID == netID) {
+			return n.Api()
+		}
+	}
+	for _, b := range DefaultCatalog().Presets(netID, true) {
+		if b.URL == url {
+			return b.Api()
+		}
+	}
+	return &Api{URL: url}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+func TestFromEnv(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	addr := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	_, err = FromEnv()
+	testutil.NotOk(t, err)
+
+	t.Setenv(EnvSigningKey, hexutil.Encode(crypto.FromECDSA(privKey)))
+	_, err = FromEnv()
+	testutil.NotOk(t, err)
+
+	// The network defaults.
+	t.Setenv(EnvNetworkID, "1")
+	multi, err := FromEnv()
+	testutil.Ok(t, err)
+	flashbots := multi.Flashbots()
+	testutil.Equals(t, 4, len(flashbots))
+	testutil.Equals(t, "https://relay.flashbots.net", flashbots[0].Api().URL)
+	testutil.Equals(t, addr, flashbots[0].(*Flashbot).Signer().Address())
+
+	// Known relays keep their capabilities.
+	t.Setenv(EnvRelays, "https://relay.flashbots.net, http://localhost:8080")
+	t.Setenv(EnvTimeout, "3s")
+	multi, err = FromEnv()
+	testutil.Ok(t, err)
+	flashbots = multi.Flashbots()
+	testutil.Equals(t, 2, len(flashbots))
+	testutil.Assert(t, flashbots[0].Api().SupportsSimulation, "flashbots relay should support simulations")
+	testutil.Equals(t, &Api{URL: "http://localhost:8080", Timeout: 3 * time.Second}, flashbots[1].Api())
+
+	// The relays file and the keystore file.
+	dir := t.TempDir()
+	relaysPath := filepath.Join(dir, "relays.yaml")
+	testutil.Ok(t, os.WriteFile(relaysPath, []byte("relays:\n  - url: http://localhost:8080\n    timeout: 1s\n"), 0o600))
+	t.Setenv(EnvRelaysFile, relaysPath)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Id: uuid.New(), Address: addr, PrivateKey: privKey}, "pass", keystore.LightScryptN, keystore.LightScryptP)
+	testutil.Ok(t, err)
+	keyPath := filepath.Join(dir, "key.json")
+	testutil.Ok(t, os.WriteFile(keyPath, keyJSON, 0o600))
+	t.Setenv(EnvSigningKey, "")
+	t.Setenv(EnvSigningKeyFile, keyPath)
+	t.Setenv(EnvSigningKeyPassphrase, "pass")
+	multi, err = FromEnv()
+	testutil.Ok(t, err)
+	flashbots = multi.Flashbots()
+	testutil.Equals(t, 1, len(flashbots))
+	testutil.Equals(t, time.Second, flashbots[0].Api().Timeout)
+	testutil.Equals(t, addr, flashbots[0].(*Flashbot).Signer().Address())
+
+	t.Setenv(EnvSigningKeyPassphrase, "wrong")
+	_, err = FromEnv()
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,90 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrorClass is the category of a failure so that
+// a relay being unavailable can be told apart from a bundle which reverts.
+type ErrorClass string
+
+const (
+	// ErrorClassNetwork are connection failures and timeouts.
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassTLS are handshake and certificate failures.
+	ErrorClassTLS ErrorClass = "tls"
+	// ErrorClassRateLimit are requests rejected because of the relay rate limits.
+	ErrorClassRateLimit ErrorClass = "rate_limit"
+	// ErrorClassRelay are the error responses of the relay, both http statuses and json-rpc errors.
+	ErrorClassRelay ErrorClass = "relay_error"
+	// ErrorClassSimulationRevert are bundles with transactions which failed in the simulation.
+	ErrorClassSimulationRevert ErrorClass = "simulation_revert"
+	// ErrorClassDecode are responses which can't be decoded.
+	ErrorClassDecode ErrorClass = "decode"
+	// ErrorClassOther are all other failures.
+	ErrorClassOther ErrorClass = "other"
+)
+
+// ErrorClasses are all classes returned by ClassifyError.
+var ErrorClasses = []ErrorClass{
+	ErrorClassNetwork,
+	ErrorClassTLS,
+	ErrorClassRateLimit,
+	ErrorClassRelay,
+	ErrorClassSimulationRevert,
+	ErrorClassDecode,
+	ErrorClassOther,
+}
+
+// ClassifyError returns the class of the error and an empty class for a nil error.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+	var (
+		httpErr *HTTPError
+		rpcErr  *RPCError
+		netErr  net.Error
+	)
+	switch {
+	case errors.Is(err, ErrBundleReverted):
+		return ErrorClassSimulationRevert
+	case errors.Is(err, ErrRateLimited):
+		return ErrorClassRateLimit
+	case errors.Is(err, ErrDecode):
+		return ErrorClassDecode
+	case errors.As(err, &httpErr), errors.As(err, &rpcErr):
+		return ErrorClassRelay
+	case isTLSError(err):
+		return ErrorClassTLS
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassNetwork
+	}
+	return ErrorClassOther
+}
+
+func isTLSError(err error) bool {
+	var (
+		unknownAuthErr x509.UnknownAuthorityError
+		certErr        x509.CertificateInvalidError
+		hostErr        x509.HostnameError
+		recordErr      tls.RecordHeaderError
+	)
+	if errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &certErr) ||
+		errors.As(err, &hostErr) ||
+		errors.As(err, &recordErr) {
+		return true
+	}
+	// Handshake alerts aren't exported.
+	return strings.Contains(err.Error(), "tls: ")
+}
@@ -0,0 +1,90 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, tc := range []struct {
+		err   error
+		class ErrorClass
+	}{
+		{nil, ""},
+		{errors.New("unexpected"), ErrorClassOther},
+		{errors.Wrap(context.DeadlineExceeded, "request"), ErrorClassNetwork},
+		{&HTTPError{StatusCode: http.StatusTooManyRequests}, ErrorClassRateLimit},
+		{&RPCError{Code: -32000, Message: "rate limit exceeded"}, ErrorClassRateLimit},
+		{&HTTPError{StatusCode: http.StatusBadGateway}, ErrorClassRelay},
+		{errors.Wrap(&RPCError{Code: -32000, Message: "invalid bundle"}, "request"), ErrorClassRelay},
+		{&RevertError{Failed: []int{0}, Results: []TxResult{{Error: "execution reverted"}}}, ErrorClassSimulationRevert},
+		{&DecodeError{Err: errors.New("invalid character")}, ErrorClassDecode},
+	} {
+		testutil.Equals(t, tc.class, ClassifyError(tc.err), "err:%v", tc.err)
+	}
+}
+
+func TestErrorCounters(t *testing.T) {
+	var resp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch resp {
+		case "429":
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "500":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, err := w.Write([]byte(resp))
+			testutil.Ok(t, err)
+		}
+	}))
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true, SupportsSimulation: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+	txs := []string{signedTxHex(t)}
+
+	for _, resp = range []string{
+		"429",
+		"500",
+		`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"invalid bundle"}}`,
+		`{"jsonrpc":"2.0","id":1,"result":`,
+	} {
+		_, err = flashbot.SendBundle(ctx, txs, 11, nil)
+		testutil.NotOk(t, err)
+	}
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"results":[{"txHash":"0x1","error":"execution reverted"}]}}`
+	_, err = flashbot.CallBundle(ctx, txs, 10, nil)
+	testutil.Ok(t, err)
+
+	srv.Close()
+	_, err = flashbot.SendBundle(ctx, txs, 11, nil)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, ErrorClassNetwork, ClassifyError(err))
+
+	testutil.Equals(t, map[ErrorClass]uint64{
+		ErrorClassRateLimit:        1,
+		ErrorClassRelay:            2,
+		ErrorClassDecode:           1,
+		ErrorClassSimulationRevert: 1,
+		ErrorClassNetwork:          1,
+	}, flashbot.Stats().Errors)
+}
+
+func TestErrorCountersTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{signedTxHex(t)}, 11, nil)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, ErrorClassTLS, ClassifyError(err))
+	testutil.Equals(t, map[ErrorClass]uint64{ErrorClassTLS: 1}, flashbot.Stats().Errors)
+}
@@ -0,0 +1,131 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Errors to check with errors.Is to decide whether to retry, re-sign or abort.
+var (
+	// ErrUnknownMethod is returned when the relay doesn't implement the method.
+	ErrUnknownMethod = errors.New("unknown method")
+	// ErrRateLimited is returned when the relay rejects the request because of its rate limits.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrBundleReverted is returned by Response.RevertErr when a bundle transaction
+	// fails in the simulation, use errors.As with *RevertError for the results.
+	ErrBundleReverted = errors.New("bundle reverted")
+	// ErrNonceTooLow is returned when a transaction was already included
+	// or its nonce was used by another transaction.
+	ErrNonceTooLow = errors.New("nonce too low")
+	// ErrStaleBlock is returned when the target block is already mined.
+	ErrStaleBlock = errors.New("stale block")
+	// ErrDecode is returned when the relay response can't be decoded,
+	// use errors.As with *DecodeError for the cause.
+	ErrDecode = errors.New("decoding relay response")
+)
+
+// RPCError is the JSON-RPC error returned by the relay.
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func newRPCError(e Error) *RPCError {
+	return &RPCError{Code: e.Code, Message: e.Message}
+}
+
+// rpcError counts the error returned in a successful response.
+func (self *Flashbot) rpcError(e Error) *RPCError {
+	rpcErr := newRPCError(e)
+	self.stats.recordError(rpcErr)
+	return rpcErr
+}
+
+func (self *RPCError) Error() string {
+	return fmt.Sprintf("code:%v message:%v", self.Code, self.Message)
+}
+
+// Is classifies the error by its code and message
+// as these differ between the relays.
+func (self *RPCError) Is(target error) bool {
+	msg := strings.ToLower(self.Message)
+	switch target {
+	case ErrUnknownMethod:
+		return self.Code == -32601 ||
+			strings.Contains(msg, "method not found") ||
+			strings.Contains(msg, "unknown method") ||
+			strings.Contains(msg, "does not exist/is not available")
+	case ErrRateLimited:
+		return self.Code == 429 ||
+			self.Code == -32005 ||
+			strings.Contains(msg, "rate limit") ||
+			strings.Contains(msg, "too many requests")
+	case ErrNonceTooLow:
+		return strings.Contains(msg, "nonce too low")
+	case ErrStaleBlock:
+		return strings.Contains(msg, "in the past") ||
+			strings.Contains(msg, "must be in the future") ||
+			strings.Contains(msg, "block number too low") ||
+			strings.Contains(msg, "stale")
+	}
+	return false
+}
+
+// RevertError describes the bundle transactions which failed in the simulation.
+type RevertError struct {
+	// Failed are the indexes of the failed transactions in Results.
+	Failed []int
+	// Results of all bundle transactions including the successful ones.
+	Results []TxResult
+}
+
+func (self *RevertError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "failed txs:%v/%v", len(self.Failed), len(self.Results))
+	for _, i := range self.Failed {
+		r := self.Results[i]
+		fmt.Fprintf(&b, " [tx:%v hash:%v error:%v revert:%v gasUsed:%v]", i, r.TxHash, r.Error, r.Revert, r.GasUsed)
+	}
+	return b.String()
+}
+
+func (self *RevertError) Is(target error) bool {
+	switch target {
+	case ErrBundleReverted:
+		return true
+	case ErrNonceTooLow:
+		for _, i := range self.Failed {
+			if strings.Contains(strings.ToLower(self.Results[i].Error), "nonce too low") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Reverted reports whether any bundle transaction failed in the simulation.
+// The simulation results are returned as is so
+// the Error, Revert and GasUsed of every transaction can be inspected.
+func (self *Response) Reverted() bool {
+	return self.RevertErr() != nil
+}
+
+// RevertErr returns a *RevertError when a bundle transaction failed in the simulation
+// and nil otherwise.
+func (self *Response) RevertErr() error {
+	var failed []int
+	for i, r := range self.Results {
+		if r.Error != "" {
+			failed = append(failed, i)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &RevertError{Failed: failed, Results: self.Results}
+}
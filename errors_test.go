@@ -0,0 +1,130 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestTypedErrors(t *testing.T) {
+	type testcase struct {
+		name   string
+		status int
+		resp   string
+		exp    []error
+	}
+
+	tests := []testcase{
+		{
+			name: "unknown method",
+			resp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"the method eth_sendBundle does not exist/is not available"}}`,
+			exp:  []error{ErrUnknownMethod},
+		},
+		{
+			name: "rate limited by rpc error",
+			resp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"rate limit exceeded"}}`,
+			exp:  []error{ErrRateLimited},
+		},
+		{
+			name:   "rate limited by status",
+			status: http.StatusTooManyRequests,
+			exp:    []error{ErrRateLimited},
+		},
+		{
+			name: "nonce too low",
+			resp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low: address 0x1, tx: 1 state: 2"}}`,
+			exp:  []error{ErrNonceTooLow},
+		},
+		{
+			name: "stale block",
+			resp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"block number must be in the future"}}`,
+			exp:  []error{ErrStaleBlock},
+		},
+	}
+
+	all := []error{ErrUnknownMethod, ErrRateLimited, ErrNonceTooLow, ErrStaleBlock, ErrBundleReverted}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.status != 0 {
+					w.WriteHeader(tc.status)
+				}
+				_, err := w.Write([]byte(tc.resp))
+				testutil.Ok(t, err)
+			}))
+			defer srv.Close()
+
+			flashbot, err := New(nil, &Api{URL: srv.URL, SupportsSimulation: true, SkipFlashbotsSignature: true})
+			testutil.Ok(t, err)
+
+			_, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 10, nil)
+			testutil.NotOk(t, err)
+			for _, e := range all {
+				exp := false
+				for _, ee := range tc.exp {
+					exp = exp || e == ee
+				}
+				testutil.Assert(t, errors.Is(err, e) == exp, "error:%v matching:%v expected:%v", err, e, exp)
+			}
+		})
+	}
+}
+
+func TestRevertError(t *testing.T) {
+	var resp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{URL: srv.URL, SupportsSimulation: true, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	// Reverts are returned with the full results instead of an error.
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"results":[
+		{"txHash":"0x1","gasUsed":50000},
+		{"txHash":"0x2","error":"execution reverted","revert":"too little received","gasUsed":21000},
+		{"txHash":"0x3","gasUsed":30000},
+		{"txHash":"0x4","error":"execution reverted","revert":"expired","gasUsed":22000}
+	]}}`
+	res, err := flashbot.CallBundle(context.Background(), []string{"0x1", "0x2", "0x3", "0x4"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, res.Reverted(), "bundle should be reverted")
+	testutil.Equals(t, 4, len(res.Results))
+
+	err = res.RevertErr()
+	testutil.Assert(t, errors.Is(err, ErrBundleReverted), "unexpected error:%v", err)
+	testutil.Assert(t, !errors.Is(err, ErrNonceTooLow), "unexpected error:%v", err)
+	var revertErr *RevertError
+	testutil.Assert(t, errors.As(err, &revertErr), "expected a revert error got:%v", err)
+	testutil.Equals(t, []int{1, 3}, revertErr.Failed)
+	testutil.Equals(t, 4, len(revertErr.Results))
+	testutil.Equals(t, "too little received", revertErr.Results[1].Revert)
+	testutil.Equals(t, uint64(21000), revertErr.Results[1].GasUsed)
+	testutil.Equals(t, "expired", revertErr.Results[3].Revert)
+	for _, exp := range []string{"failed txs:2/4", "tx:1 hash:0x2", "revert:too little received", "tx:3 hash:0x4", "revert:expired"} {
+		testutil.Assert(t, strings.Contains(err.Error(), exp), "error:%v should contain:%v", err, exp)
+	}
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"results":[{"txHash":"0x1","error":"nonce too low"}]}}`
+	res, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, errors.Is(res.RevertErr(), ErrNonceTooLow), "unexpected error:%v", res.RevertErr())
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"results":[{"txHash":"0x1","gasUsed":50000}]}}`
+	res, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !res.Reverted(), "bundle shouldn't be reverted")
+	testutil.Ok(t, res.RevertErr())
+}
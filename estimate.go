@@ -0,0 +1,114 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const MethodEstimateGasBundle = "eth_estimateGasBundle"
+
+type ParamsEstimateGas struct {
+	Txs            []Tx          `json:"txs,omitempty"`
+	BlockNum       string        `json:"blockNumber,omitempty"`
+	StateBlockNum  string        `json:"stateBlockNumber,omitempty"`
+	StateOverrides StateOverride `json:"stateOverrides,omitempty"`
+}
+
+type EstimateGasBundleOpts struct {
+	// StateOverrides are applied on top of the state block before the first transaction.
+	// The local fallback can't apply them so it returns an error when they are set.
+	StateOverrides StateOverride
+}
+
+// WithGasEstimator sets the node used to estimate the bundle gas locally
+// when the relay doesn't support eth_estimateGasBundle.
+// An ethclient.Client can be used directly.
+func WithGasEstimator(estimator ethereum.GasEstimator) Option {
+	return func(f *Flashbot) {
+		f.gasEstimator = estimator
+	}
+}
+
+// EstimateGasBundle estimates the gas used by each transaction of the bundle.
+// When the relay rejects the method and a gas estimator is set
+// the estimate falls back to eth_estimateGas for each transaction.
+// The fallback only estimates on top of the node's pending state
+// so it returns an error for a non zero blockNumState or state overrides.
+func (self *Flashbot) EstimateGasBundle(ctx context.Context, txs []Tx, blockNumState uint64, opts *EstimateGasBundleOpts) (*Response, error) {
+	if len(txs) == 0 {
+		return nil, errors.New("bundle txs can't be empty")
+	}
+	if opts == nil {
+		opts = &EstimateGasBundleOpts{}
+	}
+	if err := opts.StateOverrides.Validate(); err != nil {
+		return nil, err
+	}
+	fallback := self.gasEstimator != nil
+
+	blockTarget := uint64(100000000000000)
+	stateBlockNum := "latest"
+	if blockNumState != 0 {
+		stateBlockNum = hexutil.EncodeUint64(blockNumState)
+		blockTarget = blockNumState + 1
+	}
+	param := ParamsEstimateGas{
+		Txs:            txs,
+		BlockNum:       hexutil.EncodeUint64(blockTarget),
+		StateBlockNum:  stateBlockNum,
+		StateOverrides: opts.StateOverrides,
+	}
+
+	resp, err := self.req(ctx, MethodEstimateGasBundle, param)
+	if err != nil {
+		if fallback && isMethodNotFound(err) {
+			return self.estimateGasLocal(ctx, txs, blockNumState, opts)
+		}
+		return nil, errors.Wrap(err, "flashbot estimate gas request")
+	}
+
+	rr, err := self.parseResp(resp, blockTarget)
+	if err != nil {
+		if fallback && isMethodNotFound(err) {
+			return self.estimateGasLocal(ctx, txs, blockNumState, opts)
+		}
+		return nil, err
+	}
+	return rr, nil
+}
+
+// estimateGasLocal estimates each transaction separately with the node.
+// eth_estimateGas runs on top of the pending state so the state changes
+// of the earlier bundle transactions are only visible to the later ones
+// when they are already in the node's pending block.
+func (self *Flashbot) estimateGasLocal(ctx context.Context, txs []Tx, blockNumState uint64, opts *EstimateGasBundleOpts) (*Response, error) {
+	if blockNumState != 0 {
+		return nil, errors.Errorf("local gas estimate can't use state block:%v", blockNumState)
+	}
+	if len(opts.StateOverrides) > 0 {
+		return nil, errors.New("local gas estimate can't apply state overrides")
+	}
+	rr := &Response{}
+	for i, tx := range txs {
+		to := tx.To
+		gas, err := self.gasEstimator.EstimateGas(ctx, ethereum.CallMsg{
+			From: tx.From,
+			To:   &to,
+			Data: tx.Data,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "estimating gas locally tx index:%v", i)
+		}
+		rr.Results = append(rr.Results, TxResult{
+			FromAddress: tx.From.Hex(),
+			GasUsed:     gas,
+		})
+	}
+	return rr, nil
+}
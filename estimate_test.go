@@ -0,0 +1,73 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type gasEstimatorMock struct {
+	msgs []ethereum.CallMsg
+}
+
+func (self *gasEstimatorMock) EstimateGas(_ context.Context, msg ethereum.CallMsg) (uint64, error) {
+	self.msgs = append(self.msgs, msg)
+	return 21000 + uint64(len(msg.Data)), nil
+}
+
+func TestEstimateGasBundleFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"unknown method: eth_estimateGasBundle"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	txs := []Tx{
+		{From: common.HexToAddress("0x1"), To: common.HexToAddress("0x2")},
+		{From: common.HexToAddress("0x1"), To: common.HexToAddress("0x3"), Data: []byte{1, 2}},
+	}
+
+	// Without an estimator the relay error is returned.
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+	_, err = flashbot.EstimateGasBundle(context.Background(), txs, 0, nil)
+	testutil.NotOk(t, err)
+
+	estimator := &gasEstimatorMock{}
+	flashbot, err = New(privKey, &Api{URL: srv.URL}, WithGasEstimator(estimator))
+	testutil.Ok(t, err)
+
+	resp, err := flashbot.EstimateGasBundle(context.Background(), txs, 0, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(resp.Results))
+	testutil.Equals(t, uint64(21000), resp.Results[0].GasUsed)
+	testutil.Equals(t, uint64(21002), resp.Results[1].GasUsed)
+	testutil.Equals(t, common.HexToAddress("0x1").Hex(), resp.Results[1].FromAddress)
+
+	testutil.Equals(t, 2, len(estimator.msgs))
+	testutil.Equals(t, common.HexToAddress("0x3"), *estimator.msgs[1].To)
+	testutil.Equals(t, []byte{1, 2}, estimator.msgs[1].Data)
+
+	// The fallback can't estimate on a past state or with overrides.
+	_, err = flashbot.EstimateGasBundle(context.Background(), txs, 10, nil)
+	testutil.NotOk(t, err)
+	nonce := hexutil.Uint64(1)
+	_, err = flashbot.EstimateGasBundle(context.Background(), txs, 0, &EstimateGasBundleOpts{
+		StateOverrides: StateOverride{common.HexToAddress("0x1"): {Nonce: &nonce}},
+	})
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 2, len(estimator.msgs))
+}
@@ -6,13 +6,22 @@ package flashbot
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,6 +29,7 @@ import (
 	"github.com/cryptoriums/packages/testutil"
 	tx_p "github.com/cryptoriums/packages/tx"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-kit/log"
@@ -35,6 +45,8 @@ const (
 	// Some ERC20 token with approve function.
 	contractAddressGoerli  = "0xf74a5ca65e4552cff0f13b116113ccb493c580c5"
 	contractAddressMainnet = "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"
+	contractAddressSepolia = "0xfff9976782d46cc05630d1f6ebab18b2324d6b14"
+	contractAddressHolesky = "0x94373a4919b3240d86ea41593d5eba789fef3848"
 )
 
 var logger = log.With(
@@ -101,7 +113,7 @@ var logger = log.With(
 // 			ctx,
 // 			txHex,
 // 			blockNumber+10,
-// 			false,
+// 			nil,
 // 		)
 // 		testutil.Ok(t,err)
 
@@ -120,19 +132,12 @@ func TestPrivateTx(t *testing.T) {
 	client, err := ethclient.DialContext(ctx, envr.Nodes[0].URL)
 	testutil.Ok(t, err)
 
-	netID, err := client.NetworkID(ctx)
-	testutil.Ok(t, err)
-	level.Info(logger).Log("msg", "network", "id", netID.String(), "node", envr.Nodes[0].URL)
-
 	privKey, pubKey, err := Keys(envr.Accounts[0].Priv)
 	testutil.Ok(t, err)
 
 	level.Info(logger).Log("msg", "pub key for", "addr", pubKey.Hex())
 
-	endpoint, err := DefaultApi(netID.Int64())
-	testutil.Ok(t, err)
-
-	flashbot, err := New(privKey, endpoint)
+	flashbot, err := NewFromClient(ctx, client, privKey)
 	testutil.Ok(t, err)
 
 	respC, err := flashbot.CancelPrivateTransaction(ctx, common.HexToHash("0"))
@@ -159,10 +164,7 @@ func TestExample(t *testing.T) {
 
 	level.Info(logger).Log("msg", "pub key for", "addr", pubKey.Hex())
 
-	endpoint, err := DefaultApi(netID.Int64())
-	testutil.Ok(t, err)
-
-	flashbot, err := New(privKey, endpoint)
+	flashbot, err := NewFromClient(ctx, client, privKey)
 	testutil.Ok(t, err)
 
 	nonce, err := client.NonceAt(ctx, *pubKey, nil)
@@ -217,8 +219,10 @@ func TestExample(t *testing.T) {
 			ctx,
 			[]string{txHex},
 			0,
+			nil,
 		)
 		testutil.Ok(t, err)
+		testutil.Ok(t, resp.RevertErr())
 
 		level.Info(logger).Log("msg", "Called Bundle",
 			"respStruct", fmt.Sprintf("%+v", resp),
@@ -232,15 +236,17 @@ func TestExample(t *testing.T) {
 
 		level.Info(logger).Log("msg", "created send transaction", "hash", tx.Hash())
 
-		var resp *Response
-		for i := uint64(1); i < blockNumMax; i++ {
-			resp, err = flashbot.SendBundle(
-				ctx,
-				[]string{txHex},
-				blockNumber+i,
-			)
-			time.Sleep(100 * time.Millisecond)
-			testutil.Ok(t, err)
+		resp, err := SendBundleForBlocks(
+			ctx,
+			flashbot,
+			[]string{txHex},
+			blockNumber+1,
+			blockNumMax-1,
+			nil,
+		)
+		testutil.Ok(t, err)
+		for _, r := range resp {
+			testutil.Ok(t, r.Err)
 		}
 
 		level.Info(logger).Log("msg", "Sent Bundle",
@@ -280,6 +286,10 @@ func GetContractAddress(networkID *big.Int) (common.Address, error) {
 		return common.HexToAddress(contractAddressMainnet), nil
 	case 5:
 		return common.HexToAddress(contractAddressGoerli), nil
+	case 11155111:
+		return common.HexToAddress(contractAddressSepolia), nil
+	case 17000:
+		return common.HexToAddress(contractAddressHolesky), nil
 	default:
 		return common.Address{}, errors.Errorf("network id not supported id:%v", netID)
 	}
@@ -319,3 +329,422 @@ const ContractABI = `[
 	   "type":"function"
 	}
  ]`
+
+func TestClientCertificates(t *testing.T) {
+	ctx := context.Background()
+
+	clientCert := selfSignedCert(t)
+
+	var peerCerts int
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peerCerts = len(r.TLS.PeerCertificates)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":true}`))
+		testutil.Ok(t, err)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+
+	{
+		flashbot, err := New(privKey, &Api{URL: srv.URL, RootCAs: rootCAs})
+		testutil.Ok(t, err)
+		_, err = flashbot.CancelPrivateTransaction(ctx, common.HexToHash("0"))
+		testutil.NotOk(t, err)
+	}
+
+	// The relay certificate is verified.
+	{
+		flashbot, err := New(privKey, &Api{URL: srv.URL, ClientCertificates: []tls.Certificate{clientCert}})
+		testutil.Ok(t, err)
+		_, err = flashbot.CancelPrivateTransaction(ctx, common.HexToHash("0"))
+		testutil.NotOk(t, err)
+	}
+
+	{
+		flashbot, err := New(privKey, &Api{URL: srv.URL, RootCAs: rootCAs, ClientCertificates: []tls.Certificate{clientCert}})
+		testutil.Ok(t, err)
+		resp, err := flashbot.CancelPrivateTransaction(ctx, common.HexToHash("0"))
+		testutil.Ok(t, err)
+		testutil.Assert(t, resp.Result, "resp.Result didn't return true")
+		testutil.Equals(t, 1, peerCerts)
+	}
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.Ok(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "searcher"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	testutil.Ok(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestURLOverride(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":true}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: "http://127.0.0.1:0"})
+	testutil.Ok(t, err)
+
+	_, err = flashbot.CancelPrivateTransaction(WithURLOverride(context.Background(), srv.URL), common.HexToHash("0"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, called, "override url wasn't called")
+}
+
+func TestSendBundleOpts(t *testing.T) {
+	var params []ParamsSend
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	revertHash := common.HexToHash("0x2")
+	resp, err := flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, &SendBundleOpts{
+		MinTimestamp:      1,
+		MaxTimestamp:      2,
+		RevertingTxHashes: []common.Hash{revertHash},
+		ReplacementUuid:   "uuid",
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.BundleHash)
+
+	testutil.Equals(t, []ParamsSend{{
+		BlockNum:          "0xa",
+		Txs:               []string{"0x1"},
+		MinTimestamp:      1,
+		MaxTimestamp:      2,
+		RevertingTxHashes: []string{revertHash.Hex()},
+		ReplacementUuid:   "uuid",
+	}}, params)
+}
+
+func TestCallBundleOpts(t *testing.T) {
+	var params []ParamsCall
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1","results":[]}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL, SupportsSimulation: true})
+	testutil.Ok(t, err)
+
+	// Without opts the target is the block after the state block.
+	_, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []ParamsCall{{
+		Txs:           []string{"0x1"},
+		BlockNum:      "0xb",
+		StateBlockNum: "0xa",
+	}}, params)
+
+	coinbase := common.HexToAddress("0xc0ffee")
+	nonce := hexutil.Uint64(7)
+	overrides := StateOverride{
+		coinbase: {
+			Nonce:     &nonce,
+			Balance:   (*hexutil.Big)(big.NewInt(1)),
+			StateDiff: map[common.Hash]common.Hash{common.HexToHash("0x1"): common.HexToHash("0x2")},
+		},
+	}
+	_, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 10, &CallBundleOpts{
+		TargetBlockNum: 12,
+		Timestamp:      3,
+		GasLimit:       30_000_000,
+		Difficulty:     big.NewInt(4),
+		BaseFee:        big.NewInt(5),
+		Coinbase:       &coinbase,
+		StateOverrides: overrides,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []ParamsCall{{
+		Txs:            []string{"0x1"},
+		BlockNum:       "0xc",
+		StateBlockNum:  "0xa",
+		Timestamp:      3,
+		GasLimit:       30_000_000,
+		Difficulty:     big.NewInt(4),
+		BaseFee:        big.NewInt(5),
+		Coinbase:       coinbase.Hex(),
+		StateOverrides: overrides,
+	}}, params)
+
+	_, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 10, &CallBundleOpts{
+		StateOverrides: StateOverride{coinbase: {
+			State:     map[common.Hash]common.Hash{},
+			StateDiff: map[common.Hash]common.Hash{},
+		}},
+	})
+	testutil.NotOk(t, err)
+}
+
+func TestPrivateTxPreferences(t *testing.T) {
+	var params []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendPrivateTransaction(context.Background(), "0x1", 10, &PrivateTxPreferences{
+		Fast: true,
+		Privacy: &PrivateTxPrivacy{
+			Hints:    []PrivacyHint{HintHash, HintCalldata},
+			Builders: []string{"flashbots"},
+		},
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, `{"tx":"0x1","maxBlockNumber":"0xa","preferences":{"fast":true,"privacy":{"hints":["hash","calldata"],"builders":["flashbots"]}}}`, string(params[0]))
+}
+
+func TestMethodOverrides(t *testing.T) {
+	var method string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		method = msg.Method
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{
+		URL:                srv.URL,
+		SupportsSimulation: true,
+		Methods:            map[string]string{MethodCallBundle: "relay_simulate"},
+	})
+	testutil.Ok(t, err)
+
+	_, err = flashbot.CallBundle(context.Background(), []string{"0x1"}, 0, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "relay_simulate", method)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 1, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, MethodSendBundle, method)
+}
+
+func TestApiTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	flashbot, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		Timeout:                20 * time.Millisecond,
+	})
+	testutil.Ok(t, err)
+
+	start := time.Now()
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, errors.Is(err, context.DeadlineExceeded), "unexpected error:%v", err)
+	testutil.Assert(t, time.Since(start) < time.Second, "request wasn't bound by the timeout")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (self roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return self(r)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	var calls int
+	client := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true}, WithHTTPClient(client))
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, calls)
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+		testutil.Ok(t, err)
+	}
+	testutil.Equals(t, int32(1), atomic.LoadInt32(&conns))
+}
+
+func TestProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer proxy.Close()
+
+	flashbot, err := New(nil, &Api{URL: "http://relay.invalid", Proxy: proxy.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "http://relay.invalid/", proxied)
+
+	_, err = New(nil, &Api{URL: "http://relay.invalid", Proxy: "ftp://127.0.0.1"})
+	testutil.NotOk(t, err)
+}
+
+func TestRawResponse(t *testing.T) {
+	var resp string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{URL: srv.URL, SupportsStats: true, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1","smart":true}}`
+	res, err := flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, resp, string(res.Raw))
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"isSimulated":true,"consideredByBuildersAt":[{"pubkey":"0x1"}]}}`
+	stats, err := flashbot.GetBundleStats(ctx, "0x1", 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, resp, string(stats.Raw))
+
+	var extra struct {
+		Result struct {
+			ConsideredByBuildersAt []struct {
+				Pubkey string
+			}
+		}
+	}
+	testutil.Ok(t, json.Unmarshal(stats.Raw, &extra))
+	testutil.Equals(t, "0x1", extra.Result.ConsideredByBuildersAt[0].Pubkey)
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"is_high_priority":true,"refund_rate":0.9}}`
+	userStats, err := flashbot.GetUserStats(ctx, 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, resp, string(userStats.Raw))
+}
+
+func TestBundleStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{
+			"isSimulated":true,
+			"isHighPriority":false,
+			"simulatedAt":"2023-01-02T15:04:05.123Z",
+			"receivedAt":"2023-01-02T15:04:05Z",
+			"consideredByBuildersAt":[{"pubkey":"0xa1","timestamp":"2023-01-02T15:04:06Z"},{"pubkey":"0xa2","timestamp":null}],
+			"sealedByBuildersAt":[],
+			"sentToMinersAt":null
+		}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{URL: srv.URL, SupportsStats: true, SkipFlashbotsSignature: true}, WithDecodeMode(DecodeStrict))
+	testutil.Ok(t, err)
+
+	stats, err := flashbot.GetBundleStats(context.Background(), "0x1", 10)
+	testutil.Ok(t, err)
+	testutil.Assert(t, stats.Result.IsSimulated, "bundle should be simulated")
+	testutil.Equals(t, time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC), stats.Result.ReceivedAt.UTC())
+	testutil.Equals(t, time.Date(2023, 1, 2, 15, 4, 5, 123000000, time.UTC), stats.Result.SimulatedAt.UTC())
+	testutil.Equals(t, 2, len(stats.Result.ConsideredByBuildersAt))
+	testutil.Equals(t, "0xa1", stats.Result.ConsideredByBuildersAt[0].Pubkey)
+	testutil.Equals(t, time.Date(2023, 1, 2, 15, 4, 6, 0, time.UTC), stats.Result.ConsideredByBuildersAt[0].Timestamp.UTC())
+	testutil.Assert(t, stats.Result.ConsideredByBuildersAt[1].Timestamp == nil, "missing timestamp should be nil")
+	testutil.Equals(t, 0, len(stats.Result.SealedByBuildersAt))
+	testutil.Assert(t, stats.Result.SentToMinersAt == nil, "legacy timestamp should be nil")
+}
+
+func TestDefaultApi(t *testing.T) {
+	api, err := DefaultApi(11155111)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "https://relay-sepolia.flashbots.net", api.URL)
+	api, err = DefaultApi(17000)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "https://relay-holesky.flashbots.net", api.URL)
+
+	_, err = DefaultApi(1234)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "17000 (holesky)"), "error should list the supported networks:%v", err)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DerivationPathDefault is the first account of the standard Ethereum derivation path.
+const DerivationPathDefault = "m/44'/60'/0'/0/0"
+
+// DeriveKey derives the key at the BIP-32 path, for example DerivationPathDefault,
+// from a BIP-39 mnemonic and an optional passphrase.
+func DeriveKey(mnemonic, passphrase, path string) (*ecdsa.PrivateKey, error) {
+	keys, err := DeriveKeys(mnemonic, passphrase, path, 1)
+	if err != nil {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+// DeriveKeys derives count consecutive keys by incrementing the last index of the path,
+// for example the identity key and the keys of the bot accounts.
+func DeriveKeys(mnemonic, passphrase, path string, count int) ([]*ecdsa.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mnemonic")
+	}
+	dpath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing derivation path:%v", path)
+	}
+	if len(dpath) == 0 {
+		return nil, errors.New("derivation path can't be empty")
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	for _, index := range dpath[:len(dpath)-1] {
+		if key, chainCode, err = deriveChild(key, chainCode, index); err != nil {
+			return nil, errors.Wrapf(err, "deriving path:%v", path)
+		}
+	}
+
+	keys := make([]*ecdsa.PrivateKey, 0, count)
+	last := dpath[len(dpath)-1]
+	for i := 0; i < count; i++ {
+		child, _, err := deriveChild(key, chainCode, last+uint32(i))
+		if err != nil {
+			return nil, errors.Wrapf(err, "deriving index:%v", i)
+		}
+		prvKey, err := crypto.ToECDSA(child.FillBytes(make([]byte, 32)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "converting key:%v", i)
+		}
+		keys = append(keys, prvKey)
+	}
+	return keys, nil
+}
+
+// deriveChild implements the BIP-32 private parent key to private child key derivation.
+func deriveChild(key *big.Int, chainCode []byte, index uint32) (*big.Int, []byte, error) {
+	var data []byte
+	if index >= 0x80000000 {
+		data = append([]byte{0}, key.FillBytes(make([]byte, 32))...)
+	} else {
+		x, y := crypto.S256().ScalarBaseMult(key.FillBytes(make([]byte, 32)))
+		data = crypto.CompressPubkey(&ecdsa.PublicKey{Curve: crypto.S256(), X: x, Y: y})
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := crypto.S256().Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, nil, errors.Errorf("invalid key at index:%v", index)
+	}
+	child := il.Add(il, key)
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, nil, errors.Errorf("invalid key at index:%v", index)
+	}
+	return child, sum[32:], nil
+}
@@ -0,0 +1,41 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDeriveKeys(t *testing.T) {
+	// The well known development mnemonic of hardhat and anvil.
+	const mnemonic = "test test test test test test test test test test test junk"
+
+	key, err := DeriveKey(mnemonic, "", DerivationPathDefault)
+	testutil.Ok(t, err)
+	testutil.Equals(t, common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), crypto.PubkeyToAddress(key.PublicKey))
+
+	keys, err := DeriveKeys(mnemonic, "", DerivationPathDefault, 3)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(keys))
+	for i, exp := range []string{
+		"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		"0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC",
+	} {
+		testutil.Equals(t, common.HexToAddress(exp), crypto.PubkeyToAddress(keys[i].PublicKey))
+	}
+
+	withPass, err := DeriveKey(mnemonic, "pass", DerivationPathDefault)
+	testutil.Ok(t, err)
+	testutil.Assert(t, crypto.PubkeyToAddress(withPass.PublicKey) != crypto.PubkeyToAddress(key.PublicKey), "the passphrase should change the keys")
+
+	_, err = DeriveKey("test test test", "", DerivationPathDefault)
+	testutil.NotOk(t, err)
+	_, err = DeriveKey(mnemonic, "", "m/x")
+	testutil.NotOk(t, err)
+}
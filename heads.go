@@ -0,0 +1,193 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	headsBackoffMin = time.Second
+	headsBackoffMax = 30 * time.Second
+)
+
+// HeadSubscriber is the subset of the ethclient.Client methods used to follow the chain head.
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// HeadHook is called for every new head.
+type HeadHook func(ctx context.Context, head *types.Header)
+
+// Heads follows the chain head through a newHeads subscription so that
+// the target blocks are derived from the live head instead of guessed.
+type Heads struct {
+	sub    HeadSubscriber
+	logger log.Logger
+
+	mtx     sync.Mutex
+	head    *types.Header
+	hooks   []HeadHook
+	waiters []chan struct{}
+}
+
+func NewHeads(sub HeadSubscriber, logger log.Logger) *Heads {
+	return &Heads{
+		sub:    sub,
+		logger: log.With(logger, "component", "heads"),
+	}
+}
+
+// OnHead adds a hook called for every new head in the order they were added.
+// The hooks run in the subscription goroutine so a slow hook delays the next heads.
+func (self *Heads) OnHead(hook HeadHook) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.hooks = append(self.hooks, hook)
+}
+
+// Run subscribes to the new heads until the context is done.
+// Dropped subscriptions are reestablished with an exponential backoff.
+func (self *Heads) Run(ctx context.Context) {
+	backoff := headsBackoffMin
+	for {
+		received, err := self.subscribe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = headsBackoffMin
+		}
+		level.Warn(self.logger).Log("msg", "head subscription dropped", "err", err, "resubscribeIn", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		if backoff *= 2; backoff > headsBackoffMax {
+			backoff = headsBackoffMax
+		}
+	}
+}
+
+func (self *Heads) subscribe(ctx context.Context) (bool, error) {
+	ch := make(chan *types.Header)
+	sub, err := self.sub.SubscribeNewHead(ctx, ch)
+	if err != nil {
+		return false, errors.Wrap(err, "subscribing to new heads")
+	}
+	defer sub.Unsubscribe()
+
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return received, ctx.Err()
+		case err := <-sub.Err():
+			return received, err
+		case head := <-ch:
+			received = true
+			self.setHead(ctx, head)
+		}
+	}
+}
+
+func (self *Heads) setHead(ctx context.Context, head *types.Header) {
+	self.mtx.Lock()
+	// Reorgs can deliver the same or a lower number which is still the new head.
+	self.head = head
+	hooks := self.hooks
+	waiters := self.waiters
+	self.waiters = nil
+	self.mtx.Unlock()
+
+	for _, w := range waiters {
+		close(w)
+	}
+	for _, hook := range hooks {
+		hook(ctx, head)
+	}
+}
+
+// Head returns the latest head, nil until the first one arrives.
+func (self *Heads) Head() *types.Header {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.head
+}
+
+// WaitHead returns the latest head waiting for the first one to arrive.
+func (self *Heads) WaitHead(ctx context.Context) (*types.Header, error) {
+	for {
+		self.mtx.Lock()
+		head := self.head
+		var wait chan struct{}
+		if head == nil {
+			wait = make(chan struct{})
+			self.waiters = append(self.waiters, wait)
+		}
+		self.mtx.Unlock()
+		if head != nil {
+			return head, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// NextBlock is the target block for a bundle, the one after the latest head.
+func (self *Heads) NextBlock(ctx context.Context) (uint64, error) {
+	head, err := self.WaitHead(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return head.Number.Uint64() + 1, nil
+}
+
+// MaxBlock is the maxBlockNumber for a private transaction valid for the given number of blocks.
+func (self *Heads) MaxBlock(ctx context.Context, blocks uint64) (uint64, error) {
+	head, err := self.WaitHead(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return head.Number.Uint64() + blocks, nil
+}
+
+// BundleFunc builds the bundle to send for the block after the head,
+// returning no transactions skips the block.
+type BundleFunc func(ctx context.Context, head *types.Header) ([]string, *SendBundleOpts, error)
+
+// SubmitOnHead returns a hook which sends the bundle built for every new head
+// to the block after it as soon as the head arrives.
+// Failures are logged and don't stop the following submissions.
+func SubmitOnHead(flashbot Flashboter, logger log.Logger, build BundleFunc) HeadHook {
+	return func(ctx context.Context, head *types.Header) {
+		target := head.Number.Uint64() + 1
+		txs, opts, err := build(ctx, head)
+		if err != nil {
+			level.Error(logger).Log("msg", "building bundle", "block", target, "err", err)
+			return
+		}
+		if len(txs) == 0 {
+			return
+		}
+		if _, err := flashbot.SendBundle(ctx, txs, target, opts); err != nil {
+			level.Error(logger).Log("msg", "sending bundle", "block", target, "relay", flashbot.Api().URL, "err", err)
+		}
+	}
+}
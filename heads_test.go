@@ -0,0 +1,108 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+type headsMock struct {
+	mtx   sync.Mutex
+	calls int
+	heads chan *types.Header
+}
+
+func (self *headsMock) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	self.mtx.Lock()
+	self.calls++
+	calls := self.calls
+	self.mtx.Unlock()
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		// The first subscription drops after a head to test resubscribing.
+		for {
+			select {
+			case <-quit:
+				return nil
+			case h := <-self.heads:
+				select {
+				case ch <- h:
+				case <-quit:
+					return nil
+				}
+				if calls == 1 {
+					return errors.New("connection lost")
+				}
+			}
+		}
+	}), nil
+}
+
+func TestHeads(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		sent []ParamsSend
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		var params []ParamsSend
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		mtx.Lock()
+		sent = append(sent, params[0])
+		mtx.Unlock()
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	sub := &headsMock{heads: make(chan *types.Header)}
+	heads := NewHeads(sub, log.NewNopLogger())
+	txs := []string{signedTxHex(t)}
+	done := make(chan uint64, 2)
+	heads.OnHead(SubmitOnHead(flashbot, log.NewNopLogger(), func(ctx context.Context, head *types.Header) ([]string, *SendBundleOpts, error) {
+		return txs, nil, nil
+	}))
+	heads.OnHead(func(ctx context.Context, head *types.Header) { done <- head.Number.Uint64() })
+
+	ctx, cncl := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cncl()
+	go heads.Run(ctx)
+
+	testutil.Equals(t, (*types.Header)(nil), heads.Head())
+	go func() { sub.heads <- &types.Header{Number: big.NewInt(10)} }()
+	next, err := heads.NextBlock(ctx)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(11), next)
+	testutil.Equals(t, uint64(10), <-done)
+
+	// Delivered after resubscribing.
+	sub.heads <- &types.Header{Number: big.NewInt(11)}
+	testutil.Equals(t, uint64(11), <-done)
+	maxBlock, err := heads.MaxBlock(ctx, 25)
+	testutil.Ok(t, err)
+	testutil.Equals(t, uint64(36), maxBlock)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	testutil.Equals(t, 2, len(sent))
+	testutil.Equals(t, hexutil.EncodeUint64(11), sent[0].BlockNum)
+	testutil.Equals(t, hexutil.EncodeUint64(12), sent[1].BlockNum)
+}
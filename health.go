@@ -0,0 +1,153 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	healthCheckIntervalDefault  = 15 * time.Second
+	healthCheckTimeoutDefault   = 5 * time.Second
+	healthCheckThresholdDefault = 3
+)
+
+// HealthCheckConfig configures the relay health checks of Multi.
+type HealthCheckConfig struct {
+	// Interval between the probes, defaults to 15s.
+	Interval time.Duration
+	// Timeout of a single probe, defaults to 5s.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed probes
+	// after which a relay is marked unhealthy, defaults to 3.
+	// A single successful probe marks it healthy again.
+	FailureThreshold int
+	// Probe checks a single relay, defaults to PingProbe.
+	Probe func(ctx context.Context, flashbot Flashboter) error
+}
+
+// PingProbe treats the relay as healthy when it replies to an HTTP request
+// with any status other than a server error.
+// The request uses the client of the relay so its TLS and proxy settings apply.
+func PingProbe(ctx context.Context, flashbot Flashboter) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, flashbot.Api().URL, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating request")
+	}
+	client := http.DefaultClient
+	if f, ok := flashbot.(interface{ client() *http.Client }); ok {
+		client = f.client()
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "ping request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return errors.Errorf("bad response status %v", resp.Status)
+	}
+	return nil
+}
+
+// UserStatsProbe treats the relay as healthy when it replies to flashbots_getUserStats.
+func UserStatsProbe(ctx context.Context, flashbot Flashboter) error {
+	_, err := flashbot.GetUserStats(ctx, 0)
+	return err
+}
+
+// StartHealthChecks probes all relays periodically until the context is done.
+// Unhealthy relays are left out of the submissions until they recover.
+func (self *Multi) StartHealthChecks(ctx context.Context, logger log.Logger, cfg HealthCheckConfig) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = healthCheckIntervalDefault
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = healthCheckTimeoutDefault
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = healthCheckThresholdDefault
+	}
+	if cfg.Probe == nil {
+		cfg.Probe = PingProbe
+	}
+	logger = log.With(logger, "component", "relay-health")
+
+	go func() {
+		failures := make(map[Flashboter]int)
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			self.checkHealth(ctx, logger, cfg, failures)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (self *Multi) checkHealth(ctx context.Context, logger log.Logger, cfg HealthCheckConfig, failures map[Flashboter]int) {
+	flashbots := self.Flashbots()
+	errs := make([]error, len(flashbots))
+	var wg sync.WaitGroup
+	for i, f := range flashbots {
+		wg.Add(1)
+		go func(i int, f Flashboter) {
+			defer wg.Done()
+			ctx, cncl := context.WithTimeout(ctx, cfg.Timeout)
+			defer cncl()
+			errs[i] = cfg.Probe(ctx, f)
+		}(i, f)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for i, f := range flashbots {
+		if errs[i] == nil {
+			if self.unhealthy[f] {
+				level.Info(logger).Log("msg", "relay recovered", "relay", f.Api().URL)
+			}
+			failures[f] = 0
+			delete(self.unhealthy, f)
+			continue
+		}
+		failures[f]++
+		if failures[f] >= cfg.FailureThreshold && !self.unhealthy[f] {
+			level.Warn(logger).Log("msg", "relay marked unhealthy", "relay", f.Api().URL, "err", errs[i])
+			self.unhealthy[f] = true
+		}
+	}
+}
+
+// Healthy returns the relays which passed the health checks.
+// When all relays are unhealthy all of them are returned
+// as there is nothing better to send to.
+func (self *Multi) Healthy() []Flashboter {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	flashbots := self.Flashbots()
+	var healthy []Flashboter
+	for _, f := range flashbots {
+		if !self.unhealthy[f] {
+			healthy = append(healthy, f)
+		}
+	}
+	if len(healthy) == 0 {
+		return flashbots
+	}
+	return healthy
+}
@@ -0,0 +1,73 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log"
+)
+
+func TestHealthChecks(t *testing.T) {
+	srvOk := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srvOk.Close()
+
+	var down int32 = 1
+	srvDown := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srvDown.Close()
+
+	flashbots, err := NewMulti(1, nil, &Api{URL: srvOk.URL}, &Api{URL: srvDown.URL})
+	testutil.Ok(t, err)
+	multi := NewMultiRelay(flashbots...)
+	testutil.Equals(t, 2, len(multi.Healthy()))
+
+	ctx, cncl := context.WithCancel(context.Background())
+	defer cncl()
+	multi.StartHealthChecks(ctx, log.NewNopLogger(), HealthCheckConfig{
+		Interval:         10 * time.Millisecond,
+		FailureThreshold: 2,
+	})
+
+	waitHealthy := func(exp int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for len(multi.Healthy()) != exp {
+			testutil.Assert(t, time.Now().Before(deadline), "timeout waiting for %v healthy relays", exp)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitHealthy(1)
+	testutil.Equals(t, srvOk.URL, multi.Healthy()[0].Api().URL)
+
+	// Fails back once the relay recovers.
+	atomic.StoreInt32(&down, 0)
+	waitHealthy(2)
+}
+
+func TestPingProbeUsesRelayClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	// The certificate of the server is only trusted through the RootCAs of the relay.
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	flashbot, err := New(nil, &Api{URL: srv.URL, RootCAs: roots})
+	testutil.Ok(t, err)
+	testutil.Ok(t, PingProbe(context.Background(), flashbot))
+
+	flashbot, err = New(nil, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+	testutil.NotOk(t, PingProbe(context.Background(), flashbot))
+}
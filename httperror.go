@@ -0,0 +1,97 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// httpErrorBodyMax limits the body kept in an HTTPError
+// so that large html error pages don't flood the logs.
+const httpErrorBodyMax = 512
+
+// HTTPError is returned when the relay replies with a non 2xx status.
+type HTTPError struct {
+	StatusCode int
+	Relay      string
+	// Body is the response body as text, html pages are reduced to their title or text.
+	Body string
+	// RPCError is set when the body is a json-rpc error.
+	RPCError *RPCError
+	// RetryAfter is the delay requested by the relay with the Retry-After header.
+	RetryAfter time.Duration
+	// Retryable is set for statuses which are usually transient.
+	Retryable bool
+}
+
+func (self *HTTPError) Error() string {
+	msg := fmt.Sprintf("status:%v relay:%v", self.StatusCode, self.Relay)
+	if self.RPCError != nil {
+		msg += " " + self.RPCError.Error()
+	} else if self.Body != "" {
+		msg += " body:" + self.Body
+	}
+	return msg
+}
+
+func (self *HTTPError) Unwrap() error {
+	if self.RPCError == nil {
+		return nil
+	}
+	return self.RPCError
+}
+
+func (self *HTTPError) Is(target error) bool {
+	return target == ErrRateLimited && self.StatusCode == http.StatusTooManyRequests
+}
+
+func (self *Flashbot) newHTTPError(req *http.Request, resp *http.Response) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Relay:      req.URL.Redacted(),
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After")),
+	}
+	for _, c := range retryStatusCodesDefault {
+		httpErr.Retryable = httpErr.Retryable || c == resp.StatusCode
+	}
+
+	body, err := readBody(resp)
+	if err != nil {
+		httpErr.Body = fmt.Sprintf("reading body:%v", err)
+		return httpErr
+	}
+	httpErr.RPCError, httpErr.Body = parseErrorBody(body)
+	return httpErr
+}
+
+var (
+	htmlTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlNoise = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+)
+
+// parseErrorBody extracts the json-rpc error or a short text from the body of a failed response.
+func parseErrorBody(body []byte) (*RPCError, string) {
+	msg := &jsonrpcMessage{}
+	if err := json.Unmarshal(body, msg); err == nil && msg.Error != nil {
+		return &RPCError{Code: msg.Error.Code, Message: msg.Error.Message}, ""
+	}
+
+	text := string(body)
+	if m := htmlTitle.FindStringSubmatch(text); m != nil {
+		text = m[1]
+	} else {
+		text = htmlNoise.ReplaceAllString(text, " ")
+	}
+	text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+	if len(text) > httpErrorBodyMax {
+		text = text[:httpErrorBodyMax] + "..."
+	}
+	return nil, text
+}
@@ -0,0 +1,69 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestHTTPError(t *testing.T) {
+	var (
+		status int
+		body   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, err := w.Write([]byte(body))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	flashbot, err := New(privKey, &Api{URL: srv.URL, SupportsSimulation: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	status = http.StatusBadRequest
+	body = `<html><head><title>400 Bad Request</title><style>body{}</style></head><body><h1>Bad &amp; Request</h1></body></html>`
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	var httpErr *HTTPError
+	testutil.Assert(t, errors.As(err, &httpErr), "expected an http error got:%v", err)
+	testutil.Equals(t, http.StatusBadRequest, httpErr.StatusCode)
+	testutil.Equals(t, srv.URL, httpErr.Relay)
+	testutil.Equals(t, "400 Bad Request", httpErr.Body)
+	testutil.Assert(t, !httpErr.Retryable, "bad request shouldn't be retryable")
+	testutil.Assert(t, !strings.Contains(err.Error(), "X-Flashbots-Signature"), "error shouldn't include the request:%v", err)
+
+	body = "<p>invalid\n\n  bundle &lt;txs&gt;</p>"
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.As(err, &httpErr), "expected an http error got:%v", err)
+	testutil.Equals(t, "invalid bundle <txs>", httpErr.Body)
+
+	body = `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`
+	_, err = flashbot.CallBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.As(err, &httpErr), "expected an http error got:%v", err)
+	testutil.Equals(t, -32601, httpErr.RPCError.Code)
+	testutil.Assert(t, errors.Is(err, ErrUnknownMethod), "unexpected error:%v", err)
+
+	status = http.StatusTooManyRequests
+	body = "slow down"
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.As(err, &httpErr), "expected an http error got:%v", err)
+	testutil.Assert(t, httpErr.Retryable, "rate limiting should be retryable")
+	testutil.Assert(t, errors.Is(err, ErrRateLimited), "unexpected error:%v", err)
+
+	body = strings.Repeat("a", 2*httpErrorBodyMax)
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.As(err, &httpErr), "expected an http error got:%v", err)
+	testutil.Equals(t, httpErrorBodyMax+len("..."), len(httpErr.Body))
+}
@@ -0,0 +1,41 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+)
+
+// Interceptor observes or changes the requests sent to the relay,
+// for example for logging, metrics or capturing requests for replay.
+// It is called for every attempt including retries.
+type Interceptor interface {
+	// BeforeRequest is called with the signed request just before it is sent.
+	// The headers can be changed but changing the payload invalidates the signature.
+	// An error aborts the request.
+	BeforeRequest(ctx context.Context, method string, req *http.Request, payload []byte) error
+	// AfterResponse is called with the raw response or the error of the request.
+	AfterResponse(ctx context.Context, method string, resp []byte, err error)
+}
+
+// InterceptorFuncs adapts plain functions to the Interceptor interface,
+// nil functions are skipped.
+type InterceptorFuncs struct {
+	Before func(ctx context.Context, method string, req *http.Request, payload []byte) error
+	After  func(ctx context.Context, method string, resp []byte, err error)
+}
+
+func (self InterceptorFuncs) BeforeRequest(ctx context.Context, method string, req *http.Request, payload []byte) error {
+	if self.Before == nil {
+		return nil
+	}
+	return self.Before(ctx, method, req, payload)
+}
+
+func (self InterceptorFuncs) AfterResponse(ctx context.Context, method string, resp []byte, err error) {
+	if self.After != nil {
+		self.After(ctx, method, resp, err)
+	}
+}
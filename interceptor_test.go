@@ -0,0 +1,64 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestInterceptor(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Trace")
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	var (
+		payload  string
+		resp     string
+		methods  []string
+		abortErr = errors.New("abort")
+		abort    bool
+	)
+	flashbot, err := New(privKey, &Api{URL: srv.URL}, WithInterceptor(InterceptorFuncs{
+		Before: func(ctx context.Context, method string, req *http.Request, p []byte) error {
+			if abort {
+				return abortErr
+			}
+			testutil.Assert(t, req.Header.Get("X-Flashbots-Signature") != "", "request should be signed")
+			req.Header.Set("X-Trace", "trace")
+			payload = string(p)
+			return nil
+		},
+		After: func(ctx context.Context, method string, r []byte, err error) {
+			methods = append(methods, method)
+			resp = string(r)
+		},
+	}))
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "trace", header)
+	testutil.Assert(t, strings.Contains(payload, MethodSendBundle), "unexpected payload:%v", payload)
+	testutil.Assert(t, strings.Contains(resp, "bundleHash"), "unexpected response:%v", resp)
+
+	abort = true
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Assert(t, errors.Is(err, abortErr), "unexpected error:%v", err)
+	testutil.Equals(t, []string{MethodSendBundle, MethodSendBundle}, methods)
+}
@@ -0,0 +1,308 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+// journalMaxLineSize allows for records with large relay responses.
+const journalMaxLineSize = 16 * 1024 * 1024
+
+// JournalRecord is a single change appended to the journal,
+// either a submission to a relay or the inclusion status of a bundle.
+type JournalRecord struct {
+	Time time.Time `json:"time"`
+	// Key identifies the bundle across relays, see JournalKey.
+	Key      string        `json:"key"`
+	BlockNum uint64        `json:"blockNum"`
+	TxHashes []common.Hash `json:"txHashes,omitempty"`
+
+	// Set for submissions.
+	CorrelationID string          `json:"correlationID,omitempty"`
+	Relay         string          `json:"relay,omitempty"`
+	BundleHash    string          `json:"bundleHash,omitempty"`
+	Response      json.RawMessage `json:"response,omitempty"`
+	Err           string          `json:"err,omitempty"`
+
+	// Set for status updates.
+	Status SubmissionStatus `json:"status,omitempty"`
+}
+
+// JournalStore persists the journal records.
+type JournalStore interface {
+	Append(rec JournalRecord) error
+	// Records returns all records in the order they were appended.
+	Records() ([]JournalRecord, error)
+}
+
+// JournalKey identifies a bundle for a block independently of
+// how the relays calculate the bundle hash.
+func JournalKey(txsHex []string, blockNum uint64) (string, error) {
+	hash, err := BundleHash(txsHex)
+	if err != nil {
+		return "", err
+	}
+	return hash.Hex() + ":" + strconv.FormatUint(blockNum, 10), nil
+}
+
+// JournalSubmission is the submission of a bundle to a single relay.
+type JournalSubmission struct {
+	Time          time.Time
+	CorrelationID string
+	Relay         string
+	BundleHash    string
+	Response      json.RawMessage
+	Err           string
+}
+
+// JournalEntry is the history of a bundle for a block.
+type JournalEntry struct {
+	Key         string
+	BlockNum    uint64
+	TxHashes    []common.Hash
+	Submissions []JournalSubmission
+	// Status is empty until the inclusion is known.
+	Status SubmissionStatus
+}
+
+// Journal records every bundle submission and its eventual inclusion status
+// for audits and for debugging why a bundle never landed.
+// Add it to the relays with WithJournal and report the inclusion
+// with SetStatus or through TrackerConfig.Journal.
+type Journal struct {
+	store JournalStore
+}
+
+func NewJournal(store JournalStore) *Journal {
+	return &Journal{store: store}
+}
+
+// WithJournal records all SendBundle calls in the journal.
+// Failing to write the journal is logged and doesn't fail the submission.
+func WithJournal(journal *Journal) Option {
+	return func(f *Flashbot) {
+		f.journal = journal
+	}
+}
+
+// RecordSubmission appends a submission of the bundle to a relay.
+func (self *Journal) RecordSubmission(txsHex []string, blockNum uint64, sub JournalSubmission) error {
+	key, err := JournalKey(txsHex, blockNum)
+	if err != nil {
+		return err
+	}
+	hashes, err := txHashes(txsHex)
+	if err != nil {
+		return err
+	}
+	if sub.Time.IsZero() {
+		sub.Time = time.Now()
+	}
+	return self.store.Append(JournalRecord{
+		Time:          sub.Time,
+		Key:           key,
+		BlockNum:      blockNum,
+		TxHashes:      hashes,
+		CorrelationID: sub.CorrelationID,
+		Relay:         sub.Relay,
+		BundleHash:    sub.BundleHash,
+		Response:      sub.Response,
+		Err:           sub.Err,
+	})
+}
+
+// SetStatus records the inclusion status of the bundle.
+func (self *Journal) SetStatus(txsHex []string, blockNum uint64, status SubmissionStatus) error {
+	key, err := JournalKey(txsHex, blockNum)
+	if err != nil {
+		return err
+	}
+	return self.store.Append(JournalRecord{
+		Time:     time.Now(),
+		Key:      key,
+		BlockNum: blockNum,
+		Status:   status,
+	})
+}
+
+// Entries returns the bundles in the journal ordered by block and first submission.
+func (self *Journal) Entries() ([]*JournalEntry, error) {
+	recs, err := self.store.Records()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading journal records")
+	}
+
+	var entries []*JournalEntry
+	byKey := make(map[string]*JournalEntry)
+	for _, rec := range recs {
+		entry, ok := byKey[rec.Key]
+		if !ok {
+			entry = &JournalEntry{Key: rec.Key, BlockNum: rec.BlockNum}
+			byKey[rec.Key] = entry
+			entries = append(entries, entry)
+		}
+		if rec.TxHashes != nil {
+			entry.TxHashes = rec.TxHashes
+		}
+		if rec.Status != "" {
+			entry.Status = rec.Status
+			continue
+		}
+		entry.Submissions = append(entry.Submissions, JournalSubmission{
+			Time:          rec.Time,
+			CorrelationID: rec.CorrelationID,
+			Relay:         rec.Relay,
+			BundleHash:    rec.BundleHash,
+			Response:      rec.Response,
+			Err:           rec.Err,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].BlockNum < entries[j].BlockNum
+	})
+	return entries, nil
+}
+
+// Entry returns the history of the bundle for the block, nil when it isn't in the journal.
+func (self *Journal) Entry(txsHex []string, blockNum uint64) (*JournalEntry, error) {
+	key, err := JournalKey(txsHex, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := self.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.Key == key {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (self *Flashbot) journalSubmission(txsHex []string, blockNum uint64, correlationID string, resp *Response, sendErr error) {
+	sub := JournalSubmission{
+		CorrelationID: correlationID,
+		Relay:         self.Api().URL,
+	}
+	if resp != nil {
+		sub.BundleHash = resp.BundleHash
+		sub.Response = resp.Raw
+	}
+	if sendErr != nil {
+		sub.Err = sendErr.Error()
+	}
+	if err := self.journal.RecordSubmission(txsHex, blockNum, sub); err != nil {
+		level.Warn(self.logger).Log("msg", "writing journal", "correlationID", correlationID, "relay", self.Api().URL, "err", err)
+	}
+}
+
+func txHashes(txsHex []string) ([]common.Hash, error) {
+	hashes := make([]common.Hash, 0, len(txsHex))
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	return hashes, nil
+}
+
+// FileJournalStore appends the records to a file as JSON lines.
+type FileJournalStore struct {
+	mtx  sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileJournalStore opens or creates the journal file.
+func NewFileJournalStore(path string) (*FileJournalStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal file")
+	}
+	return &FileJournalStore{path: path, file: file}, nil
+}
+
+func (self *FileJournalStore) Append(rec JournalRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, "encoding journal record")
+	}
+	data = append(data, '\n')
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if _, err := self.file.Write(data); err != nil {
+		return errors.Wrap(err, "writing journal record")
+	}
+	return nil
+}
+
+func (self *FileJournalStore) Records() ([]JournalRecord, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	file, err := os.Open(self.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening journal file")
+	}
+	defer file.Close()
+
+	var recs []JournalRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), journalMaxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec JournalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, errors.Wrapf(err, "decoding journal record line:%v", line)
+		}
+		recs = append(recs, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading journal file")
+	}
+	return recs, nil
+}
+
+func (self *FileJournalStore) Close() error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.file.Close()
+}
+
+// MemoryJournalStore keeps the records in memory.
+type MemoryJournalStore struct {
+	mtx  sync.Mutex
+	recs []JournalRecord
+}
+
+func (self *MemoryJournalStore) Append(rec JournalRecord) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.recs = append(self.recs, rec)
+	return nil
+}
+
+func (self *MemoryJournalStore) Records() ([]JournalRecord, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return append([]JournalRecord(nil), self.recs...), nil
+}
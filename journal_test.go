@@ -0,0 +1,87 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestJournal(t *testing.T) {
+	newRelay := func(resp string, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, err := w.Write([]byte(resp))
+			testutil.Ok(t, err)
+		}))
+	}
+	srvOk := newRelay(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`, http.StatusOK)
+	defer srvOk.Close()
+	srvFail := newRelay(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"unknown"}}`, http.StatusOK)
+	defer srvFail.Close()
+
+	path := filepath.Join(t.TempDir(), "journal")
+	store, err := NewFileJournalStore(path)
+	testutil.Ok(t, err)
+	journal := NewJournal(store)
+
+	fbOk, err := New(nil, &Api{URL: srvOk.URL, SkipFlashbotsSignature: true}, WithJournal(journal))
+	testutil.Ok(t, err)
+	fbFail, err := New(nil, &Api{URL: srvFail.URL, SkipFlashbotsSignature: true}, WithJournal(journal))
+	testutil.Ok(t, err)
+
+	ctx := context.Background()
+	txs := []string{signedTxHex(t)}
+	_, err = fbOk.SendBundle(ctx, txs, 12, nil)
+	testutil.Ok(t, err)
+	_, err = fbFail.SendBundle(ctx, txs, 12, nil)
+	testutil.NotOk(t, err)
+	_, err = fbOk.SendBundle(ctx, txs, 11, nil)
+	testutil.Ok(t, err)
+
+	// The tracker records the status.
+	chain := &chainMock{head: 11, included: make(map[common.Hash]bool)}
+	chain.include(t, txs)
+	tracker := NewTracker(fbOk, chain, TrackerConfig{Journal: journal})
+	_, err = tracker.SendBundle(ctx, txs, 11, nil)
+	testutil.Ok(t, err)
+	testutil.Ok(t, tracker.Check(ctx))
+	testutil.Ok(t, store.Close())
+
+	// Read back from a reopened file.
+	store, err = NewFileJournalStore(path)
+	testutil.Ok(t, err)
+	defer store.Close()
+	entries, err := NewJournal(store).Entries()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(entries))
+
+	testutil.Equals(t, uint64(11), entries[0].BlockNum)
+	testutil.Equals(t, StatusIncluded, entries[0].Status)
+	testutil.Equals(t, 2, len(entries[0].Submissions))
+
+	e := entries[1]
+	testutil.Equals(t, uint64(12), e.BlockNum)
+	testutil.Equals(t, SubmissionStatus(""), e.Status)
+	tx, err := decodeTx(txs[0])
+	testutil.Ok(t, err)
+	testutil.Equals(t, []common.Hash{tx.Hash()}, e.TxHashes)
+	testutil.Equals(t, 2, len(e.Submissions))
+	testutil.Equals(t, srvOk.URL, e.Submissions[0].Relay)
+	testutil.Equals(t, "0x1", e.Submissions[0].BundleHash)
+	testutil.Assert(t, len(e.Submissions[0].Response) > 0, "response should be recorded")
+	testutil.Assert(t, e.Submissions[0].CorrelationID != "", "correlation id should be recorded")
+	testutil.Equals(t, srvFail.URL, e.Submissions[1].Relay)
+	testutil.Assert(t, e.Submissions[1].Err != "", "error should be recorded")
+
+	entry, err := NewJournal(store).Entry(txs, 12)
+	testutil.Ok(t, err)
+	testutil.Equals(t, e.Key, entry.Key)
+}
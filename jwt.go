@@ -0,0 +1,78 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+)
+
+// jwtTTLDefault is the expiry set on the tokens, the relays check the issued-at time with their own window.
+const jwtTTLDefault = 5 * time.Second
+
+// JWTAuth generates and caches the bearer tokens sent to relays that authenticate with JWTs.
+// Tokens are regenerated once half of their lifetime has passed.
+type JWTAuth struct {
+	// Method defaults to HS256 which is used by the engine API.
+	Method jwt.SigningMethod
+	// Key is the shared secret for HMAC methods or
+	// the private key for asymmetric methods like ES256 or RS256.
+	Key interface{}
+	// TTL sets the token expiry, defaults to 5 seconds.
+	TTL     time.Duration
+	Issuer  string
+	Subject string
+
+	mtx      sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewJWTAuthHS256 creates engine API style authentication with a shared secret.
+func NewJWTAuthHS256(secret []byte) (*JWTAuth, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("jwt secret can't be empty")
+	}
+	return &JWTAuth{Method: jwt.SigningMethodHS256, Key: secret}, nil
+}
+
+// Token returns a valid token, refreshing it when needed.
+func (self *JWTAuth) Token() (string, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	ttl := self.TTL
+	if ttl == 0 {
+		ttl = jwtTTLDefault
+	}
+
+	now := time.Now()
+	if self.token != "" && now.Sub(self.issuedAt) < ttl/2 {
+		return self.token, nil
+	}
+
+	method := self.Method
+	if method == nil {
+		method = jwt.SigningMethodHS256
+	}
+
+	claims := jwt.RegisteredClaims{
+		Issuer:    self.Issuer,
+		Subject:   self.Subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	token, err := jwt.NewWithClaims(method, claims).SignedString(self.Key)
+	if err != nil {
+		return "", errors.Wrap(err, "signing jwt")
+	}
+
+	self.token = token
+	self.issuedAt = now
+
+	return token, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestJWTAuth(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Flashbots-Signature") != "" {
+			http.Error(w, "unexpected signature", http.StatusBadRequest)
+			return
+		}
+		_, err := jwt.Parse(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), func(*jwt.Token) (interface{}, error) {
+			return secret, nil
+		}, jwt.WithValidMethods([]string{"HS256"}))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":true}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	auth, err := NewJWTAuthHS256(secret)
+	testutil.Ok(t, err)
+
+	// No key is needed when the flashbots signature is skipped.
+	flashbot, err := New(nil, &Api{URL: srv.URL, JWT: auth, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	resp, err := flashbot.CancelPrivateTransaction(context.Background(), common.HexToHash("0"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, resp.Result, "resp.Result didn't return true")
+
+	token, err := auth.Token()
+	testutil.Ok(t, err)
+	tokenCached, err := auth.Token()
+	testutil.Ok(t, err)
+	testutil.Equals(t, token, tokenCached)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// TextSigner is implemented by signers which only sign text messages
+// with the EIP-191 personal message prefix instead of arbitrary digests.
+// The request signatures use SignText when the signer implements it.
+type TextSigner interface {
+	SignText(text []byte) ([]byte, error)
+}
+
+// NewKeystoreSigner decrypts a go-ethereum keystore key file.
+func NewKeystoreSigner(keyJSON []byte, passphrase string) (Signer, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypting keystore key")
+	}
+	return NewECDSASigner(key.PrivateKey)
+}
+
+// LoadKeystoreSigner reads and decrypts a go-ethereum keystore key file.
+func LoadKeystoreSigner(path, passphrase string) (Signer, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading keystore file:%v", path)
+	}
+	return NewKeystoreSigner(keyJSON, passphrase)
+}
+
+type clefSigner struct {
+	clef    *external.ExternalSigner
+	account accounts.Account
+}
+
+// NewClefSigner signs the requests with the given account of a Clef instance,
+// for example NewClefSigner("http://localhost:8550", addr).
+// Every request needs to be approved by the Clef rules or the operator.
+func NewClefSigner(endpoint string, addr common.Address) (Signer, error) {
+	clef, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to clef:%v", endpoint)
+	}
+	return &clefSigner{clef: clef, account: accounts.Account{Address: addr}}, nil
+}
+
+func (self *clefSigner) Address() common.Address {
+	return self.account.Address
+}
+
+func (self *clefSigner) Sign(digest []byte) ([]byte, error) {
+	return nil, errors.New("clef doesn't sign raw digests")
+}
+
+func (self *clefSigner) SignText(text []byte) ([]byte, error) {
+	sig, err := self.clef.SignText(self.account, text)
+	if err != nil {
+		return nil, errors.Wrap(err, "clef signing")
+	}
+	return sig, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+func TestKeystoreSigner(t *testing.T) {
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	addr := crypto.PubkeyToAddress(prvKey.PublicKey)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{
+		Id:         uuid.New(),
+		Address:    addr,
+		PrivateKey: prvKey,
+	}, "pass", keystore.LightScryptN, keystore.LightScryptP)
+	testutil.Ok(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.json")
+	testutil.Ok(t, os.WriteFile(path, keyJSON, 0600))
+
+	signer, err := LoadKeystoreSigner(path, "pass")
+	testutil.Ok(t, err)
+	testutil.Equals(t, addr, signer.Address())
+
+	_, err = LoadKeystoreSigner(path, "wrong")
+	testutil.NotOk(t, err)
+}
+
+type fakeClef struct {
+	prvKey *ecdsa.PrivateKey
+}
+
+func (self *fakeClef) Version() string {
+	return "6.1.0"
+}
+
+func (self *fakeClef) SignData(mimeType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	if mimeType != accounts.MimetypeTextPlain {
+		return nil, errors.Errorf("unexpected mime type:%v", mimeType)
+	}
+	if addr.Address() != crypto.PubkeyToAddress(self.prvKey.PublicKey) {
+		return nil, errors.Errorf("unknown account:%v", addr.Address())
+	}
+	sig, err := crypto.Sign(accounts.TextHash(data), self.prvKey)
+	if err != nil {
+		return nil, err
+	}
+	// Clef returns the signatures with the legacy V.
+	sig[64] += 27
+	return sig, nil
+}
+
+func TestClefSigner(t *testing.T) {
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	addr := crypto.PubkeyToAddress(prvKey.PublicKey)
+
+	rpcSrv := rpc.NewServer()
+	testutil.Ok(t, rpcSrv.RegisterName("account", &fakeClef{prvKey: prvKey}))
+	clef := httptest.NewServer(rpcSrv)
+	defer clef.Close()
+
+	var signer common.Address
+	srv := newSignatureServer(t, &signer)
+	defer srv.Close()
+
+	clefSigner, err := NewClefSigner(clef.URL, addr)
+	testutil.Ok(t, err)
+	flashbot, err := New(nil, &Api{URL: srv.URL}, WithSigner(clefSigner))
+	testutil.Ok(t, err)
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, addr, signer)
+}
@@ -0,0 +1,275 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Package localsim simulates bundles with the go-ethereum EVM against state
+// fetched lazily from a node. It returns the same result as the relay eth_callBundle
+// which allows running an unlimited number of simulations without hitting relay rate limits.
+package localsim
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"sync"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+// Average block time used to set the timestamp of the simulated block.
+const blockTime = 12
+
+// Backend is the subset of the ethclient.Client methods used by the simulator.
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+// Simulator executes bundles locally on top of the state of a given block.
+// The fetched state of the most recently used block is cached between calls.
+type Simulator struct {
+	backend Backend
+
+	mtx    sync.Mutex
+	config *params.ChainConfig
+	cache  *remoteState
+}
+
+var _ flashbot.BundleSimulator = &Simulator{}
+
+func New(backend Backend) *Simulator {
+	return &Simulator{backend: backend}
+}
+
+// CallBundle simulates the bundle in the block after blockNumState,
+// 0 uses the latest block as the state.
+// The block context can be overridden with the opts same as with the relay.
+// Same as with the relay transactions that revert don't return an error,
+// the revert reason is reported in the TxResult and Response.RevertErr returns it.
+func (self *Simulator) CallBundle(ctx context.Context, txsHex []string, blockNumState uint64, opts *flashbot.CallBundleOpts) (*flashbot.Response, error) {
+	var number *big.Int
+	if blockNumState != 0 {
+		number = new(big.Int).SetUint64(blockNumState)
+	}
+	parent, err := self.backend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching state block header")
+	}
+	config, err := self.chainConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*types.Transaction
+	for i, txHex := range txsHex {
+		raw, err := hexutil.Decode(txHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding tx hex index:%v", i)
+		}
+		tx := &types.Transaction{}
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, errors.Wrapf(err, "decoding tx index:%v", i)
+		}
+		txs = append(txs, tx)
+	}
+
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+		Time:       parent.Time + blockTime,
+		GasLimit:   parent.GasLimit,
+		Coinbase:   parent.Coinbase,
+		Difficulty: parent.Difficulty,
+		MixDigest:  parent.MixDigest,
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(config, parent)
+	}
+	if opts != nil {
+		if opts.TargetBlockNum != 0 {
+			header.Number = new(big.Int).SetUint64(opts.TargetBlockNum)
+		}
+		if opts.Timestamp != 0 {
+			header.Time = opts.Timestamp
+		}
+		if opts.GasLimit != 0 {
+			header.GasLimit = opts.GasLimit
+		}
+		if opts.Difficulty != nil {
+			header.Difficulty = opts.Difficulty
+		}
+		if opts.BaseFee != nil {
+			header.BaseFee = opts.BaseFee
+		}
+		if opts.Coinbase != nil {
+			header.Coinbase = *opts.Coinbase
+		}
+	}
+
+	statedb := newStateDB(ctx, self.remoteState(parent.Number))
+	if opts != nil && len(opts.StateOverrides) > 0 {
+		if err := opts.StateOverrides.Validate(); err != nil {
+			return nil, err
+		}
+		statedb.applyOverrides(opts.StateOverrides)
+		if statedb.err != nil {
+			return nil, errors.Wrap(statedb.err, "fetching overridden state")
+		}
+	}
+	blockCtx := self.blockContext(ctx, header)
+	signer := types.MakeSigner(config, header.Number)
+	gasPool := new(core.GasPool).AddGas(header.GasLimit)
+
+	resp := &flashbot.Response{}
+	var (
+		hashes    []byte
+		totalGas  uint64
+		totalDiff = big.NewInt(0)
+		totalFees = big.NewInt(0)
+	)
+	for i, tx := range txs {
+		msg, err := tx.AsMessage(signer, header.BaseFee)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating message tx index:%v", i)
+		}
+
+		coinbaseBefore := statedb.GetBalance(header.Coinbase)
+
+		evm := vm.NewEVM(blockCtx, core.NewEVMTxContext(msg), statedb, config, vm.Config{})
+		result, err := core.ApplyMessage(evm, msg, gasPool)
+		if statedb.err != nil {
+			return nil, errors.Wrapf(statedb.err, "fetching state tx index:%v", i)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "applying tx index:%v hash:%v", i, tx.Hash())
+		}
+		statedb.finalise()
+
+		tip, err := tx.EffectiveGasTip(header.BaseFee)
+		if err != nil {
+			return nil, errors.Wrapf(err, "calculating tip tx index:%v", i)
+		}
+		gasUsed := new(big.Int).SetUint64(result.UsedGas)
+		fees := new(big.Int).Mul(tip, gasUsed)
+		diff := new(big.Int).Sub(statedb.GetBalance(header.Coinbase), coinbaseBefore)
+
+		txResult := flashbot.TxResult{
+			Metadata: flashbot.Metadata{
+				CoinbaseDiff:      diff.String(),
+				EthSentToCoinbase: new(big.Int).Sub(diff, fees).String(),
+				GasFees:           fees.String(),
+			},
+			FromAddress: msg.From().Hex(),
+			GasPrice:    new(big.Int).Div(diff, gasUsed).String(),
+			TxHash:      tx.Hash().Hex(),
+			GasUsed:     result.UsedGas,
+		}
+		if result.Err != nil {
+			txResult.Error = result.Err.Error()
+			txResult.Revert = revertReason(result.Revert())
+		}
+		resp.Results = append(resp.Results, txResult)
+
+		hashes = append(hashes, tx.Hash().Bytes()...)
+		totalGas += result.UsedGas
+		totalDiff.Add(totalDiff, diff)
+		totalFees.Add(totalFees, fees)
+	}
+
+	resp.BundleHash = crypto.Keccak256Hash(hashes).Hex()
+	resp.CoinbaseDiff = totalDiff.String()
+	resp.GasFees = totalFees.String()
+	resp.EthSentToCoinbase = new(big.Int).Sub(totalDiff, totalFees).String()
+	resp.BundleGasPrice = "0"
+	if totalGas > 0 {
+		resp.BundleGasPrice = new(big.Int).Div(totalDiff, new(big.Int).SetUint64(totalGas)).String()
+	}
+
+	return resp, nil
+}
+
+func (self *Simulator) chainConfig(ctx context.Context) (*params.ChainConfig, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if self.config != nil {
+		return self.config, nil
+	}
+
+	chainID, err := self.backend.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetching chain id")
+	}
+	switch chainID.Int64() {
+	case 1:
+		self.config = params.MainnetChainConfig
+	case 5:
+		self.config = params.GoerliChainConfig
+	default:
+		config := *params.AllEthashProtocolChanges
+		config.ChainID = chainID
+		self.config = &config
+	}
+	return self.config, nil
+}
+
+// remoteState returns the cached state for the block or
+// replaces the cache when the block has changed.
+func (self *Simulator) remoteState(number *big.Int) *remoteState {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if self.cache == nil || self.cache.blockNum.Cmp(number) != 0 {
+		self.cache = newRemoteState(self.backend, number)
+	}
+	return self.cache
+}
+
+func (self *Simulator) blockContext(ctx context.Context, header *types.Header) vm.BlockContext {
+	blockCtx := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash: func(n uint64) common.Hash {
+			h, err := self.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+			if err != nil {
+				return common.Hash{}
+			}
+			return h.Hash()
+		},
+		Coinbase:    header.Coinbase,
+		GasLimit:    header.GasLimit,
+		BlockNumber: header.Number,
+		Time:        new(big.Int).SetUint64(header.Time),
+		Difficulty:  header.Difficulty,
+		BaseFee:     header.BaseFee,
+	}
+	if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+		random := header.MixDigest
+		blockCtx.Random = &random
+		blockCtx.Difficulty = big.NewInt(0)
+	}
+	return blockCtx
+}
+
+func revertReason(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	return "0x" + hex.EncodeToString(data)
+}
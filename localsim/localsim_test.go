@@ -0,0 +1,229 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package localsim
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type backendMock struct {
+	header   *types.Header
+	balances map[common.Address]*big.Int
+	codes    map[common.Address][]byte
+	calls    int
+}
+
+func (self *backendMock) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(1337), nil
+}
+
+func (self *backendMock) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return self.header, nil
+}
+
+func (self *backendMock) BalanceAt(_ context.Context, addr common.Address, _ *big.Int) (*big.Int, error) {
+	self.calls++
+	if b, ok := self.balances[addr]; ok {
+		return b, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (self *backendMock) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return 0, nil
+}
+
+func (self *backendMock) CodeAt(_ context.Context, addr common.Address, _ *big.Int) ([]byte, error) {
+	return self.codes[addr], nil
+}
+
+func (self *backendMock) StorageAt(context.Context, common.Address, common.Hash, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func TestCallBundle(t *testing.T) {
+	ctx := context.Background()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	sender := crypto.PubkeyToAddress(privKey.PublicKey)
+	coinbase := common.HexToAddress("0xc0ffee")
+	reverter := common.HexToAddress("0xbad")
+
+	backend := &backendMock{
+		header: &types.Header{
+			Number:     big.NewInt(100),
+			GasLimit:   30_000_000,
+			GasUsed:    15_000_000,
+			BaseFee:    big.NewInt(params.GWei),
+			Difficulty: big.NewInt(1),
+			Coinbase:   coinbase,
+		},
+		balances: map[common.Address]*big.Int{sender: big.NewInt(0).Mul(big.NewInt(params.Ether), big.NewInt(10))},
+		// PUSH1 0 PUSH1 0 REVERT
+		codes: map[common.Address][]byte{reverter: common.FromHex("0x60006000fd")},
+	}
+
+	signer := types.LatestSignerForChainID(big.NewInt(1337))
+	newTx := func(nonce uint64, to common.Address, value *big.Int) string {
+		tx, err := types.SignNewTx(privKey, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1337),
+			Nonce:     nonce,
+			To:        &to,
+			Value:     value,
+			Gas:       100_000,
+			GasTipCap: big.NewInt(2 * params.GWei),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+		})
+		testutil.Ok(t, err)
+		raw, err := tx.MarshalBinary()
+		testutil.Ok(t, err)
+		return hexutil.Encode(raw)
+	}
+
+	sim := New(backend)
+	resp, err := sim.CallBundle(ctx, []string{
+		newTx(0, coinbase, big.NewInt(params.Ether)),
+		newTx(1, reverter, big.NewInt(0)),
+	}, 0, nil)
+	testutil.Ok(t, err)
+
+	testutil.Equals(t, 2, len(resp.Results))
+	testutil.Equals(t, uint64(21000), resp.Results[0].GasUsed)
+	testutil.Equals(t, "", resp.Results[0].Error)
+	testutil.Equals(t, big.NewInt(0).Add(big.NewInt(params.Ether), big.NewInt(21000*2*params.GWei)).String(), resp.Results[0].CoinbaseDiff)
+	testutil.Equals(t, big.NewInt(params.Ether).String(), resp.Results[0].EthSentToCoinbase)
+	testutil.Assert(t, resp.Results[1].Error != "", "second tx should revert")
+
+	// The state of the same block is cached.
+	calls := backend.calls
+	_, err = sim.CallBundle(ctx, []string{newTx(0, coinbase, big.NewInt(1))}, 0, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, calls, backend.calls)
+
+	// With the coinbase override the transfer to the original coinbase isn't counted.
+	otherCoinbase := common.HexToAddress("0xbeef")
+	resp, err = sim.CallBundle(ctx, []string{newTx(0, coinbase, big.NewInt(1))}, 0, &flashbot.CallBundleOpts{Coinbase: &otherCoinbase})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0", resp.EthSentToCoinbase)
+	testutil.Equals(t, big.NewInt(21000*2*params.GWei).String(), resp.CoinbaseDiff)
+
+	// Overriding the code of the reverting contract with STOP makes the tx succeed.
+	stop := hexutil.Bytes{0x00}
+	resp, err = sim.CallBundle(ctx, []string{newTx(0, reverter, big.NewInt(0))}, 0, &flashbot.CallBundleOpts{
+		StateOverrides: flashbot.StateOverride{reverter: {Code: &stop}},
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", resp.Results[0].Error)
+
+	// Nonce mismatch is an error like on the relay.
+	_, err = sim.CallBundle(ctx, []string{newTx(1, coinbase, big.NewInt(1))}, 0, nil)
+	testutil.NotOk(t, err)
+}
+
+func TestCoinbaseBribe(t *testing.T) {
+	ctx := context.Background()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	sender := crypto.PubkeyToAddress(privKey.PublicKey)
+	coinbase := common.HexToAddress("0xc0ffee")
+	rejecter := common.HexToAddress("0xbad")
+
+	backend := &backendMock{
+		header: &types.Header{
+			Number:     big.NewInt(100),
+			GasLimit:   30_000_000,
+			GasUsed:    15_000_000,
+			BaseFee:    big.NewInt(params.GWei),
+			Difficulty: big.NewInt(1),
+			Coinbase:   coinbase,
+		},
+		balances: map[common.Address]*big.Int{
+			sender:   big.NewInt(0).Mul(big.NewInt(params.Ether), big.NewInt(10)),
+			coinbase: big.NewInt(1),
+		},
+		// PUSH1 0 PUSH1 0 REVERT
+		codes: map[common.Address][]byte{rejecter: common.FromHex("0x60006000fd")},
+	}
+
+	bribe := &flashbot.CoinbaseBribe{
+		ChainID:   big.NewInt(1337),
+		Amount:    big.NewInt(params.Ether),
+		GasTipCap: big.NewInt(0),
+		GasFeeCap: big.NewInt(10 * params.GWei),
+	}
+	res, err := flashbot.SimulateCoinbaseBribe(ctx, New(backend), nil, bribe, privKey, 0)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(res.Txs))
+	testutil.Equals(t, big.NewInt(params.Ether), res.Paid)
+	testutil.Equals(t, big.NewInt(params.Ether), res.CoinbaseDiff)
+
+	// A coinbase that rejects transfers reverts the bribe instead of keeping the value.
+	backend.header.Coinbase = rejecter
+	_, err = flashbot.SimulateCoinbaseBribe(ctx, New(backend), nil, bribe, privKey, 0)
+	testutil.NotOk(t, err)
+}
+
+func TestCallBundleSelfDestruct(t *testing.T) {
+	ctx := context.Background()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	sender := crypto.PubkeyToAddress(privKey.PublicKey)
+	contract := common.HexToAddress("0xdead")
+
+	backend := &backendMock{
+		header: &types.Header{
+			Number:     big.NewInt(100),
+			GasLimit:   30_000_000,
+			GasUsed:    15_000_000,
+			BaseFee:    big.NewInt(params.GWei),
+			Difficulty: big.NewInt(1),
+			Coinbase:   common.HexToAddress("0xc0ffee"),
+		},
+		balances: map[common.Address]*big.Int{
+			sender:   big.NewInt(0).Mul(big.NewInt(params.Ether), big.NewInt(10)),
+			contract: big.NewInt(params.Ether),
+		},
+		// Without calldata SELFDESTRUCT to the zero address otherwise REVERT.
+		// CALLDATASIZE PUSH1 7 JUMPI PUSH1 0 SELFDESTRUCT JUMPDEST PUSH1 0 PUSH1 0 REVERT
+		codes: map[common.Address][]byte{contract: common.FromHex("0x366007576000ff5b60006000fd")},
+	}
+
+	signer := types.LatestSignerForChainID(big.NewInt(1337))
+	newTx := func(nonce uint64, data []byte) string {
+		tx, err := types.SignNewTx(privKey, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1337),
+			Nonce:     nonce,
+			To:        &contract,
+			Data:      data,
+			Gas:       100_000,
+			GasTipCap: big.NewInt(2 * params.GWei),
+			GasFeeCap: big.NewInt(10 * params.GWei),
+		})
+		testutil.Ok(t, err)
+		raw, err := tx.MarshalBinary()
+		testutil.Ok(t, err)
+		return hexutil.Encode(raw)
+	}
+
+	// The call after the destruction doesn't run the code of the contract anymore.
+	resp, err := New(backend).CallBundle(ctx, []string{newTx(0, nil), newTx(1, []byte{1})}, 0, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(resp.Results))
+	testutil.Equals(t, "", resp.Results[0].Error)
+	testutil.Equals(t, "", resp.Results[1].Error)
+	testutil.Equals(t, uint64(21016), resp.Results[1].GasUsed)
+}
@@ -0,0 +1,462 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package localsim
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// remoteState caches the state of a single block as it is fetched from the node
+// so that repeated simulations on the same block don't refetch it.
+type remoteState struct {
+	backend  Backend
+	blockNum *big.Int
+
+	mtx      sync.Mutex
+	accounts map[common.Address]*remoteAccount
+	storage  map[common.Address]map[common.Hash]common.Hash
+}
+
+type remoteAccount struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+}
+
+func newRemoteState(backend Backend, blockNum *big.Int) *remoteState {
+	return &remoteState{
+		backend:  backend,
+		blockNum: blockNum,
+		accounts: make(map[common.Address]*remoteAccount),
+		storage:  make(map[common.Address]map[common.Hash]common.Hash),
+	}
+}
+
+func (self *remoteState) account(ctx context.Context, addr common.Address) (*remoteAccount, error) {
+	self.mtx.Lock()
+	acc, ok := self.accounts[addr]
+	self.mtx.Unlock()
+	if ok {
+		return acc, nil
+	}
+
+	balance, err := self.backend.BalanceAt(ctx, addr, self.blockNum)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching balance addr:%v", addr)
+	}
+	nonce, err := self.backend.NonceAt(ctx, addr, self.blockNum)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching nonce addr:%v", addr)
+	}
+	code, err := self.backend.CodeAt(ctx, addr, self.blockNum)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching code addr:%v", addr)
+	}
+	acc = &remoteAccount{balance: balance, nonce: nonce, code: code}
+
+	self.mtx.Lock()
+	self.accounts[addr] = acc
+	self.mtx.Unlock()
+
+	return acc, nil
+}
+
+func (self *remoteState) slot(ctx context.Context, addr common.Address, key common.Hash) (common.Hash, error) {
+	self.mtx.Lock()
+	val, ok := self.storage[addr][key]
+	self.mtx.Unlock()
+	if ok {
+		return val, nil
+	}
+
+	raw, err := self.backend.StorageAt(ctx, addr, key, self.blockNum)
+	if err != nil {
+		return common.Hash{}, errors.Wrapf(err, "fetching storage addr:%v key:%v", addr, key)
+	}
+	val = common.BytesToHash(raw)
+
+	self.mtx.Lock()
+	if self.storage[addr] == nil {
+		self.storage[addr] = make(map[common.Hash]common.Hash)
+	}
+	self.storage[addr][key] = val
+	self.mtx.Unlock()
+
+	return val, nil
+}
+
+type stateAccount struct {
+	balance  *big.Int
+	nonce    uint64
+	code     []byte
+	codeHash common.Hash
+	// origin holds the storage values at the start of the current transaction.
+	origin   map[common.Hash]common.Hash
+	dirty    map[common.Hash]common.Hash
+	created  bool
+	suicided bool
+	// storageOverridden stops fetching the storage from the node
+	// after the whole storage was replaced with a state override
+	// or the account was destroyed.
+	storageOverridden bool
+}
+
+func (self *stateAccount) empty() bool {
+	return self.nonce == 0 && self.balance.Sign() == 0 && self.codeHash == emptyCodeHash
+}
+
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+// stateDB implements vm.StateDB on top of the lazily fetched remote state.
+// All changes are kept in memory and rolled back through a journal of undo functions.
+type stateDB struct {
+	ctx    context.Context
+	remote *remoteState
+	// err records the first failure to fetch remote state,
+	// the vm.StateDB interface has no way to return errors.
+	err error
+
+	accounts  map[common.Address]*stateAccount
+	refund    uint64
+	logs      []*types.Log
+	journal   []func()
+	accessAdr map[common.Address]bool
+	accessSlt map[common.Address]map[common.Hash]bool
+}
+
+func newStateDB(ctx context.Context, remote *remoteState) *stateDB {
+	return &stateDB{
+		ctx:       ctx,
+		remote:    remote,
+		accounts:  make(map[common.Address]*stateAccount),
+		accessAdr: make(map[common.Address]bool),
+		accessSlt: make(map[common.Address]map[common.Hash]bool),
+	}
+}
+
+func (self *stateDB) getAccount(addr common.Address) *stateAccount {
+	if acc, ok := self.accounts[addr]; ok {
+		return acc
+	}
+	acc := &stateAccount{
+		balance:  big.NewInt(0),
+		codeHash: emptyCodeHash,
+		origin:   make(map[common.Hash]common.Hash),
+		dirty:    make(map[common.Hash]common.Hash),
+	}
+	remote, err := self.remote.account(self.ctx, addr)
+	if err != nil {
+		if self.err == nil {
+			self.err = err
+		}
+	} else {
+		acc.balance = new(big.Int).Set(remote.balance)
+		acc.nonce = remote.nonce
+		acc.code = remote.code
+		acc.codeHash = crypto.Keccak256Hash(remote.code)
+	}
+	self.accounts[addr] = acc
+	return acc
+}
+
+// applyOverrides replaces the state of the accounts before the first transaction.
+// The changes are committed so that they can't be reverted by the transactions.
+func (self *stateDB) applyOverrides(overrides flashbot.StateOverride) {
+	for addr, override := range overrides {
+		acc := self.getAccount(addr)
+		if override.Nonce != nil {
+			acc.nonce = uint64(*override.Nonce)
+		}
+		if override.Code != nil {
+			acc.code = *override.Code
+			acc.codeHash = crypto.Keccak256Hash(acc.code)
+		}
+		if override.Balance != nil {
+			acc.balance = new(big.Int).Set(override.Balance.ToInt())
+		}
+		if override.State != nil {
+			acc.origin = make(map[common.Hash]common.Hash)
+			acc.storageOverridden = true
+			for k, v := range override.State {
+				acc.origin[k] = v
+			}
+		}
+		for k, v := range override.StateDiff {
+			acc.origin[k] = v
+		}
+	}
+}
+
+// finalise is called between transactions so that
+// the committed storage reflects the changes of the previous ones.
+// Like in geth the accounts destroyed by the previous transactions are removed
+// so the later ones see them without code, balance and storage.
+func (self *stateDB) finalise() {
+	for addr, acc := range self.accounts {
+		if acc.suicided {
+			self.accounts[addr] = &stateAccount{
+				balance:           big.NewInt(0),
+				codeHash:          emptyCodeHash,
+				origin:            make(map[common.Hash]common.Hash),
+				dirty:             make(map[common.Hash]common.Hash),
+				storageOverridden: true,
+			}
+			continue
+		}
+		for k, v := range acc.dirty {
+			acc.origin[k] = v
+		}
+		acc.dirty = make(map[common.Hash]common.Hash)
+	}
+	self.refund = 0
+	self.logs = nil
+	self.journal = nil
+	self.accessAdr = make(map[common.Address]bool)
+	self.accessSlt = make(map[common.Address]map[common.Hash]bool)
+}
+
+func (self *stateDB) CreateAccount(addr common.Address) {
+	prev, existed := self.accounts[addr]
+	balance := big.NewInt(0)
+	if existed {
+		// Like geth keep the balance when overwriting an existing account.
+		balance = prev.balance
+	} else {
+		balance = self.getAccount(addr).balance
+	}
+	self.accounts[addr] = &stateAccount{
+		balance:  new(big.Int).Set(balance),
+		codeHash: emptyCodeHash,
+		origin:   make(map[common.Hash]common.Hash),
+		dirty:    make(map[common.Hash]common.Hash),
+		created:  true,
+	}
+	self.journal = append(self.journal, func() {
+		if existed {
+			self.accounts[addr] = prev
+		} else {
+			delete(self.accounts, addr)
+		}
+	})
+}
+
+func (self *stateDB) SubBalance(addr common.Address, amount *big.Int) {
+	self.setBalance(addr, new(big.Int).Sub(self.GetBalance(addr), amount))
+}
+
+func (self *stateDB) AddBalance(addr common.Address, amount *big.Int) {
+	self.setBalance(addr, new(big.Int).Add(self.GetBalance(addr), amount))
+}
+
+func (self *stateDB) setBalance(addr common.Address, amount *big.Int) {
+	acc := self.getAccount(addr)
+	prev := acc.balance
+	acc.balance = amount
+	self.journal = append(self.journal, func() { acc.balance = prev })
+}
+
+func (self *stateDB) GetBalance(addr common.Address) *big.Int {
+	return new(big.Int).Set(self.getAccount(addr).balance)
+}
+
+func (self *stateDB) GetNonce(addr common.Address) uint64 {
+	return self.getAccount(addr).nonce
+}
+
+func (self *stateDB) SetNonce(addr common.Address, nonce uint64) {
+	acc := self.getAccount(addr)
+	prev := acc.nonce
+	acc.nonce = nonce
+	self.journal = append(self.journal, func() { acc.nonce = prev })
+}
+
+func (self *stateDB) GetCodeHash(addr common.Address) common.Hash {
+	acc := self.getAccount(addr)
+	if acc.empty() && !acc.created {
+		return common.Hash{}
+	}
+	return acc.codeHash
+}
+
+func (self *stateDB) GetCode(addr common.Address) []byte {
+	return self.getAccount(addr).code
+}
+
+func (self *stateDB) SetCode(addr common.Address, code []byte) {
+	acc := self.getAccount(addr)
+	prevCode, prevHash := acc.code, acc.codeHash
+	acc.code = code
+	acc.codeHash = crypto.Keccak256Hash(code)
+	self.journal = append(self.journal, func() { acc.code, acc.codeHash = prevCode, prevHash })
+}
+
+func (self *stateDB) GetCodeSize(addr common.Address) int {
+	return len(self.getAccount(addr).code)
+}
+
+func (self *stateDB) AddRefund(gas uint64) {
+	prev := self.refund
+	self.refund += gas
+	self.journal = append(self.journal, func() { self.refund = prev })
+}
+
+func (self *stateDB) SubRefund(gas uint64) {
+	prev := self.refund
+	if gas > self.refund {
+		gas = self.refund
+	}
+	self.refund -= gas
+	self.journal = append(self.journal, func() { self.refund = prev })
+}
+
+func (self *stateDB) GetRefund() uint64 {
+	return self.refund
+}
+
+func (self *stateDB) GetCommittedState(addr common.Address, key common.Hash) common.Hash {
+	acc := self.getAccount(addr)
+	if val, ok := acc.origin[key]; ok {
+		return val
+	}
+	if acc.created || acc.storageOverridden {
+		return common.Hash{}
+	}
+	val, err := self.remote.slot(self.ctx, addr, key)
+	if err != nil && self.err == nil {
+		self.err = err
+	}
+	acc.origin[key] = val
+	return val
+}
+
+func (self *stateDB) GetState(addr common.Address, key common.Hash) common.Hash {
+	acc := self.getAccount(addr)
+	if val, ok := acc.dirty[key]; ok {
+		return val
+	}
+	return self.GetCommittedState(addr, key)
+}
+
+func (self *stateDB) SetState(addr common.Address, key, value common.Hash) {
+	acc := self.getAccount(addr)
+	prev, ok := acc.dirty[key]
+	acc.dirty[key] = value
+	self.journal = append(self.journal, func() {
+		if ok {
+			acc.dirty[key] = prev
+		} else {
+			delete(acc.dirty, key)
+		}
+	})
+}
+
+func (self *stateDB) Suicide(addr common.Address) bool {
+	acc := self.getAccount(addr)
+	prevSuicided, prevBalance := acc.suicided, acc.balance
+	acc.suicided = true
+	acc.balance = big.NewInt(0)
+	self.journal = append(self.journal, func() { acc.suicided, acc.balance = prevSuicided, prevBalance })
+	return true
+}
+
+func (self *stateDB) HasSuicided(addr common.Address) bool {
+	return self.getAccount(addr).suicided
+}
+
+func (self *stateDB) Exist(addr common.Address) bool {
+	acc := self.getAccount(addr)
+	return acc.created || acc.suicided || !acc.empty()
+}
+
+func (self *stateDB) Empty(addr common.Address) bool {
+	return self.getAccount(addr).empty()
+}
+
+func (self *stateDB) PrepareAccessList(sender common.Address, dest *common.Address, precompiles []common.Address, txAccesses types.AccessList) {
+	self.AddAddressToAccessList(sender)
+	if dest != nil {
+		self.AddAddressToAccessList(*dest)
+	}
+	for _, addr := range precompiles {
+		self.AddAddressToAccessList(addr)
+	}
+	for _, el := range txAccesses {
+		self.AddAddressToAccessList(el.Address)
+		for _, key := range el.StorageKeys {
+			self.AddSlotToAccessList(el.Address, key)
+		}
+	}
+}
+
+func (self *stateDB) AddressInAccessList(addr common.Address) bool {
+	return self.accessAdr[addr]
+}
+
+func (self *stateDB) SlotInAccessList(addr common.Address, slot common.Hash) (bool, bool) {
+	return self.accessAdr[addr], self.accessSlt[addr][slot]
+}
+
+func (self *stateDB) AddAddressToAccessList(addr common.Address) {
+	if self.accessAdr[addr] {
+		return
+	}
+	self.accessAdr[addr] = true
+	self.journal = append(self.journal, func() { delete(self.accessAdr, addr) })
+}
+
+func (self *stateDB) AddSlotToAccessList(addr common.Address, slot common.Hash) {
+	self.AddAddressToAccessList(addr)
+	if self.accessSlt[addr] == nil {
+		self.accessSlt[addr] = make(map[common.Hash]bool)
+	}
+	if self.accessSlt[addr][slot] {
+		return
+	}
+	self.accessSlt[addr][slot] = true
+	self.journal = append(self.journal, func() { delete(self.accessSlt[addr], slot) })
+}
+
+func (self *stateDB) RevertToSnapshot(id int) {
+	for i := len(self.journal) - 1; i >= id; i-- {
+		self.journal[i]()
+	}
+	self.journal = self.journal[:id]
+}
+
+func (self *stateDB) Snapshot() int {
+	return len(self.journal)
+}
+
+func (self *stateDB) AddLog(log *types.Log) {
+	self.logs = append(self.logs, log)
+	self.journal = append(self.journal, func() { self.logs = self.logs[:len(self.logs)-1] })
+}
+
+func (self *stateDB) AddPreimage(common.Hash, []byte) {}
+
+func (self *stateDB) ForEachStorage(addr common.Address, cb func(common.Hash, common.Hash) bool) error {
+	acc := self.getAccount(addr)
+	for k, v := range acc.origin {
+		if _, ok := acc.dirty[k]; ok {
+			continue
+		}
+		if !cb(k, v) {
+			return nil
+		}
+	}
+	for k, v := range acc.dirty {
+		if !cb(k, v) {
+			return nil
+		}
+	}
+	return nil
+}
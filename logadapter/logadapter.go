@@ -0,0 +1,61 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Package logadapter satisfies the go-kit logger taken by flashbot.WithLogger
+// with slog, zap or zerolog loggers.
+//
+// The level set with the go-kit level package is mapped to the level of the logger,
+// records without a level are logged at the info level.
+// The msg key becomes the message and the other key value pairs become fields.
+package logadapter
+
+import (
+	"fmt"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+)
+
+// Levels of the go-kit level package.
+const (
+	levelDebug = "debug"
+	levelInfo  = "info"
+	levelWarn  = "warn"
+	levelError = "error"
+)
+
+type field struct {
+	key string
+	val interface{}
+}
+
+type record struct {
+	level  string
+	msg    string
+	fields []field
+}
+
+func parse(keyvals []interface{}) record {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, log.ErrMissingValue)
+	}
+	rec := record{level: levelInfo}
+	rec.fields = make([]field, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, val := keyvals[i], keyvals[i+1]
+		if key == level.Key() {
+			if lvl, ok := val.(level.Value); ok {
+				rec.level = lvl.String()
+				continue
+			}
+		}
+		if key == "msg" {
+			if msg, ok := val.(string); ok && rec.msg == "" {
+				rec.msg = msg
+				continue
+			}
+		}
+		rec.fields = append(rec.fields, field{key: fmt.Sprint(key), val: val})
+	}
+	return rec
+}
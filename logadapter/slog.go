@@ -0,0 +1,45 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+//go:build go1.21
+
+package logadapter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-kit/log"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// Slog returns a go-kit logger which logs to the slog logger.
+func Slog(logger *slog.Logger) log.Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (self *slogLogger) Log(keyvals ...interface{}) error {
+	rec := parse(keyvals)
+	lvl := slog.LevelInfo
+	switch rec.level {
+	case levelDebug:
+		lvl = slog.LevelDebug
+	case levelWarn:
+		lvl = slog.LevelWarn
+	case levelError:
+		lvl = slog.LevelError
+	}
+	ctx := context.Background()
+	if !self.logger.Enabled(ctx, lvl) {
+		return nil
+	}
+	attrs := make([]slog.Attr, 0, len(rec.fields))
+	for _, f := range rec.fields {
+		attrs = append(attrs, slog.Any(f.key, f.val))
+	}
+	self.logger.LogAttrs(ctx, lvl, rec.msg, attrs...)
+	return nil
+}
@@ -0,0 +1,37 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+//go:build go1.21
+
+package logadapter
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log/level"
+)
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Slog(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	testutil.Ok(t, level.Debug(logger).Log("msg", "hidden"))
+	testutil.Equals(t, "", buf.String())
+
+	testutil.Ok(t, level.Warn(logger).Log("msg", "relay request failed", "relay", "https://relay", "err", errors.New("timeout")))
+	out := buf.String()
+	for _, exp := range []string{"level=WARN", `msg="relay request failed"`, "relay=https://relay", "err=timeout"} {
+		testutil.Assert(t, strings.Contains(out, exp), "missing %v:%v", exp, out)
+	}
+
+	buf.Reset()
+	testutil.Ok(t, logger.Log("msg", "no level", "odd"))
+	out = buf.String()
+	testutil.Assert(t, strings.Contains(out, "level=INFO"), "unexpected level:%v", out)
+	testutil.Assert(t, strings.Contains(out, "odd=(MISSING)"), "missing value should be kept:%v", out)
+}
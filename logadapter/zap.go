@@ -0,0 +1,42 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package logadapter
+
+import (
+	"github.com/go-kit/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type zapLogger struct {
+	logger *zap.Logger
+}
+
+// Zap returns a go-kit logger which logs to the zap logger.
+func Zap(logger *zap.Logger) log.Logger {
+	return &zapLogger{logger: logger}
+}
+
+func (self *zapLogger) Log(keyvals ...interface{}) error {
+	rec := parse(keyvals)
+	lvl := zapcore.InfoLevel
+	switch rec.level {
+	case levelDebug:
+		lvl = zapcore.DebugLevel
+	case levelWarn:
+		lvl = zapcore.WarnLevel
+	case levelError:
+		lvl = zapcore.ErrorLevel
+	}
+	ce := self.logger.Check(lvl, rec.msg)
+	if ce == nil {
+		return nil
+	}
+	fields := make([]zap.Field, 0, len(rec.fields))
+	for _, f := range rec.fields {
+		fields = append(fields, zap.Any(f.key, f.val))
+	}
+	ce.Write(fields...)
+	return nil
+}
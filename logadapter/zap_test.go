@@ -0,0 +1,33 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package logadapter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log/level"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZap(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := Zap(zap.New(core))
+
+	testutil.Ok(t, level.Debug(logger).Log("msg", "hidden"))
+	testutil.Ok(t, level.Error(logger).Log("msg", "relay request failed", "relay", "https://relay", "err", errors.New("timeout")))
+	testutil.Ok(t, logger.Log("msg", "no level"))
+
+	entries := logs.AllUntimed()
+	testutil.Equals(t, 2, len(entries))
+	testutil.Equals(t, zapcore.ErrorLevel, entries[0].Level)
+	testutil.Equals(t, "relay request failed", entries[0].Message)
+	fields := entries[0].ContextMap()
+	testutil.Equals(t, "https://relay", fields["relay"])
+	testutil.Equals(t, "timeout", fields["err"])
+	testutil.Equals(t, zapcore.InfoLevel, entries[1].Level)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package logadapter
+
+import (
+	"github.com/go-kit/log"
+	"github.com/rs/zerolog"
+)
+
+type zerologLogger struct {
+	logger zerolog.Logger
+}
+
+// Zerolog returns a go-kit logger which logs to the zerolog logger.
+func Zerolog(logger zerolog.Logger) log.Logger {
+	return &zerologLogger{logger: logger}
+}
+
+func (self *zerologLogger) Log(keyvals ...interface{}) error {
+	rec := parse(keyvals)
+	lvl := zerolog.InfoLevel
+	switch rec.level {
+	case levelDebug:
+		lvl = zerolog.DebugLevel
+	case levelWarn:
+		lvl = zerolog.WarnLevel
+	case levelError:
+		lvl = zerolog.ErrorLevel
+	}
+	// WithLevel returns nil when the level is disabled.
+	e := self.logger.WithLevel(lvl)
+	if e == nil {
+		return nil
+	}
+	for _, f := range rec.fields {
+		if err, ok := f.val.(error); ok {
+			e = e.AnErr(f.key, err)
+			continue
+		}
+		e = e.Interface(f.key, f.val)
+	}
+	e.Msg(rec.msg)
+	return nil
+}
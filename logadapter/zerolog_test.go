@@ -0,0 +1,34 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package logadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/go-kit/log/level"
+	"github.com/rs/zerolog"
+)
+
+func TestZerolog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Zerolog(zerolog.New(&buf).Level(zerolog.InfoLevel))
+
+	testutil.Ok(t, level.Debug(logger).Log("msg", "hidden"))
+	testutil.Equals(t, "", buf.String())
+
+	testutil.Ok(t, level.Warn(logger).Log("msg", "relay request failed", "relay", "https://relay", "attempt", 2, "err", errors.New("timeout")))
+	var rec map[string]interface{}
+	testutil.Ok(t, json.Unmarshal(buf.Bytes(), &rec))
+	testutil.Equals(t, map[string]interface{}{
+		"level":   "warn",
+		"message": "relay request failed",
+		"relay":   "https://relay",
+		"attempt": 2.0,
+		"err":     "timeout",
+	}, rec)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// MergeBundles concatenates the bundles in order into a single bundle,
+// for example an own transaction with user transactions from MEV-Share.
+// Transactions included in more than one bundle are kept only at their first position.
+// An error is returned when the nonces of a sender don't increase by one
+// in the merged order as the relay would drop such a bundle.
+func MergeBundles(bundles ...[]string) ([]string, error) {
+	var (
+		merged []string
+		seen   = make(map[common.Hash]bool)
+		nonces = make(map[common.Address]uint64)
+	)
+	for i, bundle := range bundles {
+		for j, txHex := range bundle {
+			tx, err := decodeTx(txHex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "bundle:%v tx:%v", i, j)
+			}
+			if seen[tx.Hash()] {
+				continue
+			}
+			seen[tx.Hash()] = true
+
+			sender, err := txSender(tx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "bundle:%v tx:%v", i, j)
+			}
+			if prev, ok := nonces[sender]; ok && tx.Nonce() != prev+1 {
+				return nil, errors.Errorf("nonce out of order bundle:%v tx:%v sender:%v expected:%v got:%v", i, j, sender.Hex(), prev+1, tx.Nonce())
+			}
+			nonces[sender] = tx.Nonce()
+			merged = append(merged, txHex)
+		}
+	}
+	if len(merged) == 0 {
+		return nil, errors.New("bundles have no transactions")
+	}
+	return merged, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestMergeBundles(t *testing.T) {
+	chainID := big.NewInt(1)
+	newKey := func() *ecdsa.PrivateKey {
+		prvKey, err := crypto.GenerateKey()
+		testutil.Ok(t, err)
+		return prvKey
+	}
+	to := common.HexToAddress("0x1")
+	newTx := func(prvKey *ecdsa.PrivateKey, nonce uint64) string {
+		txHex, err := SignTx(chainID, &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       21000,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(1),
+		}, prvKey)
+		testutil.Ok(t, err)
+		return txHex
+	}
+	bot, user := newKey(), newKey()
+
+	userTx := newTx(user, 7)
+	merged, err := MergeBundles(
+		[]string{newTx(bot, 1), userTx},
+		[]string{userTx, newTx(user, 8)},
+		[]string{newTx(bot, 2)},
+	)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 4, len(merged))
+	testutil.Equals(t, userTx, merged[1])
+
+	// Legacy transactions without replay protection.
+	legacy, err := types.SignNewTx(user, types.HomesteadSigner{}, &types.LegacyTx{Nonce: 9, To: &to, Gas: 21000, GasPrice: big.NewInt(1)})
+	testutil.Ok(t, err)
+	legacyRaw, err := legacy.MarshalBinary()
+	testutil.Ok(t, err)
+	_, err = MergeBundles(merged, []string{common.Bytes2Hex(legacyRaw)})
+	testutil.NotOk(t, err) // Missing 0x prefix.
+	merged, err = MergeBundles(merged, []string{"0x" + common.Bytes2Hex(legacyRaw)})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 5, len(merged))
+
+	// Nonce gaps and reordering are rejected.
+	_, err = MergeBundles([]string{newTx(bot, 1)}, []string{newTx(bot, 3)})
+	testutil.NotOk(t, err)
+	_, err = MergeBundles([]string{newTx(bot, 2)}, []string{newTx(bot, 1)})
+	testutil.NotOk(t, err)
+
+	_, err = MergeBundles()
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	metricRequests = prometheus.NewDesc(
+		"flashbot_relay_requests_total",
+		"Number of requests sent to the relay including retries.",
+		[]string{"relay"}, nil,
+	)
+	metricErrors = prometheus.NewDesc(
+		"flashbot_relay_errors_total",
+		"Number of failures by their class.",
+		[]string{"relay", "class"}, nil,
+	)
+	metricSuccessRatio = prometheus.NewDesc(
+		"flashbot_relay_success_ratio",
+		"Ratio of the successful requests in the stats window.",
+		[]string{"relay"}, nil,
+	)
+	metricLatency = prometheus.NewDesc(
+		"flashbot_relay_latency_seconds",
+		"Latency percentiles of the requests in the stats window.",
+		[]string{"relay", "quantile"}, nil,
+	)
+)
+
+// Collector exports the relay stats and error counters as prometheus metrics.
+type Collector struct {
+	stats func() []RelayStats
+}
+
+// NewCollector creates a collector of the stats returned by the function,
+// for example Multi.Stats so that relays added at runtime are included.
+func NewCollector(stats func() []RelayStats) *Collector {
+	return &Collector{stats: stats}
+}
+
+func (self *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metricRequests
+	ch <- metricErrors
+	ch <- metricSuccessRatio
+	ch <- metricLatency
+}
+
+func (self *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range self.stats() {
+		ch <- prometheus.MustNewConstMetric(metricRequests, prometheus.CounterValue, float64(stats.Total), stats.Relay)
+		// All classes are exported so that the series exist before the first failure.
+		for _, class := range ErrorClasses {
+			ch <- prometheus.MustNewConstMetric(metricErrors, prometheus.CounterValue, float64(stats.Errors[class]), stats.Relay, string(class))
+		}
+		ch <- prometheus.MustNewConstMetric(metricSuccessRatio, prometheus.GaugeValue, stats.SuccessRatio, stats.Relay)
+		ch <- prometheus.MustNewConstMetric(metricLatency, prometheus.GaugeValue, stats.P50.Seconds(), stats.Relay, "0.5")
+		ch <- prometheus.MustNewConstMetric(metricLatency, prometheus.GaugeValue, stats.P90.Seconds(), stats.Relay, "0.9")
+		ch <- prometheus.MustNewConstMetric(metricLatency, prometheus.GaugeValue, stats.P99.Seconds(), stats.Relay, "0.99")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector(func() []RelayStats {
+		return []RelayStats{{
+			Relay:        "relay1",
+			Total:        10,
+			SuccessRatio: 0.5,
+			P50:          100 * time.Millisecond,
+			P90:          200 * time.Millisecond,
+			P99:          time.Second,
+			Errors:       map[ErrorClass]uint64{ErrorClassNetwork: 3, ErrorClassSimulationRevert: 2},
+		}}
+	})
+	expected := `
+# HELP flashbot_relay_errors_total Number of failures by their class.
+# TYPE flashbot_relay_errors_total counter
+flashbot_relay_errors_total{class="decode",relay="relay1"} 0
+flashbot_relay_errors_total{class="network",relay="relay1"} 3
+flashbot_relay_errors_total{class="other",relay="relay1"} 0
+flashbot_relay_errors_total{class="rate_limit",relay="relay1"} 0
+flashbot_relay_errors_total{class="relay_error",relay="relay1"} 0
+flashbot_relay_errors_total{class="simulation_revert",relay="relay1"} 2
+flashbot_relay_errors_total{class="tls",relay="relay1"} 0
+# HELP flashbot_relay_latency_seconds Latency percentiles of the requests in the stats window.
+# TYPE flashbot_relay_latency_seconds gauge
+flashbot_relay_latency_seconds{quantile="0.5",relay="relay1"} 0.1
+flashbot_relay_latency_seconds{quantile="0.9",relay="relay1"} 0.2
+flashbot_relay_latency_seconds{quantile="0.99",relay="relay1"} 1
+# HELP flashbot_relay_requests_total Number of requests sent to the relay including retries.
+# TYPE flashbot_relay_requests_total counter
+flashbot_relay_requests_total{relay="relay1"} 10
+# HELP flashbot_relay_success_ratio Ratio of the successful requests in the stats window.
+# TYPE flashbot_relay_success_ratio gauge
+flashbot_relay_success_ratio{relay="relay1"} 0.5
+`
+	testutil.Ok(t, promtestutil.CollectAndCompare(collector, strings.NewReader(expected)))
+}
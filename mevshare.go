@@ -0,0 +1,187 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const MevBundleVersion = "v0.1"
+
+// MevBundle is the MEV-Share bundle schema used by mev_sendBundle and mev_simBundle.
+type MevBundle struct {
+	Version   string             `json:"version"`
+	Inclusion MevBundleInclusion `json:"inclusion"`
+	Body      []MevBundleBody    `json:"body"`
+	Validity  *MevBundleValidity `json:"validity,omitempty"`
+	Privacy   *PrivateTxPrivacy  `json:"privacy,omitempty"`
+	Metadata  *MevBundleMetadata `json:"metadata,omitempty"`
+}
+
+// MevBundleInclusion is the range of blocks in which the bundle can be included.
+type MevBundleInclusion struct {
+	Block    hexutil.Uint64 `json:"block"`
+	MaxBlock hexutil.Uint64 `json:"maxBlock,omitempty"`
+}
+
+// MevBundleBody is a single bundle item which is either
+// the hash of a transaction from the MEV-Share event stream,
+// a signed transaction or a nested bundle.
+type MevBundleBody struct {
+	Hash      *common.Hash `json:"hash,omitempty"`
+	Tx        string       `json:"tx,omitempty"`
+	CanRevert bool         `json:"canRevert,omitempty"`
+	Bundle    *MevBundle   `json:"bundle,omitempty"`
+}
+
+type MevBundleValidity struct {
+	Refund       []MevBundleRefund       `json:"refund,omitempty"`
+	RefundConfig []MevBundleRefundConfig `json:"refundConfig,omitempty"`
+}
+
+// MevBundleRefund sets the percent of the profit refunded to the sender of the body item at BodyIdx.
+type MevBundleRefund struct {
+	BodyIdx int `json:"bodyIdx"`
+	Percent int `json:"percent"`
+}
+
+type MevBundleRefundConfig struct {
+	Address common.Address `json:"address"`
+	Percent int            `json:"percent"`
+}
+
+type MevBundleMetadata struct {
+	OriginID string `json:"originId,omitempty"`
+}
+
+// MevBundleTx creates a body item from a signed transaction.
+func MevBundleTx(txHex string, canRevert bool) MevBundleBody {
+	return MevBundleBody{Tx: txHex, CanRevert: canRevert}
+}
+
+// MevBundleHash creates a body item referencing a pending transaction by its hash.
+func MevBundleHash(hash common.Hash) MevBundleBody {
+	return MevBundleBody{Hash: &hash}
+}
+
+type SendMevBundleResponse struct {
+	Error  `json:"error,omitempty"`
+	Result struct {
+		BundleHash string `json:"bundleHash,omitempty"`
+	} `json:"result,omitempty"`
+}
+
+// withDefaultVersion returns a copy of the bundle and its nested bundles
+// with the current version where it isn't set so that the bundle of the caller isn't modified.
+func (self *MevBundle) withDefaultVersion() *MevBundle {
+	bundle := *self
+	if bundle.Version == "" {
+		bundle.Version = MevBundleVersion
+	}
+	bundle.Body = make([]MevBundleBody, len(self.Body))
+	for i, body := range self.Body {
+		if body.Bundle != nil {
+			body.Bundle = body.Bundle.withDefaultVersion()
+		}
+		bundle.Body[i] = body
+	}
+	return &bundle
+}
+
+// SendMevBundle submits a MEV-Share bundle with mev_sendBundle.
+func (self *Flashbot) SendMevBundle(ctx context.Context, bundle *MevBundle) (*SendMevBundleResponse, error) {
+	if bundle == nil || len(bundle.Body) == 0 {
+		return nil, errors.New("bundle body can't be empty")
+	}
+	bundle = bundle.withDefaultVersion()
+
+	resp, err := self.req(ctx, MethodMevSendBundle, bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "flashbot mev send bundle request")
+	}
+
+	rr := &SendMevBundleResponse{}
+
+	err = self.unmarshal(resp, rr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal flashbot response:%v", string(resp))
+	}
+
+	if rr.Error.Code != 0 {
+		return nil, errors.Wrapf(self.rpcError(rr.Error), "flashbot request returned an error block:%v", uint64(bundle.Inclusion.Block))
+	}
+
+	return rr, nil
+}
+
+// SimMevBundleOpts overrides the block context used by mev_simBundle.
+// Unset fields are derived from the parent block.
+type SimMevBundleOpts struct {
+	ParentBlock *hexutil.Uint64 `json:"parentBlock,omitempty"`
+	BlockNumber *hexutil.Uint64 `json:"blockNumber,omitempty"`
+	Coinbase    *common.Address `json:"coinbase,omitempty"`
+	Timestamp   *hexutil.Uint64 `json:"timestamp,omitempty"`
+	GasLimit    *hexutil.Uint64 `json:"gasLimit,omitempty"`
+	BaseFee     *hexutil.Big    `json:"baseFee,omitempty"`
+	// Timeout in seconds for the simulation.
+	Timeout *int64 `json:"timeout,omitempty"`
+}
+
+type SimMevBundleResult struct {
+	Success         bool             `json:"success"`
+	Error           string           `json:"error,omitempty"`
+	StateBlock      hexutil.Uint64   `json:"stateBlock"`
+	MevGasPrice     hexutil.Big      `json:"mevGasPrice"`
+	Profit          hexutil.Big      `json:"profit"`
+	RefundableValue hexutil.Big      `json:"refundableValue"`
+	GasUsed         hexutil.Uint64   `json:"gasUsed"`
+	BodyLogs        []SimMevBodyLogs `json:"logs,omitempty"`
+}
+
+// SimMevBodyLogs are the logs emitted by each body item,
+// nested bundles report their logs in BundleLogs.
+type SimMevBodyLogs struct {
+	TxLogs     []MevShareLog    `json:"txLogs,omitempty"`
+	BundleLogs []SimMevBodyLogs `json:"bundleLogs,omitempty"`
+}
+
+type SimMevBundleResponse struct {
+	Error  `json:"error,omitempty"`
+	Result SimMevBundleResult `json:"result,omitempty"`
+}
+
+// SimMevBundle simulates a MEV-Share bundle with mev_simBundle.
+// A bundle that fails in the simulation isn't an error,
+// it is reported through Result.Success and Result.Error.
+func (self *Flashbot) SimMevBundle(ctx context.Context, bundle *MevBundle, opts *SimMevBundleOpts) (*SimMevBundleResponse, error) {
+	if bundle == nil || len(bundle.Body) == 0 {
+		return nil, errors.New("bundle body can't be empty")
+	}
+	bundle = bundle.withDefaultVersion()
+	if opts == nil {
+		opts = &SimMevBundleOpts{}
+	}
+
+	resp, err := self.req(ctx, MethodMevSimBundle, bundle, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "flashbot mev sim bundle request")
+	}
+
+	rr := &SimMevBundleResponse{}
+
+	err = self.unmarshal(resp, rr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal flashbot response:%v", string(resp))
+	}
+
+	if rr.Error.Code != 0 {
+		return nil, errors.Wrapf(self.rpcError(rr.Error), "flashbot request returned an error block:%v", uint64(bundle.Inclusion.Block))
+	}
+
+	return rr, nil
+}
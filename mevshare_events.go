@@ -0,0 +1,188 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	mevShareBackoffMin = time.Second
+	mevShareBackoffMax = 30 * time.Second
+	// Events can carry full calldata so allow for lines larger than the bufio default.
+	mevShareMaxLineSize = 4 * 1024 * 1024
+)
+
+func MevShareEventsURLDefault(netID int64) (string, error) {
+	n, err := network(netID)
+	if err != nil {
+		return "", err
+	}
+	if n.MevShareEventsURL == "" {
+		return "", errors.Errorf("network has no mev-share events url id:%v", netID)
+	}
+	return n.MevShareEventsURL, nil
+}
+
+// MevShareEvent is a hint about a pending transaction or bundle.
+// Which fields are set depends on the privacy hints chosen by the sender.
+type MevShareEvent struct {
+	Hash        common.Hash       `json:"hash"`
+	Logs        []MevShareLog     `json:"logs,omitempty"`
+	Txs         []MevShareEventTx `json:"txs,omitempty"`
+	MevGasPrice *hexutil.Big      `json:"mevGasPrice,omitempty"`
+	GasUsed     *hexutil.Uint64   `json:"gasUsed,omitempty"`
+}
+
+type MevShareEventTx struct {
+	Hash             *common.Hash    `json:"hash,omitempty"`
+	To               *common.Address `json:"to,omitempty"`
+	FunctionSelector hexutil.Bytes   `json:"functionSelector,omitempty"`
+	CallData         hexutil.Bytes   `json:"callData,omitempty"`
+}
+
+type MevShareLog struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// MevShareStream reads the server-sent events stream of the MEV-Share node.
+type MevShareStream struct {
+	url    string
+	client *http.Client
+	logger log.Logger
+}
+
+func NewMevShareStream(url string, logger log.Logger) *MevShareStream {
+	return &MevShareStream{
+		url:    url,
+		client: &http.Client{},
+		logger: log.With(logger, "component", "mevshare-stream"),
+	}
+}
+
+// Subscribe connects to the stream and delivers the events over the returned channel.
+// Dropped connections are reestablished with an exponential backoff and
+// the channel is closed only once the context is done.
+func (self *MevShareStream) Subscribe(ctx context.Context) <-chan MevShareEvent {
+	events := make(chan MevShareEvent)
+
+	go func() {
+		defer close(events)
+
+		backoff := mevShareBackoffMin
+		for {
+			received, retry, err := self.stream(ctx, events)
+			if ctx.Err() != nil {
+				return
+			}
+			if received {
+				backoff = mevShareBackoffMin
+			}
+			if retry > 0 {
+				backoff = retry
+			}
+			level.Warn(self.logger).Log("msg", "event stream disconnected", "err", err, "reconnectIn", backoff)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			if backoff *= 2; backoff > mevShareBackoffMax {
+				backoff = mevShareBackoffMax
+			}
+		}
+	}()
+
+	return events
+}
+
+// stream reads the events until the connection fails and reports
+// whether any events were received and the retry delay requested by the server.
+func (self *MevShareStream) stream(ctx context.Context, events chan<- MevShareEvent) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, self.url, nil)
+	if err != nil {
+		return false, 0, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	resp, err := self.client.Do(req)
+	if err != nil {
+		return false, 0, errors.Wrap(err, "connecting")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return false, 0, errors.Errorf("bad response status %v", resp.Status)
+	}
+
+	var (
+		received bool
+		retry    time.Duration
+		data     []byte
+	)
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), mevShareMaxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			// A blank line dispatches the accumulated event.
+			if len(data) == 0 {
+				continue
+			}
+			event := MevShareEvent{}
+			if err := json.Unmarshal(data, &event); err != nil {
+				level.Error(self.logger).Log("msg", "decoding event", "err", err, "data", string(data))
+			} else {
+				select {
+				case events <- event:
+					received = true
+				case <-ctx.Done():
+					return received, retry, ctx.Err()
+				}
+			}
+			data = data[:0]
+		case line[0] == ':':
+			// Comment, used by the server as a keep-alive.
+		default:
+			field, value := line, []byte{}
+			if i := bytes.IndexByte(line, ':'); i >= 0 {
+				field, value = line[:i], bytes.TrimPrefix(line[i+1:], []byte(" "))
+			}
+			switch string(field) {
+			case "data":
+				if len(data) > 0 {
+					data = append(data, '\n')
+				}
+				data = append(data, value...)
+			case "retry":
+				if ms, err := strconv.Atoi(string(value)); err == nil {
+					retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return received, retry, errors.Wrap(err, "reading stream")
+	}
+	return received, retry, errors.New("stream closed by the server")
+}
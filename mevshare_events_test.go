@@ -0,0 +1,51 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-kit/log"
+)
+
+func TestMevShareStream(t *testing.T) {
+	var conns int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&conns, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Each connection sends a single event and then drops
+		// to check that the client reconnects.
+		_, err := fmt.Fprintf(w, ": ping\nretry: 10\ndata: {\"hash\":\"0x%064x\",\"txs\":[{\"to\":\"0x0000000000000000000000000000000000000001\",\"functionSelector\":\"0xa9059cbb\"}],\"logs\":[]}\n\n", n)
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	events := NewMevShareStream(srv.URL, log.NewNopLogger()).Subscribe(ctx)
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case event := <-events:
+			testutil.Equals(t, common.BigToHash(big.NewInt(int64(i))), event.Hash)
+			testutil.Equals(t, "0xa9059cbb", event.Txs[0].FunctionSelector.String())
+		case <-ctx.Done():
+			t.Fatal("timeout waiting for events")
+		}
+	}
+
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSendMevBundle(t *testing.T) {
+	var (
+		method string
+		params []json.RawMessage
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		method = msg.Method
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0xabc"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	bundle := &MevBundle{
+		Inclusion: MevBundleInclusion{Block: 10, MaxBlock: 12},
+		Body: []MevBundleBody{
+			MevBundleHash(common.HexToHash("0x1")),
+			MevBundleTx("0x02", false),
+		},
+		Validity: &MevBundleValidity{Refund: []MevBundleRefund{{BodyIdx: 0, Percent: 90}}},
+		Privacy:  &PrivateTxPrivacy{Hints: []PrivacyHint{HintHash}},
+	}
+	resp, err := flashbot.SendMevBundle(context.Background(), bundle)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", bundle.Version)
+	testutil.Equals(t, "0xabc", resp.Result.BundleHash)
+	testutil.Equals(t, "mev_sendBundle", method)
+	testutil.Equals(t,
+		`{"version":"v0.1","inclusion":{"block":"0xa","maxBlock":"0xc"},"body":[{"hash":"0x0000000000000000000000000000000000000000000000000000000000000001"},{"tx":"0x02"}],"validity":{"refund":[{"bodyIdx":0,"percent":90}]},"privacy":{"hints":["hash"]}}`,
+		string(params[0]),
+	)
+
+	// The nested bundles get the default version as well.
+	nested := &MevBundle{
+		Inclusion: MevBundleInclusion{Block: 10},
+		Body:      []MevBundleBody{MevBundleTx("0x03", false)},
+	}
+	bundle = &MevBundle{
+		Inclusion: MevBundleInclusion{Block: 10},
+		Body:      []MevBundleBody{MevBundleTx("0x02", false), {Bundle: nested}},
+	}
+	_, err = flashbot.SendMevBundle(context.Background(), bundle)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", nested.Version)
+	testutil.Equals(t,
+		`{"version":"v0.1","inclusion":{"block":"0xa"},"body":[{"tx":"0x02"},{"bundle":{"version":"v0.1","inclusion":{"block":"0xa"},"body":[{"tx":"0x03"}]}}]}`,
+		string(params[0]),
+	)
+
+	_, err = flashbot.SendMevBundle(context.Background(), &MevBundle{})
+	testutil.NotOk(t, err)
+}
+
+func TestSimMevBundle(t *testing.T) {
+	var params []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{
+			"success":true,"stateBlock":"0xa","mevGasPrice":"0x2","profit":"0x3e8","refundableValue":"0x64","gasUsed":"0x5208",
+			"logs":[{"txLogs":[]}]
+		}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	parent := hexutil.Uint64(9)
+	bundle := &MevBundle{
+		Inclusion: MevBundleInclusion{Block: 10},
+		Body:      []MevBundleBody{MevBundleTx("0x02", false)},
+	}
+	resp, err := flashbot.SimMevBundle(context.Background(), bundle, &SimMevBundleOpts{ParentBlock: &parent})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", bundle.Version)
+	testutil.Assert(t, strings.HasPrefix(string(params[0]), `{"version":"v0.1"`), "default version should be sent:%s", params[0])
+	testutil.Equals(t, `{"parentBlock":"0x9"}`, string(params[1]))
+	testutil.Assert(t, resp.Result.Success, "simulation should succeed")
+	testutil.Equals(t, int64(1000), resp.Result.Profit.ToInt().Int64())
+	testutil.Equals(t, int64(100), resp.Result.RefundableValue.ToInt().Int64())
+	testutil.Equals(t, hexutil.Uint64(21000), resp.Result.GasUsed)
+}
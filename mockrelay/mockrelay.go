@@ -0,0 +1,622 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Package mockrelay provides an in-memory relay that implements the flashbots JSON-RPC api.
+// Simulations are deterministic so it can be used for integration tests of bots without
+// hitting the real relays.
+package mockrelay
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeUnauthorized   = -32000
+)
+
+type Config struct {
+	// BaseFee used when calculating the effective gas tip of the simulated transactions.
+	BaseFee *big.Int
+	// Coinbase receives the value of transactions sent directly to it.
+	Coinbase common.Address
+	// RevertAddresses are contracts for which every transaction is simulated as reverted.
+	RevertAddresses []common.Address
+	// SkipSignatureCheck accepts requests without a valid X-Flashbots-Signature header.
+	SkipSignatureCheck bool
+}
+
+type bundle struct {
+	txs         []string
+	uuid        string
+	blockNum    uint64
+	submittedAt time.Time
+	simulatedAt time.Time
+	signer      common.Address
+}
+
+type userStats struct {
+	minerPayments *big.Int
+	gasSimulated  uint64
+}
+
+// Relay is a http.Handler that emulates a flashbots compatible relay.
+type Relay struct {
+	logger log.Logger
+	cfg    Config
+
+	mtx        sync.Mutex
+	bundles    map[common.Hash]*bundle
+	privateTxs map[common.Hash]string
+	users      map[common.Address]*userStats
+}
+
+func New(logger log.Logger, cfg Config) *Relay {
+	if cfg.BaseFee == nil {
+		cfg.BaseFee = big.NewInt(0)
+	}
+	return &Relay{
+		logger:     logger,
+		cfg:        cfg,
+		bundles:    make(map[common.Hash]*bundle),
+		privateTxs: make(map[common.Hash]string),
+		users:      make(map[common.Address]*userStats),
+	}
+}
+
+type request struct {
+	Version string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (self *Relay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+
+	req := &request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		self.reply(w, nil, nil, &rpcError{Code: codeInvalidRequest, Message: "invalid json"})
+		return
+	}
+
+	var signer common.Address
+	if !self.cfg.SkipSignatureCheck {
+		signer, err = verifySignature(body, r.Header.Get("X-Flashbots-Signature"))
+		if err != nil {
+			self.reply(w, req.ID, nil, &rpcError{Code: codeUnauthorized, Message: err.Error()})
+			return
+		}
+	}
+
+	level.Debug(self.logger).Log("msg", "relay request", "method", req.Method, "signer", signer.Hex())
+
+	result, rpcErr := self.handle(req, signer)
+	self.reply(w, req.ID, result, rpcErr)
+}
+
+func (self *Relay) handle(req *request, signer common.Address) (interface{}, *rpcError) {
+	if len(req.Params) < 1 {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "missing params"}
+	}
+
+	switch req.Method {
+	case "eth_sendBundle":
+		return self.sendBundle(req.Params[0], signer)
+	case "eth_cancelBundle":
+		return self.cancelBundle(req.Params[0])
+	case "eth_callBundle":
+		return self.callBundle(req.Params[0])
+	case "flashbots_getBundleStats":
+		return self.bundleStats(req.Params[0])
+	case "flashbots_getUserStats":
+		return self.userStats(signer)
+	case "flashbots_getBundleStatsV2":
+		return self.bundleStatsV2(req.Params[0])
+	case "flashbots_getUserStatsV2":
+		return self.userStatsV2(signer)
+	case "eth_sendPrivateTransaction":
+		return self.sendPrivateTransaction(req.Params[0])
+	case "eth_sendPrivateRawTransaction":
+		return self.sendPrivateRawTransaction(req.Params[0])
+	case "eth_cancelPrivateTransaction":
+		return self.cancelPrivateTransaction(req.Params[0])
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "unknown method: " + req.Method}
+	}
+}
+
+func (self *Relay) reply(w http.ResponseWriter, id json.RawMessage, result interface{}, rpcErr *rpcError) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response{Version: "2.0", ID: id, Result: result, Error: rpcErr}); err != nil {
+		level.Error(self.logger).Log("msg", "writing response", "err", err)
+	}
+}
+
+type paramsBundle struct {
+	Txs             []string `json:"txs"`
+	BlockNum        string   `json:"blockNumber"`
+	StateBlockNum   string   `json:"stateBlockNumber"`
+	ReplacementUuid string   `json:"replacementUuid"`
+}
+
+type paramsCancelBundle struct {
+	ReplacementUuid string `json:"replacementUuid"`
+}
+
+type resultSend struct {
+	BundleHash string `json:"bundleHash"`
+}
+
+func (self *Relay) sendBundle(raw json.RawMessage, signer common.Address) (interface{}, *rpcError) {
+	params := &paramsBundle{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	blockNum, err := hexutil.DecodeUint64(params.BlockNum)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid blockNumber"}
+	}
+	hash, err := bundleHash(params.Txs)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	sim, rpcErr := self.simulate(params.Txs)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	if params.ReplacementUuid != "" {
+		self.cancel(params.ReplacementUuid)
+	}
+
+	now := time.Now()
+	self.bundles[hash] = &bundle{
+		txs:         params.Txs,
+		uuid:        params.ReplacementUuid,
+		blockNum:    blockNum,
+		submittedAt: now,
+		simulatedAt: now,
+		signer:      signer,
+	}
+	self.addUserStats(signer, sim)
+
+	return resultSend{BundleHash: hash.Hex()}, nil
+}
+
+func (self *Relay) cancelBundle(raw json.RawMessage) (interface{}, *rpcError) {
+	params := &paramsCancelBundle{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if params.ReplacementUuid == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "missing replacementUuid"}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.cancel(params.ReplacementUuid)
+
+	return nil, nil
+}
+
+// cancel removes all bundles with the given uuid, the caller must hold the lock.
+func (self *Relay) cancel(uuid string) {
+	for hash, b := range self.bundles {
+		if b.uuid == uuid {
+			delete(self.bundles, hash)
+		}
+	}
+}
+
+func (self *Relay) callBundle(raw json.RawMessage) (interface{}, *rpcError) {
+	params := &paramsBundle{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	sim, rpcErr := self.simulate(params.Txs)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	return sim, nil
+}
+
+type paramsStats struct {
+	BundleHash string `json:"bundleHash"`
+	BlockNum   string `json:"blockNumber"`
+}
+
+type resultBundleStats struct {
+	IsSimulated    bool       `json:"isSimulated"`
+	IsHighPriority bool       `json:"isHighPriority"`
+	SimulatedAt    *time.Time `json:"simulatedAt,omitempty"`
+	ReceivedAt     *time.Time `json:"receivedAt,omitempty"`
+	SubmittedAt    *time.Time `json:"submittedAt,omitempty"`
+	SentToMinersAt *time.Time `json:"sentToMinersAt,omitempty"`
+}
+
+func (self *Relay) bundleStats(raw json.RawMessage) (interface{}, *rpcError) {
+	params := &paramsStats{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	b, ok := self.bundles[common.HexToHash(params.BundleHash)]
+	if !ok {
+		return resultBundleStats{}, nil
+	}
+	return resultBundleStats{
+		IsSimulated:    true,
+		IsHighPriority: self.users[b.signer] != nil,
+		SimulatedAt:    &b.simulatedAt,
+		ReceivedAt:     &b.submittedAt,
+		SubmittedAt:    &b.submittedAt,
+		SentToMinersAt: &b.submittedAt,
+	}, nil
+}
+
+// builderPubkey is reported as the builder which considered the bundles.
+const builderPubkey = "0xmockrelay"
+
+type builderTimestamp struct {
+	Pubkey    string     `json:"pubkey"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type resultBundleStatsV2 struct {
+	IsSimulated            bool               `json:"isSimulated"`
+	IsHighPriority         bool               `json:"isHighPriority"`
+	SimulatedAt            *time.Time         `json:"simulatedAt,omitempty"`
+	ReceivedAt             *time.Time         `json:"receivedAt,omitempty"`
+	ConsideredByBuildersAt []builderTimestamp `json:"consideredByBuildersAt,omitempty"`
+}
+
+func (self *Relay) bundleStatsV2(raw json.RawMessage) (interface{}, *rpcError) {
+	params := &paramsStats{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	b, ok := self.bundles[common.HexToHash(params.BundleHash)]
+	if !ok {
+		return resultBundleStatsV2{}, nil
+	}
+	return resultBundleStatsV2{
+		IsSimulated:            true,
+		IsHighPriority:         self.users[b.signer] != nil,
+		SimulatedAt:            &b.simulatedAt,
+		ReceivedAt:             &b.submittedAt,
+		ConsideredByBuildersAt: []builderTimestamp{{Pubkey: builderPubkey, Timestamp: &b.submittedAt}},
+	}, nil
+}
+
+type resultUserStats struct {
+	IsHighPriority       bool   `json:"is_high_priority"`
+	AllTimeMinerPayments string `json:"all_time_miner_payments"`
+	AllTimeGasSimulated  string `json:"all_time_gas_simulated"`
+	Last7dMinerPayments  string `json:"last_7d_miner_payments"`
+	Last7dGasSimulated   string `json:"last_7d_gas_simulated"`
+	Last1dMinerPayments  string `json:"last_1d_miner_payments"`
+	Last1dGasSimulated   string `json:"last_1d_gas_simulated"`
+}
+
+func (self *Relay) userStats(signer common.Address) (interface{}, *rpcError) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	stats, ok := self.users[signer]
+	if !ok {
+		stats = &userStats{minerPayments: big.NewInt(0)}
+	}
+	payments := stats.minerPayments.String()
+	gas := big.NewInt(0).SetUint64(stats.gasSimulated).String()
+	return resultUserStats{
+		IsHighPriority:       ok,
+		AllTimeMinerPayments: payments,
+		AllTimeGasSimulated:  gas,
+		Last7dMinerPayments:  payments,
+		Last7dGasSimulated:   gas,
+		Last1dMinerPayments:  payments,
+		Last1dGasSimulated:   gas,
+	}, nil
+}
+
+type resultUserStatsV2 struct {
+	IsHighPriority           bool   `json:"isHighPriority"`
+	AllTimeValidatorPayments string `json:"allTimeValidatorPayments"`
+	AllTimeGasSimulated      string `json:"allTimeGasSimulated"`
+	Last7dValidatorPayments  string `json:"last7dValidatorPayments"`
+	Last7dGasSimulated       string `json:"last7dGasSimulated"`
+	Last1dValidatorPayments  string `json:"last1dValidatorPayments"`
+	Last1dGasSimulated       string `json:"last1dGasSimulated"`
+}
+
+func (self *Relay) userStatsV2(signer common.Address) (interface{}, *rpcError) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	stats, ok := self.users[signer]
+	if !ok {
+		stats = &userStats{minerPayments: big.NewInt(0)}
+	}
+	payments := stats.minerPayments.String()
+	gas := big.NewInt(0).SetUint64(stats.gasSimulated).String()
+	return resultUserStatsV2{
+		IsHighPriority:           ok,
+		AllTimeValidatorPayments: payments,
+		AllTimeGasSimulated:      gas,
+		Last7dValidatorPayments:  payments,
+		Last7dGasSimulated:       gas,
+		Last1dValidatorPayments:  payments,
+		Last1dGasSimulated:       gas,
+	}, nil
+}
+
+type paramsPrivateTransaction struct {
+	Tx string `json:"tx"`
+}
+
+type paramsCancelPrivateTransaction struct {
+	TxHash string `json:"txHash"`
+}
+
+func (self *Relay) sendPrivateTransaction(raw json.RawMessage) (interface{}, *rpcError) {
+	params := &paramsPrivateTransaction{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	tx, err := decodeTx(params.Tx)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.privateTxs[tx.Hash()] = params.Tx
+
+	return tx.Hash().Hex(), nil
+}
+
+func (self *Relay) sendPrivateRawTransaction(raw json.RawMessage) (interface{}, *rpcError) {
+	var txHex string
+	if err := json.Unmarshal(raw, &txHex); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	tx, err := decodeTx(txHex)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.privateTxs[tx.Hash()] = txHex
+
+	return tx.Hash().Hex(), nil
+}
+
+func (self *Relay) cancelPrivateTransaction(raw json.RawMessage) (interface{}, *rpcError) {
+	params := &paramsCancelPrivateTransaction{}
+	if err := json.Unmarshal(raw, params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	delete(self.privateTxs, common.HexToHash(params.TxHash))
+
+	// Like the real relay cancelling unknown transactions is not an error.
+	return true, nil
+}
+
+type metadata struct {
+	CoinbaseDiff      string `json:"coinbaseDiff"`
+	EthSentToCoinbase string `json:"ethSentToCoinbase"`
+	GasFees           string `json:"gasFees"`
+}
+
+type txResult struct {
+	metadata
+	FromAddress string `json:"fromAddress"`
+	GasPrice    string `json:"gasPrice"`
+	TxHash      string `json:"txHash"`
+	Error       string `json:"error,omitempty"`
+	Revert      string `json:"revert,omitempty"`
+	GasUsed     uint64 `json:"gasUsed"`
+}
+
+type resultCall struct {
+	BundleGasPrice string `json:"bundleGasPrice"`
+	BundleHash     string `json:"bundleHash"`
+	metadata
+	Results []txResult `json:"results"`
+
+	gasUsed uint64
+	payment *big.Int
+}
+
+// simulate calculates deterministic results based only on the content of the transactions.
+// The gas used is the intrinsic gas of each transaction and
+// the miner is paid the effective tip plus any value sent directly to the coinbase.
+func (self *Relay) simulate(txsHex []string) (*resultCall, *rpcError) {
+	hash, err := bundleHash(txsHex)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	res := &resultCall{BundleHash: hash.Hex(), payment: big.NewInt(0)}
+	totalFees := big.NewInt(0)
+	totalSent := big.NewInt(0)
+
+	for _, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: errors.Wrap(err, "recovering sender").Error()}
+		}
+		gasUsed, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, true)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		tip, err := tx.EffectiveGasTip(self.cfg.BaseFee)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+
+		r := txResult{
+			FromAddress: from.Hex(),
+			GasPrice:    tip.String(),
+			TxHash:      tx.Hash().Hex(),
+			GasUsed:     gasUsed,
+		}
+
+		fees := big.NewInt(0).Mul(tip, big.NewInt(0).SetUint64(gasUsed))
+		sent := big.NewInt(0)
+		if tx.To() != nil && *tx.To() == self.cfg.Coinbase {
+			sent.Set(tx.Value())
+		}
+		if tx.To() != nil && self.reverts(*tx.To()) {
+			r.Error = "execution reverted"
+			r.Revert = "mockrelay: revert"
+			sent.SetInt64(0)
+		}
+
+		r.GasFees = fees.String()
+		r.EthSentToCoinbase = sent.String()
+		r.CoinbaseDiff = big.NewInt(0).Add(fees, sent).String()
+		res.Results = append(res.Results, r)
+
+		totalFees.Add(totalFees, fees)
+		totalSent.Add(totalSent, sent)
+		res.gasUsed += gasUsed
+	}
+
+	res.payment.Add(totalFees, totalSent)
+	res.GasFees = totalFees.String()
+	res.EthSentToCoinbase = totalSent.String()
+	res.CoinbaseDiff = res.payment.String()
+	res.BundleGasPrice = "0"
+	if res.gasUsed > 0 {
+		res.BundleGasPrice = big.NewInt(0).Div(res.payment, big.NewInt(0).SetUint64(res.gasUsed)).String()
+	}
+
+	return res, nil
+}
+
+func (self *Relay) reverts(addr common.Address) bool {
+	for _, a := range self.cfg.RevertAddresses {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (self *Relay) addUserStats(signer common.Address, sim *resultCall) {
+	stats, ok := self.users[signer]
+	if !ok {
+		stats = &userStats{minerPayments: big.NewInt(0)}
+		self.users[signer] = stats
+	}
+	stats.minerPayments.Add(stats.minerPayments, sim.payment)
+	stats.gasSimulated += sim.gasUsed
+}
+
+func decodeTx(txHex string) (*types.Transaction, error) {
+	raw, err := hexutil.Decode(txHex)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding tx hex")
+	}
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrap(err, "decoding tx")
+	}
+	return tx, nil
+}
+
+// bundleHash is the keccak256 of the concatenated transaction hashes.
+func bundleHash(txsHex []string) (common.Hash, error) {
+	if len(txsHex) == 0 {
+		return common.Hash{}, errors.New("bundle has no transactions")
+	}
+	var hashes []byte
+	for _, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes), nil
+}
+
+func verifySignature(payload []byte, header string) (common.Address, error) {
+	parts := strings.Split(header, ":")
+	if len(parts) != 2 {
+		return common.Address{}, errors.New("missing or malformed X-Flashbots-Signature header")
+	}
+	sig, err := hexutil.Decode(parts[1])
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "decoding signature")
+	}
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(payload)))), sig)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "recovering signer")
+	}
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if signer != common.HexToAddress(parts[0]) {
+		return common.Address{}, errors.New("signature doesn't match the address in the header")
+	}
+	return signer, nil
+}
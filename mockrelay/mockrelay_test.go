@@ -0,0 +1,124 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package mockrelay
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/flashbot"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/log"
+)
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+	coinbase := common.HexToAddress("0xc0ffee")
+
+	srv := httptest.NewServer(New(log.NewNopLogger(), Config{Coinbase: coinbase}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	fb, err := flashbot.New(privKey, &flashbot.Api{URL: srv.URL, SupportsSimulation: true})
+	testutil.Ok(t, err)
+
+	tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		To:        &coinbase,
+		Value:     big.NewInt(1000),
+		Gas:       21000,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(2),
+	})
+	testutil.Ok(t, err)
+	txBin, err := tx.MarshalBinary()
+	testutil.Ok(t, err)
+	txsHex := []string{hexutil.Encode(txBin)}
+
+	sim, err := fb.CallBundle(ctx, txsHex, 0, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "43000", sim.CoinbaseDiff)
+	testutil.Equals(t, "1000", sim.EthSentToCoinbase)
+	testutil.Equals(t, uint64(21000), sim.Results[0].GasUsed)
+
+	resp, err := fb.SendBundle(ctx, txsHex, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, sim.BundleHash, resp.BundleHash)
+
+	stats, err := fb.GetBundleStats(ctx, resp.BundleHash, 10)
+	testutil.Ok(t, err)
+	testutil.Assert(t, stats.Result.IsSimulated, "bundle should be simulated")
+	testutil.Assert(t, stats.Result.ReceivedAt != nil, "bundle should have a receive time")
+
+	userStats, err := fb.GetUserStats(ctx, 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "43000", userStats.Result.AllTimeMinerPayments)
+
+	statsV2, err := fb.GetBundleStatsV2(ctx, resp.BundleHash, 10)
+	testutil.Ok(t, err)
+	testutil.Assert(t, statsV2.Result.IsSimulated, "bundle should be simulated")
+	testutil.Equals(t, 1, len(statsV2.Result.ConsideredByBuildersAt))
+
+	userStatsV2, err := fb.GetUserStatsV2(ctx, 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "43000", userStatsV2.Result.AllTimeValidatorPayments)
+
+	resp, err = fb.SendBundle(ctx, txsHex, 11, &flashbot.SendBundleOpts{ReplacementUuid: "uuid"})
+	testutil.Ok(t, err)
+	_, err = fb.CancelBundle(ctx, "uuid")
+	testutil.Ok(t, err)
+	stats, err = fb.GetBundleStats(ctx, resp.BundleHash, 11)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !stats.Result.IsSimulated, "cancelled bundle shouldn't be tracked")
+}
+
+func TestRelayPrivateTx(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(New(log.NewNopLogger(), Config{}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	fb, err := flashbot.New(privKey, &flashbot.Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	to := common.HexToAddress("0x1")
+	tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(1),
+		To:      &to,
+		Gas:     21000,
+	})
+	testutil.Ok(t, err)
+	txBin, err := tx.MarshalBinary()
+	testutil.Ok(t, err)
+
+	resp, err := fb.SendPrivateRawTransaction(ctx, hexutil.Encode(txBin), &flashbot.PrivateTxPreferences{Fast: true})
+	testutil.Ok(t, err)
+	testutil.Equals(t, tx.Hash().Hex(), resp.Result)
+
+	respC, err := fb.CancelPrivateTransaction(ctx, tx.Hash())
+	testutil.Ok(t, err)
+	testutil.Assert(t, respC.Result, "cancel should return true")
+}
+
+func TestRelaySignatureCheck(t *testing.T) {
+	srv := httptest.NewServer(New(log.NewNopLogger(), Config{}))
+	defer srv.Close()
+
+	fb, err := flashbot.New(nil, &flashbot.Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	_, err = fb.GetUserStats(context.Background(), 1)
+	testutil.NotOk(t, err)
+}
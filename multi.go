@@ -0,0 +1,340 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Multi sends requests to multiple relays at once.
+type Multi struct {
+	flashbots []Flashboter
+	// registry replaces the fixed flashbots list when set.
+	registry *RelayRegistry
+
+	// unhealthy is updated by the health checks.
+	mtx       sync.Mutex
+	unhealthy map[Flashboter]bool
+}
+
+func NewMultiRelay(flashbots ...Flashboter) *Multi {
+	return &Multi{
+		flashbots: flashbots,
+		unhealthy: make(map[Flashboter]bool),
+	}
+}
+
+// NewMultiRegistry creates a Multi which always uses the relays currently in the registry.
+func NewMultiRegistry(registry *RelayRegistry) *Multi {
+	return &Multi{
+		registry:  registry,
+		unhealthy: make(map[Flashboter]bool),
+	}
+}
+
+func (self *Multi) Flashbots() []Flashboter {
+	if self.registry != nil {
+		return self.registry.Flashbots()
+	}
+	return self.flashbots
+}
+
+// RelayError is the failure of a request to a single relay.
+type RelayError struct {
+	Relay string
+	Err   error
+}
+
+func (self *RelayError) Error() string {
+	return fmt.Sprintf("relay:%v: %v", self.Relay, self.Err)
+}
+
+func (self *RelayError) Unwrap() error {
+	return self.Err
+}
+
+// MultiError collects the failures of a request sent to multiple relays.
+type MultiError struct {
+	Errors []*RelayError
+	// Succeeded is the number of relays which accepted the request.
+	Succeeded int
+}
+
+func (self *MultiError) Error() string {
+	errs := make([]string, len(self.Errors))
+	for i, e := range self.Errors {
+		errs[i] = e.Error()
+	}
+	return fmt.Sprintf("%v of %v relays failed: %v", len(self.Errors), len(self.Errors)+self.Succeeded, strings.Join(errs, "; "))
+}
+
+// Partial reports whether at least one relay accepted the request.
+func (self *MultiError) Partial() bool {
+	return self.Succeeded > 0
+}
+
+type RelayResponse struct {
+	Relay string
+	Resp  *Response
+	Err   error
+}
+
+// SendBundleAll concurrently sends the bundle to all healthy relays.
+// When any of the relays fails the returned error is a *MultiError
+// and the responses of the successful relays are still returned.
+func (self *Multi) SendBundleAll(ctx context.Context, txsHex []string, blockNum uint64, opts *SendBundleOpts) ([]RelayResponse, error) {
+	flashbots := self.Healthy()
+	if len(flashbots) == 0 {
+		return nil, errors.New("no relays configured")
+	}
+	ctx, _ = ensureCorrelationID(ctx)
+
+	resps := make([]RelayResponse, len(flashbots))
+	var wg sync.WaitGroup
+	for i, f := range flashbots {
+		wg.Add(1)
+		go func(i int, f Flashboter) {
+			defer wg.Done()
+			resp, err := f.SendBundle(ctx, txsHex, blockNum, opts)
+			resps[i] = RelayResponse{Relay: f.Api().URL, Resp: resp, Err: err}
+		}(i, f)
+	}
+	wg.Wait()
+
+	multiErr := &MultiError{}
+	for _, r := range resps {
+		if r.Err != nil {
+			multiErr.Errors = append(multiErr.Errors, &RelayError{Relay: r.Relay, Err: r.Err})
+			continue
+		}
+		multiErr.Succeeded++
+	}
+	if len(multiErr.Errors) > 0 {
+		return resps, multiErr
+	}
+	return resps, nil
+}
+
+type RelayBundleStats struct {
+	Relay string
+	Stats *ResultBundleStats
+	Err   error
+}
+
+// BundleStatsAll is the merged view of the bundle stats reported by all relays.
+type BundleStatsAll struct {
+	IsSimulated    bool
+	IsHighPriority bool
+	// SimulatedBy and SentToMinersBy list the URLs of the relays that simulated and forwarded the bundle.
+	// A bundle considered by a builder counts as forwarded.
+	SimulatedBy    []string
+	SentToMinersBy []string
+	Relays         []RelayBundleStats
+}
+
+// GetBundleStatsAll concurrently queries the bundle stats from all relays that support it.
+// An error is returned only when none of the relays replied successfully.
+func (self *Multi) GetBundleStatsAll(ctx context.Context, bundleHash string, blockNum uint64) (*BundleStatsAll, error) {
+	ctx, _ = ensureCorrelationID(ctx)
+
+	var flashbots []Flashboter
+	for _, f := range self.Flashbots() {
+		if f.Api().SupportsStats {
+			flashbots = append(flashbots, f)
+		}
+	}
+	if len(flashbots) == 0 {
+		return nil, errors.New("none of the relays support bundle stats")
+	}
+
+	stats := make([]RelayBundleStats, len(flashbots))
+	var wg sync.WaitGroup
+	for i, f := range flashbots {
+		wg.Add(1)
+		go func(i int, f Flashboter) {
+			defer wg.Done()
+			resp, err := f.GetBundleStats(ctx, bundleHash, blockNum)
+			stats[i] = RelayBundleStats{Relay: f.Api().URL, Stats: resp, Err: err}
+		}(i, f)
+	}
+	wg.Wait()
+
+	all := &BundleStatsAll{Relays: stats}
+	multiErr := &MultiError{}
+	for _, s := range stats {
+		if s.Err != nil {
+			multiErr.Errors = append(multiErr.Errors, &RelayError{Relay: s.Relay, Err: s.Err})
+			continue
+		}
+		multiErr.Succeeded++
+		if s.Stats.Result.IsSimulated {
+			all.IsSimulated = true
+			all.SimulatedBy = append(all.SimulatedBy, s.Relay)
+		}
+		if s.Stats.Result.SentToMinersAt != nil || len(s.Stats.Result.ConsideredByBuildersAt) > 0 {
+			all.SentToMinersBy = append(all.SentToMinersBy, s.Relay)
+		}
+		all.IsHighPriority = all.IsHighPriority || s.Stats.Result.IsHighPriority
+	}
+	if !multiErr.Partial() {
+		return all, multiErr
+	}
+
+	return all, nil
+}
+
+// BroadcastBundle sends the bundle to the healthy relays in the order of their priority.
+// Relays with the same priority are sent concurrently and lower priorities
+// are sent after all relays with a higher priority replied.
+// It returns as soon as the weight of the relays which accepted the bundle reaches the quorum
+// while the rest of the submissions complete in the background with the same context.
+// When the quorum isn't reached the returned error is a *MultiError.
+// A quorum above the weight of all relays is a configuration error and
+// a quorum above the weight of the healthy relays fails with ErrQuorumUnreachable,
+// in both cases without sending the bundle.
+func (self *Multi) BroadcastBundle(ctx context.Context, txsHex []string, blockNum uint64, opts *SendBundleOpts, quorum int) ([]RelayResponse, error) {
+	if len(self.Flashbots()) == 0 {
+		return nil, errors.New("no relays configured")
+	}
+	if quorum <= 0 {
+		quorum = 1
+	}
+	if total := totalWeight(self.Flashbots()); quorum > total {
+		return nil, errors.Errorf("quorum:%v larger than the total relay weight:%v", quorum, total)
+	}
+	flashbots := self.Healthy()
+	if healthy := totalWeight(flashbots); quorum > healthy {
+		return nil, errors.Wrapf(ErrQuorumUnreachable, "quorum:%v healthy relay weight:%v", quorum, healthy)
+	}
+	ctx, _ = ensureCorrelationID(ctx)
+
+	sorted := make([]Flashboter, len(flashbots))
+	copy(sorted, flashbots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Api().Priority < sorted[j].Api().Priority
+	})
+
+	type result struct {
+		RelayResponse
+		weight int
+	}
+	results := make(chan result, len(sorted))
+	go func() {
+		for start := 0; start < len(sorted); {
+			end := start
+			for end < len(sorted) && sorted[end].Api().Priority == sorted[start].Api().Priority {
+				end++
+			}
+			var wg sync.WaitGroup
+			for _, f := range sorted[start:end] {
+				wg.Add(1)
+				go func(f Flashboter) {
+					defer wg.Done()
+					resp, err := f.SendBundle(ctx, txsHex, blockNum, opts)
+					results <- result{
+						RelayResponse: RelayResponse{Relay: f.Api().URL, Resp: resp, Err: err},
+						weight:        relayWeight(f),
+					}
+				}(f)
+			}
+			wg.Wait()
+			start = end
+		}
+	}()
+
+	var (
+		resps    []RelayResponse
+		weight   int
+		multiErr = &MultiError{}
+	)
+	for range sorted {
+		r := <-results
+		resps = append(resps, r.RelayResponse)
+		if r.Err != nil {
+			multiErr.Errors = append(multiErr.Errors, &RelayError{Relay: r.Relay, Err: r.Err})
+			continue
+		}
+		multiErr.Succeeded++
+		weight += r.weight
+		if weight >= quorum {
+			return resps, nil
+		}
+	}
+	return resps, multiErr
+}
+
+// ErrQuorumUnreachable is returned by BroadcastBundle when
+// the healthy relays can't reach the quorum even if all of them accept the bundle.
+var ErrQuorumUnreachable = errors.New("quorum unreachable with the healthy relays")
+
+func relayWeight(f Flashboter) int {
+	if w := f.Api().Weight; w > 0 {
+		return w
+	}
+	return 1
+}
+
+func totalWeight(flashbots []Flashboter) int {
+	total := 0
+	for _, f := range flashbots {
+		total += relayWeight(f)
+	}
+	return total
+}
+
+// SendBundleFirst sends the bundle to all healthy relays at once and
+// returns as soon as the first relay accepts it.
+// The outcomes of the other relays are delivered over the returned channel
+// which is closed once all of them replied.
+// When all relays fail the returned error is a *MultiError.
+func (self *Multi) SendBundleFirst(ctx context.Context, txsHex []string, blockNum uint64, opts *SendBundleOpts) (*RelayResponse, <-chan RelayResponse, error) {
+	flashbots := self.Healthy()
+	if len(flashbots) == 0 {
+		return nil, nil, errors.New("no relays configured")
+	}
+	ctx, _ = ensureCorrelationID(ctx)
+
+	results := make(chan RelayResponse, len(flashbots))
+	for _, f := range flashbots {
+		go func(f Flashboter) {
+			resp, err := f.SendBundle(ctx, txsHex, blockNum, opts)
+			results <- RelayResponse{Relay: f.Api().URL, Resp: resp, Err: err}
+		}(f)
+	}
+
+	// The failures before the first success are delivered with the other outcomes.
+	var failed []RelayResponse
+	multiErr := &MultiError{}
+	for i := range flashbots {
+		r := <-results
+		if r.Err != nil {
+			failed = append(failed, r)
+			multiErr.Errors = append(multiErr.Errors, &RelayError{Relay: r.Relay, Err: r.Err})
+			continue
+		}
+
+		remaining := len(flashbots) - i - 1
+		rest := make(chan RelayResponse, len(flashbots)-1)
+		for _, f := range failed {
+			rest <- f
+		}
+		go func() {
+			defer close(rest)
+			for j := 0; j < remaining; j++ {
+				rest <- <-results
+			}
+		}()
+		return &r, rest, nil
+	}
+
+	rest := make(chan RelayResponse)
+	close(rest)
+	return nil, rest, multiErr
+}
@@ -0,0 +1,236 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/flashbot/mockrelay"
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+func TestGetBundleStatsAll(t *testing.T) {
+	ctx := context.Background()
+
+	srvSent := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srvSent.Close()
+	srvOther := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srvOther.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbots, err := NewMulti(1, privKey,
+		&Api{URL: srvSent.URL, SupportsStats: true},
+		&Api{URL: srvOther.URL, SupportsStats: true},
+		&Api{URL: "http://127.0.0.1:0"},
+	)
+	testutil.Ok(t, err)
+	multi := NewMultiRelay(flashbots...)
+
+	txHex := signedTxHex(t)
+	resp, err := flashbots[0].SendBundle(ctx, []string{txHex}, 10, nil)
+	testutil.Ok(t, err)
+
+	stats, err := multi.GetBundleStatsAll(ctx, resp.BundleHash, 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(stats.Relays))
+	testutil.Assert(t, stats.IsSimulated, "bundle should be simulated")
+	testutil.Equals(t, []string{srvSent.URL}, stats.SimulatedBy)
+	testutil.Equals(t, []string{srvSent.URL}, stats.SentToMinersBy)
+}
+
+func TestSendBundleAll(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(mockrelay.New(log.NewNopLogger(), mockrelay.Config{}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbots, err := NewMulti(1, privKey,
+		&Api{URL: srv.URL},
+		&Api{URL: "http://127.0.0.1:0"},
+	)
+	testutil.Ok(t, err)
+	multi := NewMultiRelay(flashbots...)
+
+	resps, err := multi.SendBundleAll(ctx, []string{signedTxHex(t)}, 10, nil)
+	testutil.NotOk(t, err)
+	multiErr := &MultiError{}
+	testutil.Assert(t, errors.As(err, &multiErr), "error should be a MultiError")
+	testutil.Assert(t, multiErr.Partial(), "one relay should succeed")
+	testutil.Equals(t, 1, len(multiErr.Errors))
+	testutil.Equals(t, "http://127.0.0.1:0", multiErr.Errors[0].Relay)
+
+	testutil.Equals(t, 2, len(resps))
+	testutil.Ok(t, resps[0].Err)
+	testutil.Assert(t, resps[0].Resp.BundleHash != "", "bundle hash should be set")
+}
+
+func TestBroadcastBundle(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mtx   sync.Mutex
+		order []string
+	)
+	newSrv := func(name string, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mtx.Lock()
+			order = append(order, name)
+			mtx.Unlock()
+			w.WriteHeader(status)
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+			testutil.Ok(t, err)
+		}))
+	}
+	srvFirst := newSrv("first", http.StatusOK)
+	defer srvFirst.Close()
+	srvFail := newSrv("fail", http.StatusInternalServerError)
+	defer srvFail.Close()
+	srvLast := newSrv("last", http.StatusOK)
+	defer srvLast.Close()
+
+	flashbots, err := NewMulti(1, nil,
+		&Api{URL: srvLast.URL, Priority: 1, SkipFlashbotsSignature: true},
+		&Api{URL: srvFirst.URL, SkipFlashbotsSignature: true},
+		&Api{URL: srvFail.URL, SkipFlashbotsSignature: true},
+	)
+	testutil.Ok(t, err)
+	multi := NewMultiRelay(flashbots...)
+
+	resps, err := multi.BroadcastBundle(ctx, []string{"0x1"}, 10, nil, 2)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(resps))
+	testutil.Equals(t, srvLast.URL, resps[2].Relay)
+	testutil.Equals(t, "last", order[2])
+
+	// Quorum can't be reached with only 2 accepting relays.
+	_, err = multi.BroadcastBundle(ctx, []string{"0x1"}, 10, nil, 3)
+	multiErr := &MultiError{}
+	testutil.Assert(t, errors.As(err, &multiErr), "error should be a MultiError")
+	testutil.Equals(t, 2, multiErr.Succeeded)
+
+	// A quorum above the weight of the relays isn't sent.
+	sent := len(order)
+	_, err = multi.BroadcastBundle(ctx, []string{"0x1"}, 10, nil, 4)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, !errors.As(err, &multiErr), "configuration error shouldn't be a MultiError:%v", err)
+	multi.mtx.Lock()
+	multi.unhealthy[flashbots[2]] = true
+	multi.mtx.Unlock()
+	_, err = multi.BroadcastBundle(ctx, []string{"0x1"}, 10, nil, 3)
+	testutil.Assert(t, errors.Is(err, ErrQuorumUnreachable), "unexpected error:%v", err)
+	testutil.Equals(t, sent, len(order))
+	multi.mtx.Lock()
+	delete(multi.unhealthy, flashbots[2])
+	multi.mtx.Unlock()
+
+	// The weight of a relay counts towards the quorum.
+	flashbots[1].Api().Weight = 2
+	resps, err = multi.BroadcastBundle(ctx, []string{"0x1"}, 10, nil, 2)
+	testutil.Ok(t, err)
+	testutil.Assert(t, len(resps) <= 2, "shouldn't wait for the lower priority relay")
+}
+
+func TestSendBundleFirst(t *testing.T) {
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	srvFast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srvFast.Close()
+	srvSlow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x2"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srvSlow.Close()
+
+	flashbots, err := NewMulti(1, nil,
+		&Api{URL: srvSlow.URL, SkipFlashbotsSignature: true},
+		&Api{URL: "http://127.0.0.1:0", SkipFlashbotsSignature: true},
+		&Api{URL: srvFast.URL, SkipFlashbotsSignature: true},
+	)
+	testutil.Ok(t, err)
+	multi := NewMultiRelay(flashbots...)
+
+	first, rest, err := multi.SendBundleFirst(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, srvFast.URL, first.Relay)
+	testutil.Equals(t, "0x1", first.Resp.BundleHash)
+
+	close(release)
+	var others []RelayResponse
+	for r := range rest {
+		others = append(others, r)
+	}
+	testutil.Equals(t, 2, len(others))
+	testutil.Equals(t, srvSlow.URL, others[len(others)-1].Relay)
+}
+
+func TestSendBundleFirstEarlierFailure(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reply after the unreachable relay failed.
+		time.Sleep(100 * time.Millisecond)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbots, err := NewMulti(1, nil,
+		&Api{URL: "http://127.0.0.1:0", SkipFlashbotsSignature: true},
+		&Api{URL: srv.URL, SkipFlashbotsSignature: true},
+	)
+	testutil.Ok(t, err)
+	multi := NewMultiRelay(flashbots...)
+
+	first, rest, err := multi.SendBundleFirst(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, srv.URL, first.Relay)
+
+	var others []RelayResponse
+	for r := range rest {
+		others = append(others, r)
+	}
+	testutil.Equals(t, 1, len(others))
+	testutil.Equals(t, "http://127.0.0.1:0", others[0].Relay)
+	testutil.NotOk(t, others[0].Err)
+}
+
+func signedTxHex(t *testing.T) string {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	to := common.HexToAddress("0x1")
+	tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		To:        &to,
+		Gas:       21000,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+	})
+	testutil.Ok(t, err)
+	raw, err := tx.MarshalBinary()
+	testutil.Ok(t, err)
+	return hexutil.Encode(raw)
+}
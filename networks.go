@@ -0,0 +1,174 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Network is the relay setup of a chain used by DefaultApi and the other defaults.
+type Network struct {
+	ID   int64
+	Name string
+	// RelayURL is the relay of the Api returned by DefaultApi.
+	RelayURL           string
+	SupportsSimulation bool
+	SupportsStats      bool
+	// MevShareEventsURL and ProtectStatusURL are optional.
+	MevShareEventsURL string
+	ProtectStatusURL  string
+	// Configure adapts the api to relays with their own schema or authentication.
+	Configure func(*Api)
+}
+
+// Api returns the default api for the network.
+func (self Network) Api() *Api {
+	api := &Api{
+		URL:                self.RelayURL,
+		SupportsSimulation: self.SupportsSimulation,
+		SupportsStats:      self.SupportsStats,
+	}
+	if self.Configure != nil {
+		self.Configure(api)
+	}
+	return api
+}
+
+var networks = struct {
+	mtx sync.RWMutex
+	ids map[int64]Network
+}{ids: make(map[int64]Network)}
+
+func init() {
+	for _, n := range []Network{
+		{
+			ID:                 1,
+			Name:               "mainnet",
+			RelayURL:           "https://relay.flashbots.net",
+			SupportsSimulation: true,
+			SupportsStats:      true,
+			MevShareEventsURL:  "https://mev-share.flashbots.net",
+			ProtectStatusURL:   "https://protect.flashbots.net/tx",
+		},
+		{
+			// Goerli is deprecated, use Sepolia for new deployments.
+			ID:                 5,
+			Name:               "goerli",
+			RelayURL:           "https://relay-goerli.flashbots.net",
+			SupportsSimulation: true,
+			SupportsStats:      true,
+			MevShareEventsURL:  "https://mev-share-goerli.flashbots.net",
+			ProtectStatusURL:   "https://protect-goerli.flashbots.net/tx",
+		},
+		{
+			ID:                 11155111,
+			Name:               "sepolia",
+			RelayURL:           "https://relay-sepolia.flashbots.net",
+			SupportsSimulation: true,
+			SupportsStats:      true,
+			MevShareEventsURL:  "https://mev-share-sepolia.flashbots.net",
+		},
+		{
+			ID:                 17000,
+			Name:               "holesky",
+			RelayURL:           "https://relay-holesky.flashbots.net",
+			SupportsSimulation: true,
+			SupportsStats:      true,
+			MevShareEventsURL:  "https://mev-share-holesky.flashbots.net",
+		},
+		// Polygon (137) isn't registered as its only relay needs
+		// a bloXroute account, see BloxrouteNetworkApi.
+		{
+			ID:        56,
+			Name:      "bsc",
+			RelayURL:  Club48URL,
+			Configure: configureClub48,
+		},
+	} {
+		if err := RegisterNetwork(n); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterNetwork adds the network or replaces an existing one with the same id,
+// for example to use private devnets and forks with the defaults of this package.
+// It is meant to be called at init time before creating any instances.
+func RegisterNetwork(n Network) error {
+	if n.ID == 0 {
+		return errors.New("network id is required")
+	}
+	if n.RelayURL == "" {
+		return errors.Errorf("relay url is required id:%v", n.ID)
+	}
+	if n.Name == "" {
+		n.Name = fmt.Sprint(n.ID)
+	}
+	networks.mtx.Lock()
+	defer networks.mtx.Unlock()
+	networks.ids[n.ID] = n
+	return nil
+}
+
+// LookupNetwork returns the registered network with the id.
+func LookupNetwork(netID int64) (Network, bool) {
+	networks.mtx.RLock()
+	defer networks.mtx.RUnlock()
+	n, ok := networks.ids[netID]
+	return n, ok
+}
+
+// Networks returns all registered networks ordered by their id.
+func Networks() []Network {
+	networks.mtx.RLock()
+	defer networks.mtx.RUnlock()
+	ns := make([]Network, 0, len(networks.ids))
+	for _, n := range networks.ids {
+		ns = append(ns, n)
+	}
+	sort.Slice(ns, func(i, j int) bool { return ns[i].ID < ns[j].ID })
+	return ns
+}
+
+// network returns the registered network or an error listing the supported ones.
+func network(netID int64) (Network, error) {
+	if n, ok := LookupNetwork(netID); ok {
+		return n, nil
+	}
+	var supported []string
+	for _, n := range Networks() {
+		supported = append(supported, fmt.Sprintf("%v (%v)", n.ID, n.Name))
+	}
+	return Network{}, errors.Errorf("network id not supported id:%v supported:%v", netID, strings.Join(supported, ", "))
+}
+
+// ChainIDReader is implemented by ethclient.Client.
+type ChainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// NewFromClient creates an instance for the default relay of the network
+// the node is connected to.
+func NewFromClient(ctx context.Context, client ChainIDReader, prvKey *ecdsa.PrivateKey, opts ...Option) (Flashboter, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting chain id")
+	}
+	if !chainID.IsInt64() {
+		return nil, errors.Errorf("network id not supported id:%v", chainID)
+	}
+	api, err := DefaultApi(chainID.Int64())
+	if err != nil {
+		return nil, err
+	}
+	return New(prvKey, api, opts...)
+}
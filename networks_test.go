@@ -0,0 +1,56 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRegisterNetwork(t *testing.T) {
+	testutil.NotOk(t, RegisterNetwork(Network{RelayURL: "http://localhost:8545"}))
+	testutil.NotOk(t, RegisterNetwork(Network{ID: 31337}))
+
+	_, err := DefaultApi(31337)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "1 (mainnet)"), "error should list the supported networks:%v", err)
+
+	testutil.Ok(t, RegisterNetwork(Network{ID: 31337, RelayURL: "http://localhost:8545", SupportsSimulation: true}))
+	api, err := DefaultApi(31337)
+	testutil.Ok(t, err)
+	testutil.Equals(t, &Api{URL: "http://localhost:8545", SupportsSimulation: true}, api)
+
+	n, ok := LookupNetwork(31337)
+	testutil.Assert(t, ok, "network should be registered")
+	testutil.Equals(t, "31337", n.Name)
+	_, err = MevShareEventsURLDefault(31337)
+	testutil.NotOk(t, err)
+
+	ns := Networks()
+	testutil.Equals(t, int64(1), ns[0].ID)
+	testutil.Equals(t, int64(31337), ns[len(ns)-2].ID)
+}
+
+type chainIDMock int64
+
+func (self chainIDMock) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(int64(self)), nil
+}
+
+func TestNewFromClient(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := NewFromClient(context.Background(), chainIDMock(11155111), privKey)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "https://relay-sepolia.flashbots.net", flashbot.Api().URL)
+
+	_, err = NewFromClient(context.Background(), chainIDMock(10), privKey)
+	testutil.Assert(t, err != nil && strings.Contains(err.Error(), "id:10 supported:"), "unexpected error:%v", err)
+}
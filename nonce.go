@@ -0,0 +1,183 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ErrNonceGap is returned by NonceManager.Sync when a released nonce
+// below the pending ones is still unused so the pending transactions can't be included.
+var ErrNonceGap = errors.New("nonce gap")
+
+// NonceReader reads the nonce of an account, an ethclient.Client can be used directly.
+type NonceReader interface {
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// NonceGapError lists the unused nonces of an account.
+type NonceGapError struct {
+	Account common.Address
+	Missing []uint64
+}
+
+func (self *NonceGapError) Error() string {
+	return fmt.Sprintf("%v account:%v missing:%v", ErrNonceGap, self.Account.Hex(), self.Missing)
+}
+
+func (self *NonceGapError) Is(target error) bool {
+	return target == ErrNonceGap
+}
+
+// NonceManager hands out the nonces of the bot accounts across bundle submissions.
+// Bundle transactions never reach the mempool so the pending nonce of the node
+// doesn't account for bundles which are still being submitted.
+// Nonces stay pending until the chain nonce passes them or they are released.
+type NonceManager struct {
+	client NonceReader
+
+	mtx      sync.Mutex
+	accounts map[common.Address]*accountNonces
+}
+
+type accountNonces struct {
+	mtx    sync.Mutex
+	loaded bool
+	// base is the chain nonce at the last sync.
+	base    uint64
+	next    uint64
+	pending map[uint64]bool
+}
+
+func NewNonceManager(client NonceReader) *NonceManager {
+	return &NonceManager{
+		client:   client,
+		accounts: make(map[common.Address]*accountNonces),
+	}
+}
+
+func (self *NonceManager) account(account common.Address) *accountNonces {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	a, ok := self.accounts[account]
+	if !ok {
+		a = &accountNonces{pending: make(map[uint64]bool)}
+		self.accounts[account] = a
+	}
+	return a
+}
+
+// Next returns the lowest nonce of the account which isn't pending
+// and marks it as pending.
+// The chain nonce is read on first use of the account.
+func (self *NonceManager) Next(ctx context.Context, account common.Address) (uint64, error) {
+	a := self.account(account)
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if !a.loaded {
+		if err := self.sync(ctx, account, a); err != nil {
+			return 0, err
+		}
+	}
+
+	nonce := a.base
+	for a.pending[nonce] {
+		nonce++
+	}
+	a.pending[nonce] = true
+	if nonce >= a.next {
+		a.next = nonce + 1
+	}
+	return nonce, nil
+}
+
+// Release returns a nonce which won't be used,
+// for example when the bundle wasn't included in any of its target blocks.
+// Releasing a nonce below other pending nonces leaves a gap
+// which is filled by the next call to Next.
+func (self *NonceManager) Release(account common.Address, nonce uint64) {
+	a := self.account(account)
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	delete(a.pending, nonce)
+	for a.next > a.base && !a.pending[a.next-1] {
+		a.next--
+	}
+}
+
+// Pending returns the nonces handed out for the account which aren't yet on chain.
+func (self *NonceManager) Pending(account common.Address) []uint64 {
+	a := self.account(account)
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	nonces := make([]uint64, 0, len(a.pending))
+	for n := range a.pending {
+		nonces = append(nonces, n)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	return nonces
+}
+
+// Sync reads the chain nonce of the account and forgets the pending nonces which were included.
+// The returned error wraps ErrNonceGap when nonces between the chain nonce
+// and the highest pending nonce are unused.
+func (self *NonceManager) Sync(ctx context.Context, account common.Address) error {
+	a := self.account(account)
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	if err := self.sync(ctx, account, a); err != nil {
+		return err
+	}
+
+	var missing []uint64
+	for n := a.base; n < a.next; n++ {
+		if !a.pending[n] {
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		return &NonceGapError{Account: account, Missing: missing}
+	}
+	return nil
+}
+
+// Reset forgets all pending nonces of the account and starts again from the chain nonce.
+func (self *NonceManager) Reset(ctx context.Context, account common.Address) error {
+	a := self.account(account)
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.pending = make(map[uint64]bool)
+	a.next = 0
+	return self.sync(ctx, account, a)
+}
+
+func (self *NonceManager) sync(ctx context.Context, account common.Address, a *accountNonces) error {
+	nonce, err := self.client.NonceAt(ctx, account, nil)
+	if err != nil {
+		return errors.Wrapf(err, "reading nonce account:%v", account.Hex())
+	}
+	for n := range a.pending {
+		if n < nonce {
+			delete(a.pending, n)
+		}
+	}
+	a.base = nonce
+	if a.next < nonce {
+		a.next = nonce
+	}
+	a.loaded = true
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+type nonceReader struct {
+	mtx    sync.Mutex
+	nonces map[common.Address]uint64
+}
+
+func (self *nonceReader) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return self.nonces[account], nil
+}
+
+func (self *nonceReader) set(account common.Address, nonce uint64) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.nonces[account] = nonce
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	account := common.HexToAddress("0x1")
+	other := common.HexToAddress("0x2")
+	client := &nonceReader{nonces: map[common.Address]uint64{account: 5}}
+	nm := NewNonceManager(client)
+
+	next := func(account common.Address) uint64 {
+		n, err := nm.Next(ctx, account)
+		testutil.Ok(t, err)
+		return n
+	}
+
+	testutil.Equals(t, uint64(5), next(account))
+	testutil.Equals(t, uint64(6), next(account))
+	testutil.Equals(t, uint64(7), next(account))
+	testutil.Equals(t, uint64(0), next(other))
+	testutil.Equals(t, []uint64{5, 6, 7}, nm.Pending(account))
+
+	// The bundle with the last nonce was dropped so the nonce is reused.
+	nm.Release(account, 7)
+	testutil.Equals(t, uint64(7), next(account))
+
+	// Releasing a nonce in the middle leaves a gap.
+	nm.Release(account, 6)
+	err := nm.Sync(ctx, account)
+	var gapErr *NonceGapError
+	testutil.Assert(t, errors.As(err, &gapErr), "expected a gap error got:%v", err)
+	testutil.Assert(t, errors.Is(err, ErrNonceGap), "unexpected error:%v", err)
+	testutil.Equals(t, []uint64{6}, gapErr.Missing)
+
+	// The gap is filled first.
+	testutil.Equals(t, uint64(6), next(account))
+	testutil.Ok(t, nm.Sync(ctx, account))
+
+	// Included nonces are no longer pending.
+	client.set(account, 7)
+	testutil.Ok(t, nm.Sync(ctx, account))
+	testutil.Equals(t, []uint64{7}, nm.Pending(account))
+	testutil.Equals(t, uint64(8), next(account))
+
+	// Transactions sent outside of the manager move the nonce forward.
+	client.set(account, 20)
+	testutil.Ok(t, nm.Sync(ctx, account))
+	testutil.Equals(t, 0, len(nm.Pending(account)))
+	testutil.Equals(t, uint64(20), next(account))
+
+	// None of the bundles landed.
+	testutil.Equals(t, uint64(21), next(account))
+	testutil.Ok(t, nm.Reset(ctx, account))
+	testutil.Equals(t, 0, len(nm.Pending(account)))
+	testutil.Equals(t, uint64(20), next(account))
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	ctx := context.Background()
+	account := common.HexToAddress("0x1")
+	nm := NewNonceManager(&nonceReader{nonces: map[common.Address]uint64{}})
+
+	const count = 50
+	nonces := make([]uint64, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n, err := nm.Next(ctx, account)
+			testutil.Ok(t, err)
+			nonces[i] = n
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[uint64]bool)
+	for _, n := range nonces {
+		testutil.Assert(t, !seen[n], "duplicate nonce:%v", n)
+		seen[n] = true
+	}
+	testutil.Equals(t, count, len(nm.Pending(account)))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MethodSendRawTransaction is the standard method accepted by private RPC endpoints.
+const MethodSendRawTransaction = "eth_sendRawTransaction"
+
+// PrivateRPCApi creates the api for an authenticated endpoint,
+// for example of an L2 sequencer or a private RPC provider,
+// which treats plain eth_sendRawTransaction as its private path.
+//
+// This is a degraded mode where only SendPrivateTransaction and SendPrivateRawTransaction
+// are supported. The max block and the preferences can't be expressed
+// with eth_sendRawTransaction so they are dropped.
+// The headers, usually an api key, authenticate the requests instead of the flashbots signature.
+func PrivateRPCApi(url string, headers map[string]string) *Api {
+	return &Api{
+		URL: url,
+		Methods: map[string]string{
+			MethodSendPrivateTransaction:    MethodSendRawTransaction,
+			MethodSendPrivateRawTransaction: MethodSendRawTransaction,
+		},
+		SupportedMethods:       []string{MethodSendPrivateTransaction, MethodSendPrivateRawTransaction},
+		CustomHeaders:          headers,
+		SkipFlashbotsSignature: true,
+		ParamsTransform:        privateRPCParams,
+	}
+}
+
+func privateRPCParams(method string, params []interface{}) (interface{}, error) {
+	if len(params) == 0 {
+		return nil, errors.New("missing params")
+	}
+	switch p := params[0].(type) {
+	case ParamsPrivateTransaction:
+		return []interface{}{p.Tx}, nil
+	case string:
+		// The raw variant passes the tx followed by the optional preferences.
+		return []interface{}{p}, nil
+	default:
+		return nil, errors.Errorf("method not supported by private rpc:%v", method)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestPrivateRPC(t *testing.T) {
+	var (
+		msg    *jsonrpcMessage
+		header http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg = &jsonrpcMessage{}
+		header = r.Header
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, PrivateRPCApi(srv.URL, map[string]string{"x-api-key": "key"}))
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	resp, err := flashbot.SendPrivateTransaction(ctx, "0xaa", 10, &PrivateTxPreferences{Fast: true})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.Result)
+	testutil.Equals(t, MethodSendRawTransaction, msg.Method)
+	testutil.Equals(t, `["0xaa"]`, string(msg.Params))
+	testutil.Equals(t, "key", header.Get("x-api-key"))
+	testutil.Equals(t, "", header.Get("X-Flashbots-Signature"))
+
+	resp, err = flashbot.SendPrivateRawTransaction(ctx, "0xbb", &PrivateTxPreferences{Fast: true})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0x1", resp.Result)
+	testutil.Equals(t, `["0xbb"]`, string(msg.Params))
+
+	_, err = flashbot.SendBundle(ctx, []string{"0xaa"}, 10, nil)
+	testutil.Assert(t, errors.Is(err, ErrMethodNotSupported), "unexpected error:%v", err)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const privateTxPollIntervalDefault = 2 * time.Second
+
+func ProtectStatusURLDefault(netID int64) (string, error) {
+	n, err := network(netID)
+	if err != nil {
+		return "", err
+	}
+	if n.ProtectStatusURL == "" {
+		return "", errors.Errorf("network has no protect status url id:%v", netID)
+	}
+	return n.ProtectStatusURL, nil
+}
+
+// PrivateTxStatus is the state of a private transaction.
+type PrivateTxStatus string
+
+const (
+	PrivateTxPending   PrivateTxStatus = "PENDING"
+	PrivateTxIncluded  PrivateTxStatus = "INCLUDED"
+	PrivateTxFailed    PrivateTxStatus = "FAILED"
+	PrivateTxCancelled PrivateTxStatus = "CANCELLED"
+	PrivateTxUnknown   PrivateTxStatus = "UNKNOWN"
+	// PrivateTxExpired is set by WaitForPrivateTx when the max block passes without inclusion.
+	PrivateTxExpired PrivateTxStatus = "EXPIRED"
+)
+
+// ProtectTxStatus is the reply of the Protect status API.
+type ProtectTxStatus struct {
+	Status         PrivateTxStatus `json:"status"`
+	Hash           common.Hash     `json:"hash"`
+	MaxBlockNumber uint64          `json:"maxBlockNumber"`
+	FastMode       bool            `json:"fastMode"`
+	SeenInMempool  bool            `json:"seenInMempool"`
+}
+
+// GetProtectTxStatus reads the status of a private transaction from the Protect status API.
+func GetProtectTxStatus(ctx context.Context, client *http.Client, statusURL string, hash common.Hash) (*ProtectTxStatus, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(statusURL, "/")+"/"+hash.Hex(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating request")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "status request")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, errors.Errorf("bad response status %v", resp.Status)
+	}
+
+	status := &ProtectTxStatus{}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return nil, errors.Wrap(err, "decoding status")
+	}
+	status.Status = PrivateTxStatus(strings.ToUpper(string(status.Status)))
+	return status, nil
+}
+
+// WaitPrivateTxConfig configures WaitForPrivateTx.
+type WaitPrivateTxConfig struct {
+	// StatusURL is the Protect status API, see ProtectStatusURLDefault.
+	// When empty only the chain is polled.
+	StatusURL string
+	// Client for the status API, defaults to http.DefaultClient.
+	Client *http.Client
+	// MaxBlock is the maxBlockNumber of the submission,
+	// when 0 the one reported by the status API is used.
+	MaxBlock uint64
+	// PollInterval defaults to 2s.
+	PollInterval time.Duration
+}
+
+// PrivateTxResult is the terminal state of a private transaction.
+type PrivateTxResult struct {
+	Status PrivateTxStatus
+	// Receipt is set when the transaction is included,
+	// it can still have reverted so check its status.
+	Receipt *types.Receipt
+	// LastErr is the last failed call to the status API,
+	// failures don't stop the polling.
+	LastErr error
+}
+
+// WaitForPrivateTx polls the status API and the chain until the transaction is included,
+// the relay reports it as failed or cancelled or its max block passes.
+// Without a max block from the config or the status API it waits until the context is done.
+// An error is returned only when the chain can't be read or the context is done.
+func WaitForPrivateTx(ctx context.Context, chain ChainReader, hash common.Hash, cfg WaitPrivateTxConfig) (*PrivateTxResult, error) {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = privateTxPollIntervalDefault
+	}
+	maxBlock := cfg.MaxBlock
+	result := &PrivateTxResult{}
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		receipt, err := chain.TransactionReceipt(ctx, hash)
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return result, errors.Wrapf(err, "reading receipt tx:%v", hash.Hex())
+		}
+		if err == nil && receipt != nil {
+			result.Status = PrivateTxIncluded
+			result.Receipt = receipt
+			return result, nil
+		}
+
+		if cfg.StatusURL != "" {
+			status, err := GetProtectTxStatus(ctx, cfg.Client, cfg.StatusURL, hash)
+			if err != nil {
+				result.LastErr = err
+			} else {
+				switch status.Status {
+				case PrivateTxFailed, PrivateTxCancelled:
+					result.Status = status.Status
+					return result, nil
+				}
+				if maxBlock == 0 {
+					maxBlock = status.MaxBlockNumber
+				}
+			}
+		}
+
+		if maxBlock != 0 {
+			head, err := chain.BlockNumber(ctx)
+			if err != nil {
+				return result, errors.Wrap(err, "reading block number")
+			}
+			if head > maxBlock {
+				// The max block might have been mined after the receipt was read.
+				receipt, err := chain.TransactionReceipt(ctx, hash)
+				if err != nil && !errors.Is(err, ethereum.NotFound) {
+					return result, errors.Wrapf(err, "reading receipt tx:%v", hash.Hex())
+				}
+				if err == nil && receipt != nil {
+					result.Status = PrivateTxIncluded
+					result.Receipt = receipt
+					return result, nil
+				}
+				result.Status = PrivateTxExpired
+				return result, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestWaitForPrivateTx(t *testing.T) {
+	var (
+		mtx    sync.Mutex
+		status = `{"status":"PENDING","maxBlockNumber":12}`
+		paths  []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		paths = append(paths, r.URL.Path)
+		_, err := w.Write([]byte(status))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	cfg := WaitPrivateTxConfig{StatusURL: srv.URL + "/tx/", PollInterval: time.Millisecond}
+
+	// Included.
+	txHex := signedTxHex(t)
+	tx, err := decodeTx(txHex)
+	testutil.Ok(t, err)
+	chain := &chainMock{head: 10, included: make(map[common.Hash]bool)}
+	chain.include(t, []string{txHex})
+	res, err := WaitForPrivateTx(ctx, chain, tx.Hash(), cfg)
+	testutil.Ok(t, err)
+	testutil.Equals(t, PrivateTxIncluded, res.Status)
+	testutil.Equals(t, tx.Hash(), res.Receipt.TxHash)
+
+	// Expired after the max block reported by the status API.
+	chain = &chainMock{head: 10, advance: true, included: make(map[common.Hash]bool)}
+	res, err = WaitForPrivateTx(ctx, chain, tx.Hash(), cfg)
+	testutil.Ok(t, err)
+	testutil.Equals(t, PrivateTxExpired, res.Status)
+	testutil.Equals(t, "/tx/"+tx.Hash().Hex(), paths[0])
+
+	// Failed on the relay.
+	mtx.Lock()
+	status = `{"status":"FAILED"}`
+	mtx.Unlock()
+	res, err = WaitForPrivateTx(ctx, chain, tx.Hash(), cfg)
+	testutil.Ok(t, err)
+	testutil.Equals(t, PrivateTxFailed, res.Status)
+
+	// Without a max block it waits until the context is done.
+	ctx, cncl := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cncl()
+	res, err = WaitForPrivateTx(ctx, chain, tx.Hash(), WaitPrivateTxConfig{PollInterval: time.Millisecond})
+	testutil.NotOk(t, err)
+	testutil.Equals(t, PrivateTxStatus(""), res.Status)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// probeParams are harmless params for each probed method which
+// the relay rejects or ignores without any side effects.
+var probeParams = []struct {
+	method string
+	params []interface{}
+}{
+	{MethodSendBundle, []interface{}{ParamsSend{BlockNum: "0x0"}}},
+	{MethodCallBundle, []interface{}{ParamsCall{BlockNum: "0x0", StateBlockNum: "latest"}}},
+	{MethodCancelBundle, []interface{}{ParamsCancelBundle{ReplacementUuid: "00000000-0000-0000-0000-000000000000"}}},
+	{MethodEstimateGasBundle, []interface{}{ParamsEstimateGas{BlockNum: "0x0", StateBlockNum: "latest"}}},
+	{MethodSendPrivateTransaction, []interface{}{ParamsPrivateTransaction{Tx: "0x"}}},
+	{MethodSendPrivateRawTransaction, []interface{}{"0x"}},
+	{MethodCancelPrivateTransaction, []interface{}{ParamsCancelPrivateTransaction{TxHash: common.Hash{}.Hex()}}},
+	{MethodGetBundleStats, []interface{}{ParamsStats{BundleHash: common.Hash{}.Hex(), BlockNum: "0x0"}}},
+	{MethodGetUserStats, []interface{}{"0x0"}},
+}
+
+// Probe discovers which methods the relay supports by calling each of them
+// with harmless params and checking for "unknown method" errors.
+// It returns the supported probed methods and replaces the api of the instance with a copy
+// in which the methods reported as unknown are in UnsupportedMethods,
+// the methods which aren't probed keep their previous support.
+// The api passed to New isn't changed, the result is read with Api.
+func (self *Flashbot) Probe(ctx context.Context) ([]string, error) {
+	ctx, _ = ensureCorrelationID(ctx)
+	url := self.url(ctx)
+
+	var supported, unknown []string
+	for _, p := range probeParams {
+		ok, err := self.probeMethod(ctx, url, p.method, p.params)
+		if err != nil {
+			return nil, errors.Wrapf(err, "probing method:%v", p.method)
+		}
+		if ok {
+			supported = append(supported, p.method)
+		} else {
+			unknown = append(unknown, p.method)
+		}
+	}
+
+	self.apiMtx.Lock()
+	defer self.apiMtx.Unlock()
+	api := *self.api
+	api.UnsupportedMethods = unknown
+	if api.SupportedMethods != nil {
+		methods := append([]string(nil), api.SupportedMethods...)
+		for _, m := range supported {
+			if !api.Supports(m) {
+				methods = append(methods, m)
+			}
+		}
+		api.SupportedMethods = methods
+	}
+	api.SupportsSimulation = api.Supports(MethodCallBundle)
+	api.SupportsStats = api.Supports(MethodGetBundleStats)
+	self.api = &api
+	return supported, nil
+}
+
+func (self *Flashbot) probeMethod(ctx context.Context, url, method string, params []interface{}) (bool, error) {
+	api := self.Api()
+	if api.ParamsTransform != nil {
+		transformed, err := api.ParamsTransform(method, params)
+		if err != nil {
+			// The transform only knows the methods the relay supports.
+			return false, nil
+		}
+		return self.probeReq(ctx, url, method, transformed)
+	}
+	return self.probeReq(ctx, url, method, params)
+}
+
+func (self *Flashbot) probeReq(ctx context.Context, url, method string, params interface{}) (bool, error) {
+	resp, err := self.doReq(ctx, url, self.Api().Method(method), params)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			if httpErr.StatusCode == http.StatusNotFound || isMethodNotFound(err) {
+				return false, nil
+			}
+			// Any other rejection means the method exists.
+			return true, nil
+		}
+		return false, err
+	}
+
+	msg := &jsonrpcMessage{}
+	if err := json.Unmarshal(resp, msg); err != nil {
+		return false, errors.Wrapf(err, "unmarshal flashbot response:%v", string(resp))
+	}
+	if msg.Error != nil && (msg.Error.Code == -32601 || isMethodNotFound(errors.New(msg.Error.Message))) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func isMethodNotFound(err error) bool {
+	if errors.Is(err, ErrMethodNotSupported) || errors.Is(err, ErrUnknownMethod) {
+		return true
+	}
+	errStr := strings.ToLower(err.Error())
+	return strings.Contains(errStr, "unknown method") ||
+		strings.Contains(errStr, "method not found") ||
+		strings.Contains(errStr, "does not exist/is not available") ||
+		strings.Contains(errStr, "code:-32601")
+}
@@ -0,0 +1,74 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		var err error
+		switch msg.Method {
+		case MethodSendBundle, MethodCancelBundle:
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid params"}}`))
+		case MethodCallBundle:
+			w.WriteHeader(http.StatusBadRequest)
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"no txs"}}`))
+		case MethodGetUserStats:
+			w.WriteHeader(http.StatusBadRequest)
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"unknown method: flashbots_getUserStats"}}`))
+		default:
+			_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+		}
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	api := &Api{URL: srv.URL, SupportsStats: true}
+	flashbot, err := New(privKey, api)
+	testutil.Ok(t, err)
+
+	// Probing while the instance is in use doesn't race with the requests.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	}()
+	supported, err := flashbot.Probe(context.Background())
+	testutil.Ok(t, err)
+	wg.Wait()
+	testutil.Equals(t, []string{MethodSendBundle, MethodCallBundle, MethodCancelBundle}, supported)
+
+	probed := flashbot.Api()
+	testutil.Assert(t, probed.SupportsSimulation, "simulation should be detected")
+	testutil.Assert(t, !probed.SupportsStats, "stats shouldn't be supported")
+	testutil.Assert(t, !probed.Supports(MethodEstimateGasBundle), "estimate shouldn't be supported")
+	// The methods which aren't probed are still sent to the relay.
+	for _, m := range []string{MethodMevSendBundle, MethodMevSimBundle, MethodGetUserStatsV2, MethodGetFeeRefundsByRecipient} {
+		testutil.Assert(t, probed.Supports(m), "method should be supported:%v", m)
+	}
+	testutil.Assert(t, api.SupportsStats && api.UnsupportedMethods == nil, "the api passed to New shouldn't change")
+
+	// An explicit list keeps its methods and gets the probed ones.
+	flashbot, err = New(privKey, &Api{URL: srv.URL, SupportedMethods: []string{MethodMevSendBundle}})
+	testutil.Ok(t, err)
+	_, err = flashbot.Probe(context.Background())
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{MethodMevSendBundle, MethodSendBundle, MethodCallBundle, MethodCancelBundle}, flashbot.Api().SupportedMethods)
+}
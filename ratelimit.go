@@ -0,0 +1,26 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig is a token bucket which refills at PerSecond requests
+// per second and allows bursts of up to Burst requests.
+type RateLimitConfig struct {
+	// PerSecond is the sustained number of requests per second.
+	// It must be positive, leave RateLimit nil to disable the limit.
+	PerSecond float64
+	// Burst defaults to 1.
+	Burst int
+}
+
+func (self RateLimitConfig) limiter() *rate.Limiter {
+	burst := self.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(self.PerSecond), burst)
+}
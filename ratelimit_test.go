@@ -0,0 +1,48 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+)
+
+func TestRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		RateLimit:              &RateLimitConfig{PerSecond: 20, Burst: 2},
+	})
+	testutil.Ok(t, err)
+
+	ctx := context.Background()
+	start := time.Now()
+	// The first 2 use the burst and the next 2 wait 50ms each.
+	for i := 0; i < 4; i++ {
+		_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+		testutil.Ok(t, err)
+	}
+	testutil.Assert(t, time.Since(start) >= 90*time.Millisecond, "requests weren't throttled:%v", time.Since(start))
+
+	// Waiting is bound by the context.
+	ctx, cncl := context.WithTimeout(ctx, time.Millisecond)
+	defer cncl()
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+
+	// A limit without a rate would block all the requests.
+	_, err = New(nil, &Api{URL: srv.URL, RateLimit: &RateLimitConfig{Burst: 2}})
+	testutil.NotOk(t, err)
+}
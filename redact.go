@@ -0,0 +1,96 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"fmt"
+	"net/textproto"
+	"regexp"
+	"strings"
+)
+
+// sensitiveHeaders carry credentials in every request,
+// the custom headers of the api are redacted as well as they are usually api keys.
+var sensitiveHeaders = []string{
+	"X-Flashbots-Signature",
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// rawTxHex matches hex strings long enough to be signed transactions,
+// hashes, addresses and numbers are shorter.
+// The prefix is optional as bloXroute takes the transactions without it.
+var rawTxHex = regexp.MustCompile(`\b(?:0x)?[0-9a-fA-F]{160,}\b`)
+
+// compressionEncodings are the content codings which make the body unreadable,
+// identity leaves it as is.
+var compressionEncodings = map[string]bool{
+	"gzip":       true,
+	"x-gzip":     true,
+	"deflate":    true,
+	"br":         true,
+	"compress":   true,
+	"x-compress": true,
+	"zstd":       true,
+}
+
+// redactDump masks the credentials headers and the raw transactions in an http dump
+// so that it can be sent to log aggregation systems.
+// The transactions are replaced by their hash to keep the dump useful.
+func (self *Flashbot) redactDump(dump []byte) []byte {
+	sensitive := make(map[string]bool, len(sensitiveHeaders)+len(self.Api().CustomHeaders))
+	for _, h := range sensitiveHeaders {
+		sensitive[textproto.CanonicalMIMEHeaderKey(h)] = true
+	}
+	for h := range self.Api().CustomHeaders {
+		sensitive[textproto.CanonicalMIMEHeaderKey(h)] = true
+	}
+
+	head, body := dump, []byte(nil)
+	if i := bytes.Index(dump, []byte("\r\n\r\n")); i >= 0 {
+		head, body = dump[:i], dump[i+4:]
+	}
+
+	compressed := false
+	lines := bytes.Split(head, []byte("\r\n"))
+	for i, line := range lines {
+		name, value, ok := bytes.Cut(line, []byte(":"))
+		if !ok {
+			continue
+		}
+		key := textproto.CanonicalMIMEHeaderKey(string(bytes.TrimSpace(name)))
+		if key == "Content-Encoding" {
+			for _, coding := range bytes.Split(value, []byte(",")) {
+				if compressionEncodings[strings.ToLower(string(bytes.TrimSpace(coding)))] {
+					compressed = true
+				}
+			}
+		}
+		if sensitive[key] {
+			lines[i] = []byte(key + ": [redacted]")
+		}
+	}
+
+	if compressed && len(body) > 0 {
+		body = []byte(fmt.Sprintf("[redacted compressed body %v bytes]", len(body)))
+	} else {
+		body = rawTxHex.ReplaceAllFunc(body, func(txHex []byte) []byte {
+			txHex = bytes.TrimPrefix(txHex, []byte("0x"))
+			if tx, err := decodeTx("0x" + string(txHex)); err == nil {
+				return []byte("[redacted tx:" + tx.Hash().Hex() + "]")
+			}
+			return []byte(fmt.Sprintf("[redacted %v bytes]", len(txHex)/2))
+		})
+	}
+
+	out := bytes.Join(lines, []byte("\r\n"))
+	if body != nil {
+		out = append(out, "\r\n\r\n"...)
+		out = append(out, body...)
+	}
+	return out
+}
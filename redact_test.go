@@ -0,0 +1,112 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRedactDump(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"rejected"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	txHex := signedTxHex(t)
+	tx, err := decodeTx(txHex)
+	testutil.Ok(t, err)
+
+	send := func(cfg DebugDumpConfig) string {
+		cfg.Dir = t.TempDir()
+		flashbot, err := New(privKey, &Api{
+			URL:           srv.URL,
+			CustomHeaders: map[string]string{"x-api-key": "secret-key"},
+			JWT:           &JWTAuth{Key: []byte("jwt-secret")},
+		}, WithDebugDumps(cfg))
+		testutil.Ok(t, err)
+		_, err = flashbot.SendPrivateTransaction(context.Background(), txHex, 10, nil)
+		testutil.NotOk(t, err)
+		files, err := filepath.Glob(filepath.Join(cfg.Dir, "*"))
+		testutil.Ok(t, err)
+		testutil.Equals(t, 1, len(files))
+		dump, err := os.ReadFile(files[0])
+		testutil.Ok(t, err)
+		return string(dump)
+	}
+
+	dump := send(DebugDumpConfig{})
+	for _, secret := range []string{txHex[2:], "secret-key", "Bearer"} {
+		testutil.Assert(t, !strings.Contains(dump, secret), "dump includes %v:%v", secret, dump)
+	}
+	for _, h := range []string{"X-Flashbots-Signature", "X-Api-Key", "Authorization"} {
+		testutil.Assert(t, strings.Contains(dump, h+": [redacted]"), "%v should be redacted:%v", h, dump)
+	}
+	testutil.Assert(t, strings.Contains(dump, "[redacted tx:"+tx.Hash().Hex()+"]"), "tx should be replaced by its hash:%v", dump)
+	testutil.Assert(t, strings.Contains(dump, "rejected"), "response should be kept:%v", dump)
+
+	dump = send(DebugDumpConfig{Unsafe: true})
+	testutil.Assert(t, strings.Contains(dump, txHex[2:]), "unsafe dump should include the tx:%v", dump)
+	testutil.Assert(t, strings.Contains(dump, "secret-key"), "unsafe dump should include the headers:%v", dump)
+}
+
+func TestRedactDumpCompressed(t *testing.T) {
+	flashbot := &Flashbot{api: &Api{}}
+	dump := []byte("POST / HTTP/1.1\r\nContent-Encoding: gzip\r\n\r\n\x1f\x8b0x" + strings.Repeat("ab", 100))
+	redacted := flashbot.redactDump(dump)
+	testutil.Assert(t, bytes.HasSuffix(redacted, []byte("[redacted compressed body 204 bytes]")), "unexpected dump:%q", redacted)
+}
+
+func TestRedactDumpIdentityEncoding(t *testing.T) {
+	flashbot := &Flashbot{api: &Api{}}
+	txHex := signedTxHex(t)
+	tx, err := decodeTx(txHex)
+	testutil.Ok(t, err)
+	dump := []byte("POST / HTTP/1.1\r\nContent-Encoding: identity\r\n\r\n" + txHex)
+	redacted := flashbot.redactDump(dump)
+	testutil.Assert(t, bytes.HasSuffix(redacted, []byte("[redacted tx:"+tx.Hash().Hex()+"]")), "identity body should be kept:%q", redacted)
+}
+
+func TestRedactDumpBloxroute(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"rejected"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	txHex := signedTxHex(t)
+	tx, err := decodeTx(txHex)
+	testutil.Ok(t, err)
+
+	dir := t.TempDir()
+	api := BloxrouteApi("secret-auth")
+	api.URL = srv.URL
+	flashbot, err := New(privKey, api, WithDebugDumps(DebugDumpConfig{Dir: dir}))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(context.Background(), []string{txHex}, 10, nil)
+	testutil.NotOk(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(files))
+	dump, err := os.ReadFile(files[0])
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(dump), `"transaction":["[redacted tx:`+tx.Hash().Hex()+`]"]`), "tx without the prefix should be redacted:%s", dump)
+	testutil.Assert(t, !strings.Contains(string(dump), txHex[2:]), "dump includes the tx:%s", dump)
+	testutil.Assert(t, !strings.Contains(string(dump), "secret-auth"), "dump includes the auth header:%s", dump)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	MethodGetFeeRefundTotalsByRecipient = "flashbots_getFeeRefundTotalsByRecipient"
+	MethodGetFeeRefundsByRecipient      = "flashbots_getFeeRefundsByRecipient"
+)
+
+type FeeRefundTotals struct {
+	Pending  *hexutil.Big `json:"pending"`
+	Received *hexutil.Big `json:"received"`
+}
+
+type FeeRefundTotalsResponse struct {
+	Error  `json:"error,omitempty"`
+	Result FeeRefundTotals `json:"result,omitempty"`
+}
+
+type FeeRefund struct {
+	Hash        common.Hash    `json:"hash"`
+	Amount      *hexutil.Big   `json:"amount"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Status      string         `json:"status"`
+	Recipient   common.Address `json:"recipient"`
+}
+
+type FeeRefunds struct {
+	Refunds []FeeRefund `json:"refunds"`
+	// Cursor is set when there are more refunds to fetch.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type FeeRefundsResponse struct {
+	Error  `json:"error,omitempty"`
+	Result FeeRefunds `json:"result,omitempty"`
+}
+
+type ParamsFeeRefunds struct {
+	Recipient common.Address `json:"recipient"`
+	Cursor    string         `json:"cursor,omitempty"`
+}
+
+// GetFeeRefundTotals returns the pending and received gas fee refunds of the recipient.
+// When recipient is nil the address of the signing key is used.
+func (self *Flashbot) GetFeeRefundTotals(ctx context.Context, recipient *common.Address) (*FeeRefundTotalsResponse, error) {
+	addr, err := self.refundRecipient(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := self.req(ctx, MethodGetFeeRefundTotalsByRecipient, addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "flashbot fee refund totals request")
+	}
+
+	rr := &FeeRefundTotalsResponse{}
+
+	err = self.unmarshal(resp, rr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal flashbot response:%v", string(resp))
+	}
+
+	if rr.Error.Code != 0 {
+		return nil, errors.Wrapf(self.rpcError(rr.Error), "flashbot request returned an error recipient:%v", addr)
+	}
+
+	return rr, nil
+}
+
+// GetFeeRefunds returns a page of the individual refunds of the recipient.
+// When recipient is nil the address of the signing key is used.
+// Pass the cursor from the previous result to get the next page.
+func (self *Flashbot) GetFeeRefunds(ctx context.Context, recipient *common.Address, cursor string) (*FeeRefundsResponse, error) {
+	addr, err := self.refundRecipient(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	param := ParamsFeeRefunds{
+		Recipient: addr,
+		Cursor:    cursor,
+	}
+	resp, err := self.req(ctx, MethodGetFeeRefundsByRecipient, param)
+	if err != nil {
+		return nil, errors.Wrap(err, "flashbot fee refunds request")
+	}
+
+	rr := &FeeRefundsResponse{}
+
+	err = self.unmarshal(resp, rr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal flashbot response:%v", string(resp))
+	}
+
+	if rr.Error.Code != 0 {
+		return nil, errors.Wrapf(self.rpcError(rr.Error), "flashbot request returned an error recipient:%v", addr)
+	}
+
+	return rr, nil
+}
+
+func (self *Flashbot) refundRecipient(recipient *common.Address) (common.Address, error) {
+	if recipient != nil {
+		return *recipient, nil
+	}
+	signer := self.Signer()
+	if signer == nil {
+		return common.Address{}, errors.New("no recipient provided and the signing key is not set")
+	}
+	return signer.Address(), nil
+}
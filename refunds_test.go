@@ -0,0 +1,43 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestGetFeeRefundTotals(t *testing.T) {
+	var params []json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"pending":"0x64","received":"0x3e8"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	resp, err := flashbot.GetFeeRefundTotals(context.Background(), nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int64(100), resp.Result.Pending.ToInt().Int64())
+	testutil.Equals(t, int64(1000), resp.Result.Received.ToInt().Int64())
+
+	// Defaults to the address of the signing key.
+	addr := crypto.PubkeyToAddress(privKey.PublicKey)
+	testutil.Equals(t, `"`+strings.ToLower(addr.Hex())+`"`, string(params[0]))
+}
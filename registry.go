@@ -0,0 +1,120 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/ecdsa"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RelayRegistry holds named relays which can be changed at runtime,
+// for example from a hot-reloaded config.
+// Updating a relay creates a new instance for it so calls in flight
+// complete on the previous instance with the previous api.
+type RelayRegistry struct {
+	prvKey *ecdsa.PrivateKey
+	opts   []Option
+
+	mtx    sync.RWMutex
+	names  []string
+	relays map[string]Flashboter
+}
+
+// NewRelayRegistry creates an empty registry,
+// the key and options are used for all relays added to it.
+func NewRelayRegistry(prvKey *ecdsa.PrivateKey, opts ...Option) *RelayRegistry {
+	return &RelayRegistry{
+		prvKey: prvKey,
+		opts:   opts,
+		relays: make(map[string]Flashboter),
+	}
+}
+
+// Add registers a new relay under the name.
+func (self *RelayRegistry) Add(name string, api *Api) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	f, err := New(self.prvKey, api, self.opts...)
+	if err != nil {
+		return errors.Wrapf(err, "create flashbot instance:%v", name)
+	}
+	if _, ok := self.relays[name]; ok {
+		return errors.Errorf("relay already exists:%v", name)
+	}
+	self.names = append(self.names, name)
+	self.relays[name] = f
+	return nil
+}
+
+// Update replaces the api of an existing relay.
+func (self *RelayRegistry) Update(name string, api *Api) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	f, err := New(self.prvKey, api, self.opts...)
+	if err != nil {
+		return errors.Wrapf(err, "create flashbot instance:%v", name)
+	}
+	if _, ok := self.relays[name]; !ok {
+		return errors.Errorf("relay doesn't exist:%v", name)
+	}
+	self.relays[name] = f
+	return nil
+}
+
+// Remove unregisters the relay and reports whether it existed.
+func (self *RelayRegistry) Remove(name string) bool {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if _, ok := self.relays[name]; !ok {
+		return false
+	}
+	delete(self.relays, name)
+	for i, n := range self.names {
+		if n == name {
+			self.names = append(self.names[:i], self.names[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// RotateKey switches the identity key of all relays
+// and of the relays added or updated afterwards.
+// Relays with their own Api.Signer keep using it.
+func (self *RelayRegistry) RotateKey(prvKey *ecdsa.PrivateKey) error {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for _, name := range self.names {
+		if self.relays[name].Api().Signer != nil {
+			continue
+		}
+		if err := self.relays[name].RotateKey(prvKey); err != nil {
+			return errors.Wrapf(err, "rotating key:%v", name)
+		}
+	}
+	self.prvKey = prvKey
+	return nil
+}
+
+func (self *RelayRegistry) Get(name string) (Flashboter, bool) {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	f, ok := self.relays[name]
+	return f, ok
+}
+
+// Flashbots returns the current relays in the order they were added.
+func (self *RelayRegistry) Flashbots() []Flashboter {
+	self.mtx.RLock()
+	defer self.mtx.RUnlock()
+	flashbots := make([]Flashboter, 0, len(self.names))
+	for _, name := range self.names {
+		flashbots = append(flashbots, self.relays[name])
+	}
+	return flashbots
+}
@@ -0,0 +1,70 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRelayRegistry(t *testing.T) {
+	newSrv := func(calls *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*calls++
+			_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+			testutil.Ok(t, err)
+		}))
+	}
+	var callsA, callsB int
+	srvA := newSrv(&callsA)
+	defer srvA.Close()
+	srvB := newSrv(&callsB)
+	defer srvB.Close()
+
+	registry := NewRelayRegistry(nil)
+	testutil.Ok(t, registry.Add("a", &Api{URL: srvA.URL, SkipFlashbotsSignature: true}))
+	testutil.NotOk(t, registry.Add("a", &Api{URL: srvA.URL}))
+	testutil.NotOk(t, registry.Update("missing", &Api{URL: srvA.URL}))
+
+	multi := NewMultiRegistry(registry)
+	ctx := context.Background()
+
+	_, err := multi.SendBundleAll(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, callsA)
+
+	// The running Multi picks up the changes.
+	testutil.Ok(t, registry.Add("b", &Api{URL: srvB.URL, SkipFlashbotsSignature: true}))
+	_, err = multi.SendBundleAll(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, callsA)
+	testutil.Equals(t, 1, callsB)
+
+	testutil.Ok(t, registry.Update("a", &Api{URL: srvB.URL, SkipFlashbotsSignature: true}))
+	testutil.Assert(t, registry.Remove("b"), "relay should be removed")
+	testutil.Assert(t, !registry.Remove("b"), "relay was already removed")
+
+	_, err = multi.SendBundleAll(ctx, []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, callsA)
+	testutil.Equals(t, 2, callsB)
+
+	f, ok := registry.Get("a")
+	testutil.Assert(t, ok, "relay should exist")
+	testutil.Equals(t, srvB.URL, f.Api().URL)
+
+	// Key rotation applies to the existing and the new relays.
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	testutil.Ok(t, registry.RotateKey(prvKey))
+	testutil.Ok(t, registry.Add("c", &Api{URL: srvA.URL}))
+	for _, f := range registry.Flashbots() {
+		testutil.Equals(t, prvKey, f.(*Flashbot).PrvKey())
+	}
+}
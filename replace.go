@@ -0,0 +1,90 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// BundleReplacer keeps the replacementUuid of each bundle so that
+// an updated version can be resubmitted in the following blocks
+// and replaces the previous one instead of competing with it.
+type BundleReplacer struct {
+	flashbot Flashboter
+
+	mtx   sync.Mutex
+	uuids map[string]string
+}
+
+func NewBundleReplacer(flashbot Flashboter) *BundleReplacer {
+	return &BundleReplacer{
+		flashbot: flashbot,
+		uuids:    make(map[string]string),
+	}
+}
+
+// ReplaceBundle sends the bundle under the replacementUuid remembered for the key.
+// The first submission for a key generates a new uuid.
+// The ReplacementUuid in opts is ignored.
+func (self *BundleReplacer) ReplaceBundle(ctx context.Context, key string, txsHex []string, blockNum uint64, opts *SendBundleOpts) (*Response, error) {
+	self.mtx.Lock()
+	replacementUuid, ok := self.uuids[key]
+	if !ok {
+		replacementUuid = uuid.NewString()
+		self.uuids[key] = replacementUuid
+	}
+	self.mtx.Unlock()
+
+	sendOpts := SendBundleOpts{}
+	if opts != nil {
+		sendOpts = *opts
+	}
+	sendOpts.ReplacementUuid = replacementUuid
+
+	resp, err := self.flashbot.SendBundle(ctx, txsHex, blockNum, &sendOpts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "replacing bundle key:%v replacementUuid:%v", key, replacementUuid)
+	}
+	return resp, nil
+}
+
+// Uuid returns the replacementUuid used for the key.
+func (self *BundleReplacer) Uuid(key string) (string, bool) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	replacementUuid, ok := self.uuids[key]
+	return replacementUuid, ok
+}
+
+// CancelByUuid cancels the bundles sent with the replacementUuid
+// and forgets it so that the next ReplaceBundle for its key starts a new bundle.
+func (self *BundleReplacer) CancelByUuid(ctx context.Context, replacementUuid string) (*CancelBundleResponse, error) {
+	resp, err := self.flashbot.CancelBundle(ctx, replacementUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	self.mtx.Lock()
+	for key, u := range self.uuids {
+		if u == replacementUuid {
+			delete(self.uuids, key)
+		}
+	}
+	self.mtx.Unlock()
+
+	return resp, nil
+}
+
+// Cancel cancels the bundles sent for the key.
+func (self *BundleReplacer) Cancel(ctx context.Context, key string) (*CancelBundleResponse, error) {
+	replacementUuid, ok := self.Uuid(key)
+	if !ok {
+		return nil, errors.Errorf("no bundle sent for key:%v", key)
+	}
+	return self.CancelByUuid(ctx, replacementUuid)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBundleReplacer(t *testing.T) {
+	var uuids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		var params []struct {
+			ReplacementUuid string `json:"replacementUuid"`
+		}
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		uuids = append(uuids, msg.Method+":"+params[0].ReplacementUuid)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(privKey, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	ctx := context.Background()
+	replacer := NewBundleReplacer(flashbot)
+
+	_, err = replacer.ReplaceBundle(ctx, "arb", []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	_, err = replacer.ReplaceBundle(ctx, "arb", []string{"0x2"}, 11, &SendBundleOpts{ReplacementUuid: "ignored"})
+	testutil.Ok(t, err)
+	_, err = replacer.ReplaceBundle(ctx, "other", []string{"0x3"}, 11, nil)
+	testutil.Ok(t, err)
+
+	arbUuid, ok := replacer.Uuid("arb")
+	testutil.Assert(t, ok, "uuid should be remembered")
+	otherUuid, _ := replacer.Uuid("other")
+	testutil.Assert(t, arbUuid != otherUuid, "keys should use different uuids")
+
+	_, err = replacer.Cancel(ctx, "arb")
+	testutil.Ok(t, err)
+	_, ok = replacer.Uuid("arb")
+	testutil.Assert(t, !ok, "cancelled uuid should be forgotten")
+
+	testutil.Equals(t, []string{
+		MethodSendBundle + ":" + arbUuid,
+		MethodSendBundle + ":" + arbUuid,
+		MethodSendBundle + ":" + otherUuid,
+		MethodCancelBundle + ":" + arbUuid,
+	}, uuids)
+
+	_, err = replacer.Cancel(ctx, "arb")
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,268 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	resubmitPollIntervalDefault = time.Second
+	resubmitCancelTimeout       = 5 * time.Second
+	slotDurationDefault         = 12 * time.Second
+	slotLateAfterDefault        = 4 * time.Second
+)
+
+// ChainReader is the subset of the ethclient.Client methods used to follow the chain.
+type ChainReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// SubmissionStatus is the final state of a managed submission.
+type SubmissionStatus string
+
+const (
+	StatusIncluded  SubmissionStatus = "included"
+	StatusCancelled SubmissionStatus = "cancelled"
+	StatusExpired   SubmissionStatus = "expired"
+)
+
+// ResubmitConfig configures Resubmit.
+type ResubmitConfig struct {
+	Txs []string
+	// MaxBlock is the last block for which the bundle is submitted.
+	MaxBlock uint64
+	// Refresh is called before each submission with the target block
+	// and the previous transactions and returns the transactions to submit,
+	// for example to re-price the bundle. Nil submits the same transactions.
+	Refresh func(ctx context.Context, blockNum uint64, txsHex []string) ([]string, error)
+	// Opts are sent with every submission.
+	// A replacementUuid is generated when empty so that each submission replaces the previous one.
+	Opts SendBundleOpts
+	// PollInterval is how often the chain is checked for a new block, defaults to 1s.
+	PollInterval time.Duration
+	// Slot enables slot aware submissions which set the timestamps of each submission
+	// to the slot of the target block and retarget the bundle to the next slot
+	// when the slot is missed or the block is late. It overrides the timestamps of Opts.
+	Slot *SlotConfig
+}
+
+// SlotConfig configures the slot timing of Resubmit.
+type SlotConfig struct {
+	// Headers reads the time of the head.
+	Headers HeaderReader
+	// Duration of a slot, defaults to 12s.
+	Duration time.Duration
+	// LateAfter is how long after the start of its slot the target block
+	// is considered missed, defaults to 4s which is the attestation deadline.
+	LateAfter time.Duration
+}
+
+// HeaderReader is the subset of the ethclient.Client methods used to read block times.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+func (self *SlotConfig) duration() time.Duration {
+	if self.Duration <= 0 {
+		return slotDurationDefault
+	}
+	return self.Duration
+}
+
+func (self *SlotConfig) lateAfter() time.Duration {
+	if self.LateAfter <= 0 {
+		return slotLateAfterDefault
+	}
+	return self.LateAfter
+}
+
+// next returns the first slot after the head which isn't already late.
+// A head that arrived late can leave no time for the slot right after it.
+func (self *SlotConfig) next(ctx context.Context, head uint64) (time.Time, error) {
+	header, err := self.Headers.HeaderByNumber(ctx, new(big.Int).SetUint64(head))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "reading header block:%v", head)
+	}
+	slot := time.Unix(int64(header.Time), 0).Add(self.duration())
+	for now := time.Now(); !now.Before(slot.Add(self.lateAfter())); {
+		slot = slot.Add(self.duration())
+	}
+	return slot, nil
+}
+
+// ResubmitResult is the outcome of Resubmit.
+type ResubmitResult struct {
+	Status SubmissionStatus
+	// BlockNum is the block that included the bundle or the last targeted block.
+	BlockNum uint64
+	// Txs are the transactions of the last submission.
+	Txs         []string
+	Submissions int
+	// Retargets is how many times the slot of the target block was missed.
+	Retargets int
+	// LastErr is the last failed submission or cancellation,
+	// failures don't stop the resubmissions.
+	LastErr error
+}
+
+// Resubmit submits the bundle for the next block after every new block until it is included,
+// the context is cancelled or the MaxBlock passes.
+// With a SlotConfig a target block that misses its slot is submitted again for the next slot.
+// When the context is cancelled the bundle is also cancelled on the relay on a best effort basis
+// as the relay might not support cancellations and the bundle expires on its own.
+// An error is returned only when the chain can't be read or Refresh fails.
+func Resubmit(ctx context.Context, flashbot Flashboter, chain ChainReader, cfg ResubmitConfig) (*ResubmitResult, error) {
+	if len(cfg.Txs) == 0 {
+		return nil, errors.New("bundle txs can't be empty")
+	}
+	pollInterval := cfg.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = resubmitPollIntervalDefault
+	}
+	opts := cfg.Opts
+	if opts.ReplacementUuid == "" {
+		opts.ReplacementUuid = uuid.NewString()
+	}
+	ctx, _ = ensureCorrelationID(ctx)
+
+	head, err := chain.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading block number")
+	}
+	result := &ResubmitResult{Txs: cfg.Txs}
+	for {
+		target := head + 1
+		if target > cfg.MaxBlock {
+			result.Status = StatusExpired
+			return result, nil
+		}
+
+		var slotTime time.Time
+		if cfg.Slot != nil {
+			if slotTime, err = cfg.Slot.next(ctx, head); err != nil {
+				return result, err
+			}
+		}
+		for {
+			if cfg.Refresh != nil {
+				txs, err := cfg.Refresh(ctx, target, result.Txs)
+				if err != nil {
+					return result, errors.Wrapf(err, "refreshing bundle block:%v", target)
+				}
+				result.Txs = txs
+			}
+			var deadline time.Time
+			if cfg.Slot != nil {
+				opts.MinTimestamp = uint64(slotTime.Unix())
+				opts.MaxTimestamp = opts.MinTimestamp
+				deadline = slotTime.Add(cfg.Slot.lateAfter())
+			}
+			result.BlockNum = target
+			result.Submissions++
+			if _, err := flashbot.SendBundle(ctx, result.Txs, target, &opts); err != nil {
+				result.LastErr = err
+			}
+
+			var late bool
+			head, late, err = waitForBlock(ctx, chain, target, pollInterval, deadline)
+			if err != nil {
+				if ctx.Err() != nil {
+					if err := cancelResubmission(flashbot, opts.ReplacementUuid); err != nil {
+						result.LastErr = err
+					}
+					result.Status = StatusCancelled
+					return result, nil
+				}
+				return result, err
+			}
+			if !late {
+				break
+			}
+			// The slot was missed or the block is late so the target
+			// block will be proposed in a later slot.
+			slotTime = slotTime.Add(cfg.Slot.duration())
+			result.Retargets++
+		}
+
+		included, err := bundleIncluded(ctx, chain, result.Txs)
+		if err != nil {
+			return result, err
+		}
+		if included {
+			result.Status = StatusIncluded
+			return result, nil
+		}
+	}
+}
+
+// waitForBlock polls the chain until the block is mined and returns the new head.
+// It gives up at the deadline and reports the block as late,
+// a zero deadline waits until the context is done.
+func waitForBlock(ctx context.Context, chain ChainReader, blockNum uint64, interval time.Duration, deadline time.Time) (uint64, bool, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		case <-ticker.C:
+		}
+		head, err := chain.BlockNumber(ctx)
+		if err != nil {
+			return 0, false, errors.Wrap(err, "reading block number")
+		}
+		if head >= blockNum {
+			return head, false, nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return head, true, nil
+		}
+	}
+}
+
+// bundleIncluded reports whether all bundle transactions have a receipt.
+func bundleIncluded(ctx context.Context, chain ChainReader, txsHex []string) (bool, error) {
+	hashes := make([]common.Hash, len(txsHex))
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return false, errors.Wrapf(err, "tx:%v", i)
+		}
+		hashes[i] = tx.Hash()
+	}
+	return txsIncluded(ctx, chain, hashes)
+}
+
+func txsIncluded(ctx context.Context, chain ChainReader, txHashes []common.Hash) (bool, error) {
+	for _, hash := range txHashes {
+		_, err := chain.TransactionReceipt(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "reading receipt tx:%v", hash.Hex())
+		}
+	}
+	return true, nil
+}
+
+// cancelResubmission uses a new context as the one of the submission is already cancelled.
+func cancelResubmission(flashbot Flashboter, replacementUuid string) error {
+	ctx, cncl := context.WithTimeout(context.Background(), resubmitCancelTimeout)
+	defer cncl()
+	if _, err := flashbot.CancelBundle(ctx, replacementUuid); err != nil {
+		return errors.Wrapf(err, "cancelling bundle replacementUuid:%v", replacementUuid)
+	}
+	return nil
+}
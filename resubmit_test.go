@@ -0,0 +1,200 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type chainMock struct {
+	mtx      sync.Mutex
+	head     uint64
+	advance  bool
+	included map[common.Hash]bool
+}
+
+func (self *chainMock) BlockNumber(ctx context.Context) (uint64, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	head := self.head
+	if self.advance {
+		self.head++
+	}
+	return head, nil
+}
+
+func (self *chainMock) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	if !self.included[txHash] {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{TxHash: txHash}, nil
+}
+
+func (self *chainMock) include(t *testing.T, txsHex []string) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	for _, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		testutil.Ok(t, err)
+		self.included[tx.Hash()] = true
+	}
+}
+
+func TestResubmit(t *testing.T) {
+	var (
+		mtx     sync.Mutex
+		methods []string
+		sent    []ParamsSend
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		mtx.Lock()
+		methods = append(methods, msg.Method)
+		if msg.Method == MethodSendBundle {
+			var params []ParamsSend
+			testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+			sent = append(sent, params[0])
+		}
+		mtx.Unlock()
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	// Re-priced every block and included in the second one.
+	chain := &chainMock{head: 10, advance: true, included: make(map[common.Hash]bool)}
+	var refreshed []uint64
+	res, err := Resubmit(ctx, flashbot, chain, ResubmitConfig{
+		Txs:      []string{signedTxHex(t)},
+		MaxBlock: 20,
+		Refresh: func(ctx context.Context, blockNum uint64, txsHex []string) ([]string, error) {
+			refreshed = append(refreshed, blockNum)
+			txs := []string{signedTxHex(t)}
+			if blockNum == 12 {
+				chain.include(t, txs)
+			}
+			return txs, nil
+		},
+		PollInterval: time.Millisecond,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, StatusIncluded, res.Status)
+	testutil.Equals(t, uint64(12), res.BlockNum)
+	testutil.Equals(t, 2, res.Submissions)
+	testutil.Equals(t, []uint64{11, 12}, refreshed)
+	testutil.Equals(t, 2, len(sent))
+	testutil.Equals(t, hexutil.EncodeUint64(11), sent[0].BlockNum)
+	testutil.Equals(t, hexutil.EncodeUint64(12), sent[1].BlockNum)
+	testutil.Equals(t, res.Txs, sent[1].Txs)
+	testutil.Assert(t, sent[0].ReplacementUuid != "", "replacementUuid should be generated")
+	testutil.Equals(t, sent[0].ReplacementUuid, sent[1].ReplacementUuid)
+
+	// Never included.
+	sent = nil
+	chain = &chainMock{head: 10, advance: true, included: make(map[common.Hash]bool)}
+	res, err = Resubmit(ctx, flashbot, chain, ResubmitConfig{
+		Txs:          []string{signedTxHex(t)},
+		MaxBlock:     12,
+		Opts:         SendBundleOpts{ReplacementUuid: "uuid"},
+		PollInterval: time.Millisecond,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, StatusExpired, res.Status)
+	testutil.Equals(t, 2, res.Submissions)
+	testutil.Equals(t, "uuid", sent[1].ReplacementUuid)
+
+	// Cancelled while waiting for the next block.
+	methods = nil
+	chain = &chainMock{head: 10, included: make(map[common.Hash]bool)}
+	ctx, cncl := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cncl()
+	res, err = Resubmit(ctx, flashbot, chain, ResubmitConfig{
+		Txs:          []string{signedTxHex(t)},
+		MaxBlock:     20,
+		PollInterval: time.Millisecond,
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, StatusCancelled, res.Status)
+	testutil.Equals(t, []string{MethodSendBundle, MethodCancelBundle}, methods)
+}
+
+type headersMock struct {
+	time uint64
+}
+
+func (self *headersMock) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: number, Time: self.time}, nil
+}
+
+func TestResubmitMissedSlot(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		sent []ParamsSend
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		if msg.Method == MethodSendBundle {
+			var params []ParamsSend
+			testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+			mtx.Lock()
+			sent = append(sent, params[0])
+			mtx.Unlock()
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	// The head is old so the slots right after it are already late
+	// and the chain doesn't advance so every slot is missed.
+	now := time.Now()
+	chain := &chainMock{head: 10, included: make(map[common.Hash]bool)}
+	ctx, cncl := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cncl()
+	res, err := Resubmit(ctx, flashbot, chain, ResubmitConfig{
+		Txs:          []string{signedTxHex(t)},
+		MaxBlock:     20,
+		PollInterval: 10 * time.Millisecond,
+		Slot: &SlotConfig{
+			Headers:   &headersMock{time: uint64(now.Unix()) - 10},
+			Duration:  time.Second,
+			LateAfter: 100 * time.Millisecond,
+		},
+	})
+	testutil.Ok(t, err)
+	testutil.Equals(t, StatusCancelled, res.Status)
+	testutil.Assert(t, res.Retargets >= 1, "the missed slot should be retargeted")
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	testutil.Equals(t, res.Submissions, len(sent))
+	testutil.Assert(t, sent[0].MinTimestamp+1 >= uint64(now.Unix()), "stale slot timestamp:%v", sent[0].MinTimestamp)
+	for i, s := range sent {
+		testutil.Equals(t, hexutil.EncodeUint64(11), s.BlockNum)
+		testutil.Equals(t, s.MinTimestamp, s.MaxTimestamp)
+		testutil.Equals(t, sent[0].MinTimestamp+uint64(i), s.MinTimestamp)
+	}
+}
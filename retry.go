@@ -0,0 +1,120 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	retryAttemptsDefault   = 3
+	retryBackoffMinDefault = 100 * time.Millisecond
+	retryBackoffMaxDefault = 2 * time.Second
+)
+
+var retryStatusCodesDefault = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+}
+
+// RetryConfig retries requests which failed with a transient status
+// using an exponential backoff with jitter or the delay from the Retry-After header.
+// Calls which would submit the same bundle or transaction twice
+// are retried only when the bundle has a replacementUuid.
+type RetryConfig struct {
+	// MaxAttempts includes the first attempt, defaults to 3.
+	MaxAttempts int
+	// BackoffMin and BackoffMax bound the exponential backoff, default to 100ms and 2s.
+	// BackoffMax caps the delay from the Retry-After header as well.
+	BackoffMin time.Duration
+	BackoffMax time.Duration
+	// StatusCodes are the retried response statuses, default to 429, 502 and 503.
+	StatusCodes []int
+}
+
+func (self *RetryConfig) maxAttempts() int {
+	if self.MaxAttempts <= 0 {
+		return retryAttemptsDefault
+	}
+	return self.MaxAttempts
+}
+
+// delay returns how long to wait before the next attempt
+// and false when the error isn't transient.
+func (self *RetryConfig) delay(attempt int, err error) (time.Duration, bool) {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return 0, false
+	}
+	codes := self.StatusCodes
+	if codes == nil {
+		codes = retryStatusCodesDefault
+	}
+	retry := false
+	for _, c := range codes {
+		if c == httpErr.StatusCode {
+			retry = true
+			break
+		}
+	}
+	if !retry {
+		return 0, false
+	}
+
+	min, max := self.BackoffMin, self.BackoffMax
+	if min <= 0 {
+		min = retryBackoffMinDefault
+	}
+	if max <= 0 {
+		max = retryBackoffMaxDefault
+	}
+	if httpErr.RetryAfter > 0 {
+		if httpErr.RetryAfter > max {
+			return max, true
+		}
+		return httpErr.RetryAfter, true
+	}
+	backoff := min << uint(attempt-1)
+	if backoff > max || backoff <= 0 {
+		backoff = max
+	}
+	// Random jitter between half and the full backoff.
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)), true
+}
+
+// retryAfter parses the Retry-After header which is either seconds or a date.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// retryable reports whether sending the request again can't cause a duplicate submission.
+func retryable(method string, params []interface{}) bool {
+	switch method {
+	case MethodSendBundle:
+		if len(params) == 0 {
+			return false
+		}
+		p, ok := params[0].(ParamsSend)
+		return ok && p.ReplacementUuid != ""
+	case MethodSendPrivateTransaction, MethodSendPrivateRawTransaction, MethodMevSendBundle:
+		return false
+	default:
+		return true
+	}
+}
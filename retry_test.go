@@ -0,0 +1,67 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+)
+
+func TestRetry(t *testing.T) {
+	var calls, failures int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	flashbot, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		Retry:                  &RetryConfig{MaxAttempts: 3, BackoffMin: time.Millisecond, BackoffMax: 5 * time.Millisecond},
+	})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	// Without a replacementUuid resending could duplicate the bundle.
+	calls, failures = 0, 2
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 1, calls)
+
+	calls, failures = 0, 2
+	_, err = flashbot.SendBundle(ctx, []string{"0x1"}, 10, &SendBundleOpts{ReplacementUuid: "uuid"})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, calls)
+
+	calls, failures = 0, 3
+	_, err = flashbot.CancelBundle(ctx, "uuid")
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 3, calls)
+}
+
+func TestRetryAfter(t *testing.T) {
+	testutil.Equals(t, 2*time.Second, retryAfter("2"))
+	testutil.Equals(t, time.Duration(0), retryAfter("invalid"))
+	d := retryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	testutil.Assert(t, d > 58*time.Second && d <= time.Minute, "unexpected delay:%v", d)
+
+	// The header can't hold the request for longer than the max backoff.
+	cfg := &RetryConfig{BackoffMax: time.Second}
+	d, ok := cfg.delay(1, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour})
+	testutil.Assert(t, ok, "429 should be retried")
+	testutil.Equals(t, time.Second, d)
+	d, _ = cfg.delay(1, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 500 * time.Millisecond})
+	testutil.Equals(t, 500*time.Millisecond, d)
+}
@@ -0,0 +1,385 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+// Command cfgdocgen generates the configuration docs of the flashbot package.
+// The descriptions are the doc comments of the package sources
+// and the networks and the relay catalog are the ones built into the package.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cryptoriums/flashbot"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the flashbot package sources")
+	out := flag.String("out", "", "output file, empty writes to stdout")
+	flag.Parse()
+
+	pkg, err := parsePackage(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if err := generate(&b, pkg); err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(b.Bytes()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := os.WriteFile(*out, b.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type pkgDocs struct {
+	types   map[string]*ast.StructType
+	docs    map[string]string
+	consts  []constDoc
+	options []funcDoc
+}
+
+type constDoc struct {
+	name, value, doc string
+}
+
+type funcDoc struct {
+	signature, doc string
+}
+
+func parsePackage(dir string) (*pkgDocs, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := pkgs["flashbot"]
+	if !ok {
+		return nil, fmt.Errorf("no flashbot package in:%v", dir)
+	}
+
+	docs := &pkgDocs{
+		types: make(map[string]*ast.StructType),
+		docs:  make(map[string]string),
+	}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				docs.addGenDecl(d)
+			case *ast.FuncDecl:
+				docs.addFunc(d)
+			}
+		}
+	}
+	sort.Slice(docs.consts, func(i, j int) bool { return docs.consts[i].name < docs.consts[j].name })
+	sort.Slice(docs.options, func(i, j int) bool { return docs.options[i].signature < docs.options[j].signature })
+	return docs, nil
+}
+
+func (self *pkgDocs) addGenDecl(d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			st, ok := s.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			self.types[s.Name.Name] = st
+			for _, f := range st.Fields.List {
+				for _, n := range f.Names {
+					self.docs[s.Name.Name+"."+n.Name] = text(f.Doc)
+				}
+			}
+		case *ast.ValueSpec:
+			if d.Tok != token.CONST {
+				continue
+			}
+			for i, n := range s.Names {
+				if !strings.HasPrefix(n.Name, "Env") || i >= len(s.Values) {
+					continue
+				}
+				lit, ok := s.Values[i].(*ast.BasicLit)
+				if !ok {
+					continue
+				}
+				value, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					continue
+				}
+				self.consts = append(self.consts, constDoc{name: n.Name, value: value, doc: text(s.Doc)})
+			}
+		}
+	}
+}
+
+// addFunc keeps the functions returning an Option.
+func (self *pkgDocs) addFunc(d *ast.FuncDecl) {
+	if d.Recv != nil || !d.Name.IsExported() || d.Type.Results == nil || len(d.Type.Results.List) != 1 {
+		return
+	}
+	if ident, ok := d.Type.Results.List[0].Type.(*ast.Ident); !ok || ident.Name != "Option" {
+		return
+	}
+	var params []string
+	for _, p := range d.Type.Params.List {
+		typ := exprString(p.Type)
+		for _, n := range p.Names {
+			params = append(params, n.Name+" "+typ)
+		}
+	}
+	self.options = append(self.options, funcDoc{
+		signature: d.Name.Name + "(" + strings.Join(params, ", ") + ")",
+		doc:       text(d.Doc),
+	})
+}
+
+func generate(b *bytes.Buffer, pkg *pkgDocs) error {
+	b.WriteString("<!-- Code generated by scripts/cfgdocgen. DO NOT EDIT. -->\n\n")
+	b.WriteString("# Configuration\n")
+
+	b.WriteString("\n## Relays file\n\n")
+	b.WriteString("The JSON or YAML file read by `LoadApis` and by `FromEnv` with `FLASHBOT_RELAYS_FILE`.\n")
+	b.WriteString("Durations are strings like `1.5s`.\n")
+	for _, name := range []string{"ApisConfig", "ApiConfig", "RateLimitFileConfig", "RetryFileConfig", "BreakerFileConfig", "ClientCertFileConfig"} {
+		if err := fileStruct(b, pkg, name); err != nil {
+			return err
+		}
+	}
+
+	b.WriteString("\n## Environment variables\n\n")
+	b.WriteString("Read by `FromEnv`.\n\n")
+	b.WriteString("| Variable | Description |\n|---|---|\n")
+	for _, c := range pkg.consts {
+		fmt.Fprintf(b, "| `%v` | %v |\n", c.value, cell(withoutName(c.doc, c.name)))
+	}
+
+	b.WriteString("\n## Api\n\n")
+	b.WriteString("The fields of `Api` for configuring the relays in code.\n\n")
+	if err := goStruct(b, pkg, "Api"); err != nil {
+		return err
+	}
+
+	b.WriteString("\n## Options\n\n")
+	b.WriteString("The options passed to `New` and the other constructors.\n\n")
+	b.WriteString("| Option | Description |\n|---|---|\n")
+	for _, o := range pkg.options {
+		fmt.Fprintf(b, "| `%v` | %v |\n", o.signature, cell(o.doc))
+	}
+
+	b.WriteString("\n## Networks\n\n")
+	b.WriteString("The networks used by `DefaultApi`, more can be added with `RegisterNetwork`.\n")
+	b.WriteString("Polygon (137) bundles can only be sent with `BloxrouteNetworkApi` as its relay needs a bloXroute account.\n\n")
+	b.WriteString("| ID | Name | Relay | Simulation | Stats | MEV-Share events | Protect status |\n|---|---|---|---|---|---|---|\n")
+	for _, n := range flashbot.Networks() {
+		fmt.Fprintf(b, "| %v | %v | %v | %v | %v | %v | %v |\n",
+			n.ID, n.Name, n.RelayURL, n.SupportsSimulation, n.SupportsStats, orDash(n.MevShareEventsURL), orDash(n.ProtectStatusURL))
+	}
+
+	catalog := flashbot.DefaultCatalog()
+	fmt.Fprintf(b, "\n## Relay catalog\n\nVersion %v of the catalog used by `NewAll`, deprecated relays are skipped unless requested with `NewAllWithOpts`.\n\n", catalog.Version)
+	b.WriteString("| Name | URL | Networks | Methods | Deprecated |\n|---|---|---|---|---|\n")
+	for _, r := range catalog.Relays {
+		var ids []string
+		for _, id := range r.NetIDs {
+			ids = append(ids, strconv.FormatInt(id, 10))
+		}
+		methods := "all"
+		if r.Methods != nil {
+			methods = strings.Join(r.Methods, ", ")
+		}
+		deprecated := "no"
+		if r.Deprecated {
+			deprecated = "yes " + r.Note
+		}
+		fmt.Fprintf(b, "| %v | %v | %v | %v | %v |\n", r.Name, r.URL, strings.Join(ids, ", "), methods, deprecated)
+	}
+	return nil
+}
+
+// fileDocSources are the types documenting the fields of the config file types
+// which don't have their own docs.
+var fileDocSources = map[string]string{
+	"ApiConfig":           "Api",
+	"RateLimitFileConfig": "RateLimitConfig",
+	"RetryFileConfig":     "RetryConfig",
+	"BreakerFileConfig":   "CircuitBreakerConfig",
+}
+
+var fileFieldSources = map[string]string{
+	"ApiConfig.Headers": "CustomHeaders",
+}
+
+func (self *pkgDocs) fileFieldDoc(typ, field string) string {
+	if doc := self.docs[typ+"."+field]; doc != "" {
+		return withoutName(doc, field)
+	}
+	src, ok := fileDocSources[typ]
+	if !ok {
+		return ""
+	}
+	srcField := field
+	if f, ok := fileFieldSources[typ+"."+field]; ok {
+		srcField = f
+	}
+	return withoutName(self.docs[src+"."+srcField], srcField)
+}
+
+// withoutName drops the leading identifier of a go doc comment
+// which doesn't mean anything to the readers of the config docs.
+func withoutName(doc, name string) string {
+	rest := strings.TrimPrefix(doc, name+" ")
+	// Docs shared by several fields read better with the names.
+	if rest == doc || rest == "" || strings.HasPrefix(rest, "and ") {
+		return doc
+	}
+	for _, verb := range []string{"is ", "are "} {
+		rest = strings.TrimPrefix(rest, verb)
+	}
+	return strings.ToUpper(rest[:1]) + rest[1:]
+}
+
+// fileStruct documents a struct of the config file with its json field names.
+func fileStruct(b *bytes.Buffer, pkg *pkgDocs, name string) error {
+	st, ok := pkg.types[name]
+	if !ok {
+		return fmt.Errorf("missing type:%v", name)
+	}
+	fmt.Fprintf(b, "\n### %v\n\n| Field | Type | Description |\n|---|---|---|\n", name)
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) == 0 {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return err
+		}
+		key := strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fmt.Fprintf(b, "| `%v` | %v | %v |\n", key, typeName(f.Type), cell(pkg.fileFieldDoc(name, f.Names[0].Name)))
+	}
+	return nil
+}
+
+// goStruct documents a struct with its go field names.
+func goStruct(b *bytes.Buffer, pkg *pkgDocs, name string) error {
+	st, ok := pkg.types[name]
+	if !ok {
+		return fmt.Errorf("missing type:%v", name)
+	}
+	b.WriteString("| Field | Type | Description |\n|---|---|---|\n")
+	for _, f := range st.Fields.List {
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			fmt.Fprintf(b, "| `%v` | `%v` | %v |\n", n.Name, exprString(f.Type), cell(pkg.docs[name+"."+n.Name]))
+		}
+	}
+	return nil
+}
+
+// typeName describes the type of a config file field.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "Duration":
+			return "duration"
+		case "string", "bool":
+			return t.Name
+		case "int", "int64", "uint64", "float64":
+			return "number"
+		}
+		return "[" + t.Name + "](#" + strings.ToLower(t.Name) + ")"
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.ArrayType:
+		return "list of " + typeName(t.Elt)
+	case *ast.MapType:
+		return "map of " + typeName(t.Value)
+	}
+	return exprString(expr)
+}
+
+func exprString(expr ast.Expr) string {
+	var b bytes.Buffer
+	if err := formatNode(&b, expr); err != nil {
+		return "?"
+	}
+	return b.String()
+}
+
+func formatNode(b *bytes.Buffer, expr ast.Expr) error {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		b.WriteString(t.Name)
+	case *ast.StarExpr:
+		b.WriteString("*")
+		return formatNode(b, t.X)
+	case *ast.SelectorExpr:
+		if err := formatNode(b, t.X); err != nil {
+			return err
+		}
+		b.WriteString("." + t.Sel.Name)
+	case *ast.ArrayType:
+		b.WriteString("[]")
+		return formatNode(b, t.Elt)
+	case *ast.Ellipsis:
+		b.WriteString("...")
+		return formatNode(b, t.Elt)
+	case *ast.MapType:
+		b.WriteString("map[")
+		if err := formatNode(b, t.Key); err != nil {
+			return err
+		}
+		b.WriteString("]")
+		return formatNode(b, t.Value)
+	case *ast.InterfaceType:
+		b.WriteString("interface{}")
+	case *ast.FuncType:
+		b.WriteString("func(...)")
+	default:
+		return fmt.Errorf("unsupported expression:%T", expr)
+	}
+	return nil
+}
+
+func text(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(doc.Text()), " ")
+}
+
+func cell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
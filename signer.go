@@ -0,0 +1,98 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/ecdsa"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Signer signs the relay requests with the searcher reputation key.
+// Implementations can keep the key outside of the process memory,
+// for example in an HSM or a remote signer.
+//
+// The key must be a secp256k1 key so it can't be kept in services without
+// secp256k1 support like the HashiCorp Vault transit secrets engine.
+// The awskms package implements it for AWS KMS.
+//
+// Ledger and Trezor devices can't be used through the go-ethereum USB wallets
+// because their drivers only sign transactions and return accounts.ErrNotSupported
+// for the request messages. NewKeystoreSigner and NewClefSigner cover the
+// go-ethereum keystore and external signers.
+type Signer interface {
+	// Address of the reputation key.
+	Address() common.Address
+	// Sign returns the signature of the 32 byte digest
+	// in the [R || S || V] format with V being 0 or 1 like crypto.Sign.
+	Sign(digest []byte) ([]byte, error)
+}
+
+type ecdsaSigner struct {
+	prvKey *ecdsa.PrivateKey
+	addr   common.Address
+}
+
+// NewECDSASigner returns a signer for an in-memory private key.
+func NewECDSASigner(prvKey *ecdsa.PrivateKey) (Signer, error) {
+	if prvKey == nil {
+		return nil, errors.New("private key can't be empty")
+	}
+	pubKey, ok := prvKey.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("casting private key to ECDSA")
+	}
+	return &ecdsaSigner{prvKey: prvKey, addr: crypto.PubkeyToAddress(*pubKey)}, nil
+}
+
+func (self *ecdsaSigner) Address() common.Address {
+	return self.addr
+}
+
+func (self *ecdsaSigner) Sign(digest []byte) ([]byte, error) {
+	return crypto.Sign(digest, self.prvKey)
+}
+
+// WithSigner sets the signer for the relay requests instead of the private key passed to New.
+// The Api.Signer of the relay takes precedence over it.
+func WithSigner(signer Signer) Option {
+	return func(f *Flashbot) {
+		f.signer = signer
+	}
+}
+
+// VerifySignature checks the X-Flashbots-Signature header of a request
+// and returns the address which signed the payload.
+// It is the reverse of the request signing and is useful
+// for relay proxies or audit tooling which receive signed requests.
+func VerifySignature(payload []byte, header string) (common.Address, error) {
+	parts := strings.Split(header, ":")
+	if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+		return common.Address{}, errors.New("missing or malformed X-Flashbots-Signature header")
+	}
+	sig, err := hexutil.Decode(parts[1])
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "decoding signature")
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, errors.Errorf("invalid signature length:%v", len(sig))
+	}
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(payload)))), sig)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "recovering signer")
+	}
+	signer := crypto.PubkeyToAddress(*pubKey)
+	if signer != common.HexToAddress(parts[0]) {
+		return common.Address{}, errors.New("signature doesn't match the address in the header")
+	}
+	return signer, nil
+}
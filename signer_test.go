@@ -0,0 +1,202 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type countingSigner struct {
+	Signer
+	calls int
+}
+
+func (self *countingSigner) Sign(digest []byte) ([]byte, error) {
+	self.calls++
+	return self.Signer.Sign(digest)
+}
+
+func TestSigner(t *testing.T) {
+	var signer common.Address
+	srv := newSignatureServer(t, &signer)
+	defer srv.Close()
+
+	keyDefault, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	keySigner, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	ecdsaSigner, err := NewECDSASigner(keySigner)
+	testutil.Ok(t, err)
+	s := &countingSigner{Signer: ecdsaSigner}
+
+	// The signer takes precedence over the private key.
+	flashbot, err := New(keyDefault, &Api{URL: srv.URL}, WithSigner(s))
+	testutil.Ok(t, err)
+	testutil.Assert(t, flashbot.(*Flashbot).PrvKey() == nil, "the private key shouldn't be exposed for a custom signer")
+
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, s.calls)
+	testutil.Equals(t, crypto.PubkeyToAddress(keySigner.PublicKey), signer)
+
+	testutil.Ok(t, flashbot.(*Flashbot).SetKey(keyDefault))
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, crypto.PubkeyToAddress(keyDefault.PublicKey), signer)
+}
+
+// newSignatureServer verifies the request signatures
+// and sets the signer to the recovered address.
+func newSignatureServer(t *testing.T, signer *common.Address) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		parts := strings.Split(r.Header.Get("X-Flashbots-Signature"), ":")
+		testutil.Equals(t, 2, len(parts))
+		sig, err := hexutil.Decode(parts[1])
+		testutil.Ok(t, err)
+		pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(payload)))), sig)
+		testutil.Ok(t, err)
+		*signer = crypto.PubkeyToAddress(*pubKey)
+		testutil.Equals(t, common.HexToAddress(parts[0]), *signer)
+
+		_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+}
+
+func TestVerifySignature(t *testing.T) {
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	signer, err := NewECDSASigner(prvKey)
+	testutil.Ok(t, err)
+
+	payload := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendBundle","params":[]}`)
+	header, err := signPayload(payload, signer)
+	testutil.Ok(t, err)
+
+	addr, err := VerifySignature(payload, header)
+	testutil.Ok(t, err)
+	testutil.Equals(t, signer.Address(), addr)
+
+	_, err = VerifySignature(append(payload, ' '), header)
+	testutil.NotOk(t, err)
+
+	other, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	parts := strings.Split(header, ":")
+	_, err = VerifySignature(payload, crypto.PubkeyToAddress(other.PublicKey).Hex()+":"+parts[1])
+	testutil.NotOk(t, err)
+
+	for _, malformed := range []string{"", parts[0], parts[1], "0x1:" + parts[1], parts[0] + ":0x1234"} {
+		_, err = VerifySignature(payload, malformed)
+		testutil.NotOk(t, err)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	var (
+		mtx     sync.Mutex
+		signers = make(map[common.Address]int)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		signer, err := VerifySignature(payload, r.Header.Get("X-Flashbots-Signature"))
+		testutil.Ok(t, err)
+		mtx.Lock()
+		signers[signer]++
+		mtx.Unlock()
+		_, err = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	key1, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	key2, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	flashbot, err := New(key1, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				_, err := flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+				testutil.Ok(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		key := key1
+		if i%2 == 0 {
+			key = key2
+		}
+		testutil.Ok(t, flashbot.RotateKey(key))
+	}
+	wg.Wait()
+
+	testutil.Ok(t, flashbot.RotateKey(key2))
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	testutil.Equals(t, 101, signers[crypto.PubkeyToAddress(key1.PublicKey)]+signers[crypto.PubkeyToAddress(key2.PublicKey)])
+	testutil.Assert(t, signers[crypto.PubkeyToAddress(key2.PublicKey)] > 0, "no requests signed with the rotated key")
+}
+
+func TestApiSigner(t *testing.T) {
+	var signerA, signerB common.Address
+	srvA := newSignatureServer(t, &signerA)
+	defer srvA.Close()
+	srvB := newSignatureServer(t, &signerB)
+	defer srvB.Close()
+
+	identity, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	throwaway, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	throwawaySigner, err := NewECDSASigner(throwaway)
+	testutil.Ok(t, err)
+
+	flashbots, err := NewMulti(1, identity, &Api{URL: srvA.URL}, &Api{URL: srvB.URL, Signer: throwawaySigner})
+	testutil.Ok(t, err)
+	for _, f := range flashbots {
+		_, err = f.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+		testutil.Ok(t, err)
+	}
+	testutil.Equals(t, crypto.PubkeyToAddress(identity.PublicKey), signerA)
+	testutil.Equals(t, throwawaySigner.Address(), signerB)
+
+	// The registry rotation leaves the relays with their own identity alone.
+	rotated, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	registry := NewRelayRegistry(identity)
+	testutil.Ok(t, registry.Add("a", &Api{URL: srvA.URL}))
+	testutil.Ok(t, registry.Add("b", &Api{URL: srvB.URL, Signer: throwawaySigner}))
+	testutil.Ok(t, registry.RotateKey(rotated))
+	for _, f := range registry.Flashbots() {
+		_, err = f.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+		testutil.Ok(t, err)
+	}
+	testutil.Equals(t, crypto.PubkeyToAddress(rotated.PublicKey), signerA)
+	testutil.Equals(t, throwawaySigner.Address(), signerB)
+}
@@ -0,0 +1,87 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ErrSimulationRefused is returned when SendBundle doesn't submit
+// a bundle because it failed the simulation guard.
+var ErrSimulationRefused = errors.New("bundle refused after simulation")
+
+// SimulationGuard simulates the bundle with CallBundle on the same relay before SendBundle
+// and refuses to submit it when it wouldn't be profitable to include.
+// The guard is skipped for relays which don't support simulations.
+type SimulationGuard struct {
+	// MinCoinbasePayment is the lowest accepted coinbase diff in wei,
+	// nil only checks for reverts.
+	MinCoinbasePayment *big.Int
+	// BlockNumState is the state block of the simulation, 0 means latest.
+	BlockNumState uint64
+}
+
+// SimulationRefusedError is the reason the simulation guard refused the bundle.
+type SimulationRefusedError struct {
+	Reason string
+	// Simulation is the result of the CallBundle.
+	Simulation *Response
+}
+
+func (self *SimulationRefusedError) Error() string {
+	return fmt.Sprintf("%v reason:%v", ErrSimulationRefused, self.Reason)
+}
+
+func (self *SimulationRefusedError) Is(target error) bool {
+	return target == ErrSimulationRefused
+}
+
+func (self *Flashbot) simulateGuard(ctx context.Context, txsHex []string, blockNum uint64, opts *SendBundleOpts) error {
+	guard := opts.Simulate
+	resp, err := self.CallBundle(ctx, txsHex, guard.BlockNumState, &CallBundleOpts{TargetBlockNum: blockNum})
+	if err != nil {
+		return errors.Wrap(err, "simulating bundle")
+	}
+
+	allowed := make(map[string]bool, len(opts.RevertingTxHashes))
+	for _, h := range opts.RevertingTxHashes {
+		allowed[strings.ToLower(h.Hex())] = true
+	}
+	for i, tx := range resp.Results {
+		if tx.Error == "" && tx.Revert == "" {
+			continue
+		}
+		if allowed[strings.ToLower(tx.TxHash)] {
+			continue
+		}
+		reason := tx.Revert
+		if reason == "" {
+			reason = tx.Error
+		}
+		return &SimulationRefusedError{
+			Reason:     fmt.Sprintf("tx:%v hash:%v reverted:%v", i, common.HexToHash(tx.TxHash).Hex(), reason),
+			Simulation: resp,
+		}
+	}
+
+	if guard.MinCoinbasePayment != nil {
+		diff, err := resp.CoinbaseDiffWei()
+		if err != nil {
+			return err
+		}
+		if diff.Cmp(guard.MinCoinbasePayment) < 0 {
+			return &SimulationRefusedError{
+				Reason:     fmt.Sprintf("coinbase payment:%v below the floor:%v", diff, guard.MinCoinbasePayment),
+				Simulation: resp,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+func TestSimulationGuard(t *testing.T) {
+	var (
+		methods []string
+		simResp string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		methods = append(methods, msg.Method)
+		resp := `{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`
+		if msg.Method == MethodCallBundle {
+			resp = simResp
+		}
+		_, err := w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	api := &Api{URL: srv.URL, SkipFlashbotsSignature: true, SupportsSimulation: true}
+	flashbot, err := New(nil, api)
+	testutil.Ok(t, err)
+	ctx := context.Background()
+	txs := []string{signedTxHex(t)}
+	opts := &SendBundleOpts{Simulate: &SimulationGuard{MinCoinbasePayment: big.NewInt(100)}}
+
+	// Paying above the floor.
+	simResp = `{"jsonrpc":"2.0","id":1,"result":{"coinbaseDiff":"150","results":[{"txHash":"0xa"}]}}`
+	_, err = flashbot.SendBundle(ctx, txs, 11, opts)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{MethodCallBundle, MethodSendBundle}, methods)
+
+	// Paying below the floor.
+	methods = nil
+	simResp = `{"jsonrpc":"2.0","id":1,"result":{"coinbaseDiff":"50","results":[{"txHash":"0xa"}]}}`
+	_, err = flashbot.SendBundle(ctx, txs, 11, opts)
+	testutil.Assert(t, errors.Is(err, ErrSimulationRefused), "unexpected error:%v", err)
+	refused := &SimulationRefusedError{}
+	testutil.Assert(t, errors.As(err, &refused), "unexpected error type:%T", err)
+	testutil.Equals(t, "50", refused.Simulation.CoinbaseDiff)
+	testutil.Equals(t, []string{MethodCallBundle}, methods)
+
+	// Reverting unless the revert is allowed.
+	simResp = `{"jsonrpc":"2.0","id":1,"result":{"coinbaseDiff":"150","results":[{"txHash":"0xa","revert":"execution reverted"}]}}`
+	_, err = flashbot.SendBundle(ctx, txs, 11, opts)
+	testutil.Assert(t, errors.Is(err, ErrSimulationRefused), "unexpected error:%v", err)
+	opts.RevertingTxHashes = []common.Hash{common.HexToHash("0xa")}
+	simResp = `{"jsonrpc":"2.0","id":1,"result":{"coinbaseDiff":"150","results":[{"txHash":"` + common.HexToHash("0xa").Hex() + `","revert":"execution reverted"}]}}`
+	_, err = flashbot.SendBundle(ctx, txs, 11, opts)
+	testutil.Ok(t, err)
+
+	// Skipped for relays without simulations.
+	methods = nil
+	api.SupportsSimulation = false
+	_, err = flashbot.SendBundle(ctx, txs, 11, opts)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{MethodSendBundle}, methods)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const statsWindowDefault = 256
+
+// RelayStats is the recent performance of a relay over the last requests in the window.
+// Every attempt counts as a request including retries.
+type RelayStats struct {
+	Relay string
+	// Requests and Failures are the number of requests in the window.
+	Requests int
+	Failures int
+	// SuccessRatio is 1 without any requests.
+	SuccessRatio float64
+	// The latency percentiles of the requests in the window, both successful and failed.
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	// Total is the number of requests since the instance was created.
+	Total     uint64
+	LastError string
+	// LastErrorAt is zero when no request has failed.
+	LastErrorAt time.Time
+	// Errors are the number of failures by their class since the instance was created.
+	// Besides the failed requests these include the relay errors and the reverts
+	// in the successful responses and the responses which couldn't be decoded.
+	Errors map[ErrorClass]uint64
+}
+
+// WithStatsWindow sets the number of the most recent requests used for the relay stats,
+// defaults to 256.
+func WithStatsWindow(n int) Option {
+	return func(f *Flashbot) {
+		f.stats = newRelayStats(n)
+	}
+}
+
+type statsSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+type relayStats struct {
+	mtx         sync.Mutex
+	samples     []statsSample
+	next        int
+	total       uint64
+	lastErr     string
+	lastErrTime time.Time
+	errors      map[ErrorClass]uint64
+}
+
+func newRelayStats(window int) *relayStats {
+	if window <= 0 {
+		window = statsWindowDefault
+	}
+	return &relayStats{
+		samples: make([]statsSample, 0, window),
+		errors:  make(map[ErrorClass]uint64),
+	}
+}
+
+func (self *relayStats) record(latency time.Duration, err error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	s := statsSample{latency: latency, failed: err != nil}
+	if len(self.samples) < cap(self.samples) {
+		self.samples = append(self.samples, s)
+	} else {
+		self.samples[self.next] = s
+		self.next = (self.next + 1) % len(self.samples)
+	}
+	self.total++
+	if err != nil {
+		self.lastErr = err.Error()
+		self.lastErrTime = time.Now()
+		self.errors[ClassifyError(err)]++
+	}
+}
+
+// recordError counts a failure found after the request completed.
+func (self *relayStats) recordError(err error) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.errors[ClassifyError(err)]++
+}
+
+func (self *relayStats) snapshot() RelayStats {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+
+	stats := RelayStats{
+		Requests:     len(self.samples),
+		SuccessRatio: 1,
+		Total:        self.total,
+		LastError:    self.lastErr,
+		LastErrorAt:  self.lastErrTime,
+		Errors:       make(map[ErrorClass]uint64, len(self.errors)),
+	}
+	for class, n := range self.errors {
+		stats.Errors[class] = n
+	}
+	if len(self.samples) == 0 {
+		return stats
+	}
+	latencies := make([]time.Duration, 0, len(self.samples))
+	for _, s := range self.samples {
+		latencies = append(latencies, s.latency)
+		if s.failed {
+			stats.Failures++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.SuccessRatio = float64(stats.Requests-stats.Failures) / float64(stats.Requests)
+	stats.P50 = percentile(latencies, 50)
+	stats.P90 = percentile(latencies, 90)
+	stats.P99 = percentile(latencies, 99)
+	return stats
+}
+
+// percentile uses the nearest rank of the sorted values.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Stats returns the latency and success statistics of the recent requests to the relay.
+func (self *Flashbot) Stats() RelayStats {
+	stats := self.stats.snapshot()
+	stats.Relay = self.Api().URL
+	return stats
+}
+
+// Stats returns the statistics of all relays.
+func (self *Multi) Stats() []RelayStats {
+	flashbots := self.Flashbots()
+	stats := make([]RelayStats, 0, len(flashbots))
+	for _, f := range flashbots {
+		stats = append(stats, f.Stats())
+	}
+	return stats
+}
+
+// ByLatency orders the relays from the fastest to the slowest median latency,
+// relays which failed more of the recent requests come after the more reliable ones.
+func (self *Multi) ByLatency() []Flashboter {
+	flashbots := append([]Flashboter(nil), self.Flashbots()...)
+	stats := make(map[Flashboter]RelayStats, len(flashbots))
+	for _, f := range flashbots {
+		stats[f] = f.Stats()
+	}
+	sort.SliceStable(flashbots, func(i, j int) bool {
+		si, sj := stats[flashbots[i]], stats[flashbots[j]]
+		if si.SuccessRatio != sj.SuccessRatio {
+			return si.SuccessRatio > sj.SuccessRatio
+		}
+		return si.P50 < sj.P50
+	})
+	return flashbots
+}
@@ -0,0 +1,88 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+)
+
+func TestStats(t *testing.T) {
+	fail := false
+	srvSlow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srvSlow.Close()
+	srvFast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srvFast.Close()
+
+	slow, err := New(nil, &Api{URL: srvSlow.URL, SkipFlashbotsSignature: true}, WithStatsWindow(4))
+	testutil.Ok(t, err)
+	fast, err := New(nil, &Api{URL: srvFast.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	stats := slow.Stats()
+	testutil.Equals(t, srvSlow.URL, stats.Relay)
+	testutil.Equals(t, 0, stats.Requests)
+	testutil.Equals(t, 1.0, stats.SuccessRatio)
+
+	txs := []string{signedTxHex(t)}
+	for i := 0; i < 3; i++ {
+		_, err = slow.SendBundle(ctx, txs, 11, nil)
+		testutil.Ok(t, err)
+		_, err = fast.SendBundle(ctx, txs, 11, nil)
+		testutil.Ok(t, err)
+	}
+	fail = true
+	for i := 0; i < 2; i++ {
+		_, err = slow.SendBundle(ctx, txs, 11, nil)
+		testutil.NotOk(t, err)
+	}
+
+	// Only the last 4 requests are in the window.
+	stats = slow.Stats()
+	testutil.Equals(t, 4, stats.Requests)
+	testutil.Equals(t, 2, stats.Failures)
+	testutil.Equals(t, 0.5, stats.SuccessRatio)
+	testutil.Equals(t, uint64(5), stats.Total)
+	testutil.Assert(t, stats.P50 >= 20*time.Millisecond, "unexpected p50:%v", stats.P50)
+	testutil.Assert(t, stats.P50 <= stats.P90 && stats.P90 <= stats.P99, "unordered percentiles:%+v", stats)
+	testutil.Assert(t, stats.LastError != "" && !stats.LastErrorAt.IsZero(), "last error should be set")
+
+	multi := NewMultiRelay(slow, fast)
+	testutil.Equals(t, 2, len(multi.Stats()))
+	testutil.Equals(t, []Flashboter{fast, slow}, multi.ByLatency())
+
+	// Canceled requests aren't counted.
+	cctx, cncl := context.WithCancel(ctx)
+	cncl()
+	_, err = fast.SendBundle(cctx, txs, 11, nil)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 3, fast.Stats().Requests)
+}
+
+func TestPercentile(t *testing.T) {
+	var values []time.Duration
+	for i := 1; i <= 100; i++ {
+		values = append(values, time.Duration(i))
+	}
+	testutil.Equals(t, time.Duration(50), percentile(values, 50))
+	testutil.Equals(t, time.Duration(99), percentile(values, 99))
+	testutil.Equals(t, time.Duration(1), percentile(values[:1], 50))
+}
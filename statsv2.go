@@ -0,0 +1,91 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// The V2 stats methods report the builders which considered the bundle
+// and the payments to validators instead of miners.
+const (
+	MethodGetBundleStatsV2 = "flashbots_getBundleStatsV2"
+	MethodGetUserStatsV2   = "flashbots_getUserStatsV2"
+)
+
+type ParamsUserStatsV2 struct {
+	BlockNum string `json:"blockNumber"`
+}
+
+type UserStatsV2 struct {
+	IsHighPriority           bool   `json:"isHighPriority"`
+	AllTimeValidatorPayments string `json:"allTimeValidatorPayments"`
+	AllTimeGasSimulated      string `json:"allTimeGasSimulated"`
+	Last7dValidatorPayments  string `json:"last7dValidatorPayments"`
+	Last7dGasSimulated       string `json:"last7dGasSimulated"`
+	Last1dValidatorPayments  string `json:"last1dValidatorPayments"`
+	Last1dGasSimulated       string `json:"last1dGasSimulated"`
+}
+
+type ResultUserStatsV2 struct {
+	Error  `json:"error,omitempty"`
+	Result UserStatsV2
+	// Raw is the response body for the fields which aren't parsed.
+	Raw json.RawMessage `json:"-"`
+}
+
+// GetBundleStatsV2 is GetBundleStats with flashbots_getBundleStatsV2
+// which reports the builders that considered and sealed the bundle.
+func (self *Flashbot) GetBundleStatsV2(ctx context.Context, bundleHash string, blockNum uint64) (*ResultBundleStats, error) {
+	param := ParamsStats{
+		BundleHash: bundleHash,
+		BlockNum:   hexutil.EncodeUint64(blockNum),
+	}
+
+	resp, err := self.req(ctx, MethodGetBundleStatsV2, param)
+	if err != nil {
+		return nil, errors.Wrap(err, "flashbot bundle stats v2 request")
+	}
+
+	rr := &ResultBundleStats{Raw: resp}
+
+	err = self.unmarshal(resp, rr)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal flashbot bundle stats v2 response")
+	}
+
+	if rr.Error.Code != 0 {
+		return nil, errors.Wrap(self.rpcError(rr.Error), "flashbot request returned an error")
+	}
+
+	return rr, nil
+}
+
+// GetUserStatsV2 is GetUserStats with flashbots_getUserStatsV2
+// which reports the payments to validators.
+func (self *Flashbot) GetUserStatsV2(ctx context.Context, blockNum uint64) (*ResultUserStatsV2, error) {
+	param := ParamsUserStatsV2{BlockNum: hexutil.EncodeUint64(blockNum)}
+
+	resp, err := self.req(ctx, MethodGetUserStatsV2, param)
+	if err != nil {
+		return nil, errors.Wrap(err, "flashbot user stats v2 request")
+	}
+
+	rr := &ResultUserStatsV2{Raw: resp}
+
+	err = self.unmarshal(resp, rr)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal flashbot user stats v2 response")
+	}
+
+	if rr.Error.Code != 0 {
+		return nil, errors.Wrap(self.rpcError(rr.Error), "flashbot request returned an error")
+	}
+
+	return rr, nil
+}
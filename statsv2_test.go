@@ -0,0 +1,64 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStatsV2(t *testing.T) {
+	var (
+		method string
+		params []json.RawMessage
+		resp   string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		testutil.Ok(t, json.Unmarshal(msg.Params, &params))
+		method = msg.Method
+		_, err := w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	privKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	flashbot, err := New(privKey, &Api{URL: srv.URL, SupportsStats: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{
+		"isSimulated":true,
+		"isHighPriority":true,
+		"consideredByBuildersAt":[{"pubkey":"0xa1","timestamp":"2023-01-02T15:04:06Z"}],
+		"sealedByBuildersAt":[{"pubkey":"0xa1","timestamp":"2023-01-02T15:04:07Z"}]
+	}}`
+	stats, err := flashbot.GetBundleStatsV2(ctx, "0x1", 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, MethodGetBundleStatsV2, method)
+	testutil.Equals(t, `{"blockNumber":"0xa","bundleHash":"0x1"}`, string(params[0]))
+	testutil.Assert(t, stats.Result.IsSimulated, "bundle should be simulated")
+	testutil.Equals(t, "0xa1", stats.Result.SealedByBuildersAt[0].Pubkey)
+
+	resp = `{"jsonrpc":"2.0","id":1,"result":{"isHighPriority":true,"allTimeValidatorPayments":"1000","last1dGasSimulated":"21000"}}`
+	userStats, err := flashbot.GetUserStatsV2(ctx, 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, MethodGetUserStatsV2, method)
+	testutil.Equals(t, `{"blockNumber":"0xa"}`, string(params[0]))
+	testutil.Assert(t, userStats.Result.IsHighPriority, "user should be high priority")
+	testutil.Equals(t, "1000", userStats.Result.AllTimeValidatorPayments)
+	testutil.Equals(t, "21000", userStats.Result.Last1dGasSimulated)
+
+	resp = `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"block too old"}}`
+	_, err = flashbot.GetUserStatsV2(ctx, 10)
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"time"
+)
+
+// BreakerState is the state of the circuit breaker of a relay.
+type BreakerState string
+
+const (
+	BreakerDisabled BreakerState = "disabled"
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	// BreakerHalfOpen lets a single request through after the cool-down.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// RelayStatus is a snapshot of the state of a relay client
+// for the health endpoints of the host application.
+type RelayStatus struct {
+	Relay string
+	// Healthy is false when the relay failed the health checks of Multi
+	// or when its circuit breaker is open.
+	Healthy          bool
+	Breaker          BreakerState
+	BreakerOpenUntil time.Time
+	// Stats include the last error of the relay.
+	Stats RelayStats
+}
+
+// Status returns a snapshot of the relay state.
+func (self *Flashbot) Status() RelayStatus {
+	status := RelayStatus{
+		Relay:   self.Api().URL,
+		Healthy: true,
+		Breaker: BreakerDisabled,
+		Stats:   self.Stats(),
+	}
+	if self.breaker != nil {
+		status.Breaker, status.BreakerOpenUntil = self.breaker.state()
+		status.Healthy = status.Breaker != BreakerOpen
+	}
+	return status
+}
+
+func (self *circuitBreaker) state() (BreakerState, time.Time) {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	switch {
+	case time.Now().Before(self.openUntil):
+		return BreakerOpen, self.openUntil
+	case self.failures >= self.cfg.Threshold:
+		return BreakerHalfOpen, self.openUntil
+	default:
+		return BreakerClosed, time.Time{}
+	}
+}
+
+// MultiStatus is a snapshot of the state of all relays.
+type MultiStatus struct {
+	// Healthy is set when at least one relay is healthy.
+	Healthy bool
+	Relays  []RelayStatus
+}
+
+// Status returns a snapshot of all relays including the results of the health checks.
+func (self *Multi) Status() MultiStatus {
+	flashbots := self.Flashbots()
+
+	self.mtx.Lock()
+	unhealthy := make(map[Flashboter]bool, len(self.unhealthy))
+	for f, u := range self.unhealthy {
+		unhealthy[f] = u
+	}
+	self.mtx.Unlock()
+
+	var status MultiStatus
+	for _, f := range flashbots {
+		s := f.Status()
+		if unhealthy[f] {
+			s.Healthy = false
+		}
+		status.Healthy = status.Healthy || s.Healthy
+		status.Relays = append(status.Relays, s)
+	}
+	return status
+}
+
+// TrackerStatus is a snapshot of the submissions tracked by a Tracker.
+type TrackerStatus struct {
+	Bundles    int
+	PrivateTxs int
+	// LastCheckErr is the error of the last check of the tracked submissions.
+	LastCheckErr   string
+	LastCheckErrAt time.Time
+}
+
+// Status returns the number of the pending submissions and the last failed check.
+func (self *Tracker) Status() TrackerStatus {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return TrackerStatus{
+		Bundles:        len(self.bundles),
+		PrivateTxs:     len(self.txs),
+		LastCheckErr:   self.lastErr,
+		LastCheckErrAt: self.lastErrAt,
+	}
+}
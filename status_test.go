@@ -0,0 +1,74 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+type failingChain struct{}
+
+func (failingChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, errors.New("node down")
+}
+
+func (failingChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, errors.New("node down")
+}
+
+func TestStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	breaking, err := New(nil, &Api{
+		URL:                    srv.URL,
+		SkipFlashbotsSignature: true,
+		CircuitBreaker:         &CircuitBreakerConfig{Threshold: 1, CoolDown: 50 * time.Millisecond},
+	})
+	testutil.Ok(t, err)
+	plain, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	status := breaking.Status()
+	testutil.Equals(t, BreakerClosed, status.Breaker)
+	testutil.Assert(t, status.Healthy, "relay should be healthy")
+	testutil.Equals(t, BreakerDisabled, plain.Status().Breaker)
+
+	_, err = breaking.SendBundle(ctx, []string{signedTxHex(t)}, 11, nil)
+	testutil.NotOk(t, err)
+	status = breaking.Status()
+	testutil.Equals(t, BreakerOpen, status.Breaker)
+	testutil.Assert(t, !status.Healthy, "relay with an open breaker shouldn't be healthy")
+	testutil.Assert(t, status.Stats.LastError != "", "last error should be set")
+	time.Sleep(60 * time.Millisecond)
+	testutil.Equals(t, BreakerHalfOpen, breaking.Status().Breaker)
+
+	// The health checks of Multi mark relays unhealthy.
+	multi := NewMultiRelay(breaking, plain)
+	multi.unhealthy[plain] = true
+	ms := multi.Status()
+	testutil.Assert(t, ms.Healthy, "a half open relay is still usable")
+	testutil.Equals(t, 2, len(ms.Relays))
+	testutil.Assert(t, !ms.Relays[1].Healthy, "relay failing the health checks shouldn't be healthy")
+
+	tracker := NewTracker(plain, failingChain{}, TrackerConfig{})
+	tracker.bundles["0x1"] = &trackedBundle{hash: "0x1"}
+	testutil.NotOk(t, tracker.Check(ctx))
+	ts := tracker.Status()
+	testutil.Equals(t, 1, ts.Bundles)
+	testutil.Equals(t, 0, ts.PrivateTxs)
+	testutil.Assert(t, ts.LastCheckErr != "" && !ts.LastCheckErrAt.IsZero(), "last check error should be set")
+}
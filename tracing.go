@@ -0,0 +1,55 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/cryptoriums/flashbot"
+
+// Span attributes set on the relay calls.
+const (
+	AttrRelay         = attribute.Key("flashbot.relay")
+	AttrMethod        = attribute.Key("rpc.method")
+	AttrCorrelationID = attribute.Key("flashbot.correlation_id")
+	AttrBlock         = attribute.Key("flashbot.block")
+	AttrTxs           = attribute.Key("flashbot.txs")
+	AttrAttempt       = attribute.Key("flashbot.attempt")
+	AttrStatusCode    = attribute.Key("http.status_code")
+)
+
+// WithTracerProvider creates OpenTelemetry spans for SendBundle, CallBundle
+// and every relay request as children of the span in the context
+// so submissions show up in the trace of the bot.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(f *Flashbot) {
+		f.tracer = provider.Tracer(tracerName)
+	}
+}
+
+func (self *Flashbot) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if self.tracer == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	attrs = append(attrs, AttrRelay.String(self.Api().URL))
+	return self.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error and ends spans started by startSpan,
+// without a tracer the span belongs to the caller and is left open.
+func (self *Flashbot) endSpan(span trace.Span, err error) {
+	if self.tracer == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
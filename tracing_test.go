@@ -0,0 +1,69 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true}, WithTracerProvider(provider))
+	testutil.Ok(t, err)
+
+	ctx, parent := provider.Tracer("bot").Start(context.Background(), "opportunity")
+	_, err = flashbot.SendBundle(ctx, []string{signedTxHex(t)}, 11, nil)
+	testutil.Ok(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	testutil.Equals(t, 3, len(spans))
+	req, send := spans[0], spans[1]
+	testutil.Equals(t, MethodSendBundle, req.Name())
+	testutil.Equals(t, "SendBundle", send.Name())
+	testutil.Equals(t, send.SpanContext().SpanID(), req.Parent().SpanID())
+	testutil.Equals(t, parent.SpanContext().SpanID(), send.Parent().SpanID())
+
+	attrs := func(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+		m := make(map[attribute.Key]attribute.Value)
+		for _, kv := range kvs {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+	reqAttrs := attrs(req.Attributes())
+	testutil.Equals(t, srv.URL, reqAttrs[AttrRelay].AsString())
+	testutil.Equals(t, MethodSendBundle, reqAttrs[AttrMethod].AsString())
+	testutil.Equals(t, int64(http.StatusOK), reqAttrs[AttrStatusCode].AsInt64())
+	testutil.Assert(t, reqAttrs[AttrCorrelationID].AsString() != "", "correlation id should be set")
+	testutil.Equals(t, int64(11), attrs(send.Attributes())[AttrBlock].AsInt64())
+
+	// Failures set the status of the span.
+	status = http.StatusBadGateway
+	_, err = flashbot.SendBundle(context.Background(), []string{signedTxHex(t)}, 11, nil)
+	testutil.NotOk(t, err)
+	spans = recorder.Ended()
+	req, send = spans[3], spans[4]
+	testutil.Equals(t, codes.Error, req.Status().Code)
+	testutil.Equals(t, codes.Error, send.Status().Code)
+	testutil.Equals(t, int64(http.StatusBadGateway), attrs(req.Attributes())[AttrStatusCode].AsInt64())
+}
@@ -0,0 +1,379 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ErrInclusionUnknown is the Err of the EventExpired of a watched bundle
+// without transaction hashes as its inclusion can't be read from the chain.
+var ErrInclusionUnknown = errors.New("inclusion unknown without the bundle transactions")
+
+const (
+	trackerIntervalDefault = 2 * time.Second
+	// privateTxBlocksDefault is how long the relay keeps a private tx without a max block.
+	privateTxBlocksDefault = 25
+)
+
+// EventType is a stage in the lifecycle of a bundle or a private transaction.
+type EventType string
+
+const (
+	EventSubmitted           EventType = "submitted"
+	EventSimulated           EventType = "simulated"
+	EventConsideredByBuilder EventType = "consideredByBuilder"
+	EventIncluded            EventType = "included"
+	// EventDropped is emitted when the relay rejects the submission.
+	EventDropped EventType = "dropped"
+	// EventExpired is emitted when the target or max block passes without inclusion.
+	EventExpired EventType = "expired"
+)
+
+// Event reports a change in the state of a tracked bundle or private transaction.
+type Event struct {
+	Type EventType
+	// BundleHash is set for bundles and TxHash for private transactions.
+	BundleHash string
+	TxHash     common.Hash
+	BlockNum   uint64
+	Relay      string
+	// Builder is the pubkey of the builder for EventConsideredByBuilder.
+	Builder string
+	// Err is the rejection for EventDropped or
+	// the failed attribution or ErrInclusionUnknown for EventExpired.
+	Err error
+	// Attribution explains an EventExpired of a bundle when TrackerConfig.Blocks is set.
+	Attribution *Attribution
+	Time        time.Time
+}
+
+// TrackerConfig configures a Tracker.
+type TrackerConfig struct {
+	// OnEvent is called for every event from the goroutine that sends or runs the tracker,
+	// a slow handler delays the tracking so it should hand the events off, for example to a channel.
+	OnEvent func(Event)
+	// Interval between the checks of the tracked submissions, defaults to 2s.
+	Interval time.Duration
+	// Journal records the inclusion status of the tracked bundles.
+	Journal *Journal
+	// Blocks enables inspecting the target block of expired bundles with AttributeMiss.
+	Blocks      BlockReader
+	Attribution AttributionConfig
+}
+
+// Tracker sends bundles and private transactions and emits events
+// as they move through the relay and the chain.
+type Tracker struct {
+	flashbot Flashboter
+	chain    ChainReader
+	cfg      TrackerConfig
+
+	mtx       sync.Mutex
+	bundles   map[string]*trackedBundle
+	txs       map[common.Hash]*trackedTx
+	lastErr   string
+	lastErrAt time.Time
+}
+
+type trackedBundle struct {
+	hash     string
+	blockNum uint64
+	// txsHex is only set for the bundles sent by the tracker.
+	txsHex     []string
+	txHashes   []common.Hash
+	simulated  bool
+	considered map[string]bool
+}
+
+type trackedTx struct {
+	hash     common.Hash
+	maxBlock uint64
+}
+
+func NewTracker(flashbot Flashboter, chain ChainReader, cfg TrackerConfig) *Tracker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = trackerIntervalDefault
+	}
+	return &Tracker{
+		flashbot: flashbot,
+		chain:    chain,
+		cfg:      cfg,
+		bundles:  make(map[string]*trackedBundle),
+		txs:      make(map[common.Hash]*trackedTx),
+	}
+}
+
+func (self *Tracker) emit(e Event) {
+	if self.cfg.OnEvent == nil {
+		return
+	}
+	e.Relay = self.flashbot.Api().URL
+	e.Time = time.Now()
+	self.cfg.OnEvent(e)
+}
+
+// SendBundle sends the bundle and tracks it until its block passes.
+func (self *Tracker) SendBundle(ctx context.Context, txsHex []string, blockNum uint64, opts *SendBundleOpts) (*Response, error) {
+	resp, err := self.flashbot.SendBundle(ctx, txsHex, blockNum, opts)
+	if err != nil {
+		hash, _ := BundleHash(txsHex)
+		self.emit(Event{Type: EventDropped, BundleHash: hash.Hex(), BlockNum: blockNum, Err: err})
+		return nil, err
+	}
+
+	self.mtx.Lock()
+	self.bundles[resp.BundleHash] = &trackedBundle{
+		hash:       resp.BundleHash,
+		blockNum:   blockNum,
+		txsHex:     txsHex,
+		considered: make(map[string]bool),
+	}
+	self.mtx.Unlock()
+
+	self.emit(Event{Type: EventSubmitted, BundleHash: resp.BundleHash, BlockNum: blockNum})
+	return resp, nil
+}
+
+// WatchBundle tracks a bundle sent by another process until its block passes.
+// The bundle hash is computed from the transaction hashes when empty.
+// Without the transaction hashes only the relay stats are followed
+// and the bundle expires with ErrInclusionUnknown.
+func (self *Tracker) WatchBundle(bundleHash string, blockNum uint64, txHashes []common.Hash) error {
+	if bundleHash == "" {
+		if len(txHashes) == 0 {
+			return errors.New("bundle hash or transaction hashes are required")
+		}
+		bundleHash = BundleHashFromTxHashes(txHashes).Hex()
+	}
+
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	self.bundles[bundleHash] = &trackedBundle{
+		hash:       bundleHash,
+		blockNum:   blockNum,
+		txHashes:   txHashes,
+		considered: make(map[string]bool),
+	}
+	return nil
+}
+
+// Tracked returns the number of submissions that are still tracked.
+func (self *Tracker) Tracked() int {
+	self.mtx.Lock()
+	defer self.mtx.Unlock()
+	return len(self.bundles) + len(self.txs)
+}
+
+// SendPrivateTransaction sends the transaction and tracks it until it is included or its max block passes.
+// Without a max block the relay keeps the transaction for 25 blocks.
+func (self *Tracker) SendPrivateTransaction(ctx context.Context, txHex string, maxBlock uint64, preferences *PrivateTxPreferences) (*SendPrivateTransactionResponse, error) {
+	tx, err := decodeTx(txHex)
+	if err != nil {
+		return nil, err
+	}
+	if maxBlock == 0 {
+		head, err := self.chain.BlockNumber(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading block number")
+		}
+		maxBlock = head + privateTxBlocksDefault
+	}
+
+	resp, err := self.flashbot.SendPrivateTransaction(ctx, txHex, maxBlock, preferences)
+	if err != nil {
+		self.emit(Event{Type: EventDropped, TxHash: tx.Hash(), BlockNum: maxBlock, Err: err})
+		return nil, err
+	}
+
+	self.mtx.Lock()
+	self.txs[tx.Hash()] = &trackedTx{hash: tx.Hash(), maxBlock: maxBlock}
+	self.mtx.Unlock()
+
+	self.emit(Event{Type: EventSubmitted, TxHash: tx.Hash(), BlockNum: maxBlock})
+	return resp, nil
+}
+
+// Run checks the tracked submissions until the context is done.
+// Failed checks don't stop it as the relay and node errors are usually temporary,
+// the last one is reported by Status.
+func (self *Tracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(self.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		_ = self.Check(ctx)
+	}
+}
+
+// Check updates the state of all tracked submissions once.
+// A failure for one submission doesn't stop checking the others
+// and the first error is returned.
+func (self *Tracker) Check(ctx context.Context) error {
+	err := self.check(ctx)
+	if err != nil {
+		self.mtx.Lock()
+		self.lastErr, self.lastErrAt = err.Error(), time.Now()
+		self.mtx.Unlock()
+	}
+	return err
+}
+
+func (self *Tracker) check(ctx context.Context) error {
+	head, err := self.chain.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "reading block number")
+	}
+
+	self.mtx.Lock()
+	bundles := make([]*trackedBundle, 0, len(self.bundles))
+	for _, b := range self.bundles {
+		bundles = append(bundles, b)
+	}
+	txs := make([]*trackedTx, 0, len(self.txs))
+	for _, tx := range self.txs {
+		txs = append(txs, tx)
+	}
+	self.mtx.Unlock()
+
+	var errs []error
+	for _, b := range bundles {
+		if err := self.checkBundle(ctx, b, head); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, tx := range txs {
+		if err := self.checkTx(ctx, tx, head); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Wrapf(errs[0], "failed checks:%v", len(errs))
+	}
+	return nil
+}
+
+// checkBundle follows the relay stats and the inclusion of the bundle.
+// The stats are optional so failing to get them, for example for a watched bundle
+// the relay doesn't know, doesn't stop checking the inclusion and is returned after it.
+func (self *Tracker) checkBundle(ctx context.Context, b *trackedBundle, head uint64) error {
+	var statsErr error
+	if self.flashbot.Api().SupportsStats {
+		stats, err := self.flashbot.GetBundleStats(ctx, b.hash, b.blockNum)
+		if err != nil {
+			statsErr = errors.Wrapf(err, "bundle stats bundleHash:%v", b.hash)
+		} else {
+			self.emitStats(b, stats)
+		}
+	}
+	if err := self.checkBundleInclusion(ctx, b, head); err != nil {
+		return err
+	}
+	return statsErr
+}
+
+func (self *Tracker) emitStats(b *trackedBundle, stats *ResultBundleStats) {
+	if stats.Result.IsSimulated && !b.simulated {
+		b.simulated = true
+		self.emit(Event{Type: EventSimulated, BundleHash: b.hash, BlockNum: b.blockNum})
+	}
+	for _, c := range stats.Result.ConsideredByBuildersAt {
+		if !b.considered[c.Pubkey] {
+			b.considered[c.Pubkey] = true
+			self.emit(Event{Type: EventConsideredByBuilder, BundleHash: b.hash, BlockNum: b.blockNum, Builder: c.Pubkey})
+		}
+	}
+}
+
+func (self *Tracker) checkBundleInclusion(ctx context.Context, b *trackedBundle, head uint64) error {
+	if head < b.blockNum {
+		return nil
+	}
+	if b.txsHex == nil {
+		return self.checkWatchedBundle(ctx, b)
+	}
+	included, err := bundleIncluded(ctx, self.chain, b.txsHex)
+	if err != nil {
+		return err
+	}
+	self.mtx.Lock()
+	delete(self.bundles, b.hash)
+	self.mtx.Unlock()
+	if self.cfg.Journal != nil {
+		status := StatusExpired
+		if included {
+			status = StatusIncluded
+		}
+		if err := self.cfg.Journal.SetStatus(b.txsHex, b.blockNum, status); err != nil {
+			return errors.Wrapf(err, "journal status bundleHash:%v", b.hash)
+		}
+	}
+	if included {
+		self.emit(Event{Type: EventIncluded, BundleHash: b.hash, BlockNum: b.blockNum})
+		return nil
+	}
+	e := Event{Type: EventExpired, BundleHash: b.hash, BlockNum: b.blockNum}
+	if self.cfg.Blocks != nil {
+		e.Attribution, e.Err = AttributeMiss(ctx, self.cfg.Blocks, b.txsHex, b.blockNum, self.cfg.Attribution)
+	}
+	self.emit(e)
+	return nil
+}
+
+// checkWatchedBundle completes a bundle added with WatchBundle,
+// the journal and the attribution need the transactions so they are skipped.
+func (self *Tracker) checkWatchedBundle(ctx context.Context, b *trackedBundle) error {
+	e := Event{Type: EventExpired, BundleHash: b.hash, BlockNum: b.blockNum, Err: ErrInclusionUnknown}
+	if len(b.txHashes) > 0 {
+		included, err := txsIncluded(ctx, self.chain, b.txHashes)
+		if err != nil {
+			return err
+		}
+		e.Err = nil
+		if included {
+			e.Type = EventIncluded
+		}
+	}
+	self.mtx.Lock()
+	delete(self.bundles, b.hash)
+	self.mtx.Unlock()
+	self.emit(e)
+	return nil
+}
+
+func (self *Tracker) checkTx(ctx context.Context, tx *trackedTx, head uint64) error {
+	receipt, err := self.chain.TransactionReceipt(ctx, tx.hash)
+	if errors.Is(err, ethereum.NotFound) {
+		receipt = nil
+	} else if err != nil {
+		return errors.Wrapf(err, "reading receipt tx:%v", tx.hash.Hex())
+	}
+	if receipt == nil && head <= tx.maxBlock {
+		return nil
+	}
+
+	self.mtx.Lock()
+	delete(self.txs, tx.hash)
+	self.mtx.Unlock()
+	if receipt != nil {
+		blockNum := tx.maxBlock
+		if receipt.BlockNumber != nil {
+			blockNum = receipt.BlockNumber.Uint64()
+		}
+		self.emit(Event{Type: EventIncluded, TxHash: tx.hash, BlockNum: blockNum})
+	} else {
+		self.emit(Event{Type: EventExpired, TxHash: tx.hash, BlockNum: tx.maxBlock})
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+func TestTracker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := &jsonrpcMessage{}
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(msg))
+		var resp string
+		switch msg.Method {
+		case MethodSendBundle:
+			resp = `{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`
+		case MethodGetBundleStats:
+			resp = `{"jsonrpc":"2.0","id":1,"result":{"isSimulated":true,"consideredByBuildersAt":[{"pubkey":"0xa"},{"pubkey":"0xb"}]}}`
+		case MethodSendPrivateTransaction:
+			resp = `{"jsonrpc":"2.0","id":1,"result":"0x2"}`
+		}
+		_, err := w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true, SupportsStats: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	var events []Event
+	chain := &chainMock{head: 10, included: make(map[common.Hash]bool)}
+	tracker := NewTracker(flashbot, chain, TrackerConfig{OnEvent: func(e Event) { events = append(events, e) }})
+
+	bundle := []string{signedTxHex(t)}
+	_, err = tracker.SendBundle(ctx, bundle, 11, nil)
+	testutil.Ok(t, err)
+	privateTx := signedTxHex(t)
+	_, err = tracker.SendPrivateTransaction(ctx, privateTx, 0, nil)
+	testutil.Ok(t, err)
+
+	testutil.Ok(t, tracker.Check(ctx))
+	// Checking again doesn't repeat the stats events.
+	testutil.Ok(t, tracker.Check(ctx))
+	types := func() []EventType {
+		var tt []EventType
+		for _, e := range events {
+			tt = append(tt, e.Type)
+		}
+		return tt
+	}
+	testutil.Equals(t, []EventType{EventSubmitted, EventSubmitted, EventSimulated, EventConsideredByBuilder, EventConsideredByBuilder}, types())
+	testutil.Equals(t, uint64(35), events[1].BlockNum)
+	testutil.Equals(t, "0xb", events[4].Builder)
+	testutil.Equals(t, srv.URL, events[0].Relay)
+
+	// The bundle block passes without inclusion and the private tx is included.
+	events = nil
+	chain.head = 11
+	chain.include(t, []string{privateTx})
+	testutil.Ok(t, tracker.Check(ctx))
+	testutil.Equals(t, []EventType{EventExpired, EventIncluded}, types())
+	testutil.Equals(t, "0x1", events[0].BundleHash)
+
+	// Nothing is tracked anymore.
+	events = nil
+	testutil.Ok(t, tracker.Check(ctx))
+	testutil.Equals(t, 0, len(events))
+}
+
+func TestTrackerDropped(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle rejected"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true})
+	testutil.Ok(t, err)
+
+	var events []Event
+	chain := &chainMock{head: 10, included: make(map[common.Hash]bool)}
+	tracker := NewTracker(flashbot, chain, TrackerConfig{OnEvent: func(e Event) { events = append(events, e) }})
+	_, err = tracker.SendBundle(context.Background(), []string{signedTxHex(t)}, 11, nil)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, 1, len(events))
+	testutil.Equals(t, EventDropped, events[0].Type)
+	testutil.NotOk(t, events[0].Err)
+}
+
+func TestTrackerWatchBundle(t *testing.T) {
+	// The relay doesn't know the bundle 0x1 which doesn't stop tracking it or the other bundle.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		testutil.Ok(t, err)
+		resp := `{"jsonrpc":"2.0","id":1,"result":{"isSimulated":true}}`
+		if strings.Contains(string(body), `"bundleHash":"0x1"`) {
+			resp = `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle not found"}}`
+		}
+		_, err = w.Write([]byte(resp))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true, SupportsStats: true})
+	testutil.Ok(t, err)
+	ctx := context.Background()
+
+	var events []Event
+	chain := &chainMock{head: 10, included: make(map[common.Hash]bool)}
+	tracker := NewTracker(flashbot, chain, TrackerConfig{OnEvent: func(e Event) { events = append(events, e) }})
+
+	txHex := signedTxHex(t)
+	tx, err := decodeTx(txHex)
+	testutil.Ok(t, err)
+	testutil.NotOk(t, tracker.WatchBundle("", 11, nil))
+	testutil.Ok(t, tracker.WatchBundle("", 11, []common.Hash{tx.Hash()}))
+	testutil.Ok(t, tracker.WatchBundle("0x1", 11, nil))
+	testutil.Equals(t, 2, tracker.Tracked())
+
+	testutil.NotOk(t, tracker.Check(ctx))
+	testutil.Equals(t, 1, len(events))
+	testutil.Equals(t, EventSimulated, events[0].Type)
+	testutil.Assert(t, tracker.Status().LastCheckErr != "", "the stats error should be recorded")
+
+	events = nil
+	chain.head = 11
+	chain.include(t, []string{txHex})
+	testutil.NotOk(t, tracker.Check(ctx))
+	testutil.Equals(t, 0, tracker.Tracked())
+	testutil.Equals(t, 2, len(events))
+	for _, e := range events {
+		switch e.BundleHash {
+		case "0x1":
+			testutil.Equals(t, EventExpired, e.Type)
+			testutil.Assert(t, errors.Is(e.Err, ErrInclusionUnknown), "unexpected error:%v", e.Err)
+		default:
+			bundleHash, err := BundleHash([]string{txHex})
+			testutil.Ok(t, err)
+			testutil.Equals(t, bundleHash.Hex(), e.BundleHash)
+			testutil.Equals(t, EventIncluded, e.Type)
+		}
+	}
+}
+
+func TestTrackerRunContinuesAfterErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"bundle not found"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true, SupportsStats: true})
+	testutil.Ok(t, err)
+
+	var events []Event
+	chain := &chainMock{head: 10, advance: true, included: make(map[common.Hash]bool)}
+	tracker := NewTracker(flashbot, chain, TrackerConfig{
+		Interval: time.Millisecond,
+		OnEvent:  func(e Event) { events = append(events, e) },
+	})
+	testutil.Ok(t, tracker.WatchBundle("0x1", 13, nil))
+
+	ctx, cncl := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cncl()
+	err = tracker.Run(ctx)
+	testutil.Assert(t, errors.Is(err, context.DeadlineExceeded), "unexpected error:%v", err)
+	testutil.Equals(t, 0, tracker.Tracked())
+	testutil.Equals(t, 1, len(events))
+	testutil.Equals(t, EventExpired, events[0].Type)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+)
+
+// TransportConfig tunes the connections to the relay.
+// Zero values keep the defaults of http.DefaultTransport.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept open to the relay.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes keep-alive connections which were idle for longer.
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake with the relay.
+	TLSHandshakeTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every request.
+	DisableKeepAlives bool
+	// ForceHTTP2 offers only HTTP/2 during the TLS handshake
+	// so relays without HTTP/2 support fail instead of falling back to HTTP/1.1.
+	// Plain http relays are unaffected.
+	ForceHTTP2 bool
+	// HTTP2PingInterval sends a ping on HTTP/2 connections
+	// which didn't receive any frame for this long
+	// and closes them when the ping fails, 0 disables the pings.
+	HTTP2PingInterval time.Duration
+}
+
+// WithTransportConfig tunes the transport used for the relay requests.
+// It is ignored when a client is set with WithHTTPClient.
+func WithTransportConfig(cfg TransportConfig) Option {
+	return func(f *Flashbot) {
+		f.transportCfg = &cfg
+	}
+}
+
+func (self *Flashbot) newTransport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: self.Api().ClientCertificates,
+		RootCAs:      self.Api().RootCAs,
+	}
+	if self.proxyURL != nil {
+		transport.Proxy = http.ProxyURL(self.proxyURL)
+	}
+
+	cfg := self.transportCfg
+	if cfg == nil {
+		return transport, nil
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if transport.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+			transport.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+
+	if !cfg.ForceHTTP2 && cfg.HTTP2PingInterval <= 0 {
+		return transport, nil
+	}
+	transport.TLSNextProto = nil
+	h2, err := http2.ConfigureTransports(transport)
+	if err != nil {
+		return nil, errors.Wrap(err, "configuring http2")
+	}
+	h2.ReadIdleTimeout = cfg.HTTP2PingInterval
+	if cfg.ForceHTTP2 {
+		transport.TLSClientConfig.NextProtos = []string{http2.NextProtoTLS}
+	}
+	return transport, nil
+}
@@ -0,0 +1,79 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cryptoriums/packages/testutil"
+)
+
+func TestTransportConfig(t *testing.T) {
+	var (
+		conns int32
+		proto int32
+	)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.StoreInt32(&proto, int32(r.ProtoMajor))
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	})
+	newServer := func(http2 bool) *httptest.Server {
+		srv := httptest.NewUnstartedServer(handler)
+		srv.EnableHTTP2 = http2
+		srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&conns, 1)
+			}
+		}
+		srv.StartTLS()
+		return srv
+	}
+	newApi := func(srv *httptest.Server) *Api {
+		roots := x509.NewCertPool()
+		roots.AddCert(srv.Certificate())
+		return &Api{URL: srv.URL, RootCAs: roots, SkipFlashbotsSignature: true}
+	}
+
+	// HTTP/2 with keep-alive pings.
+	srvH2 := newServer(true)
+	defer srvH2.Close()
+	flashbot, err := New(nil, newApi(srvH2), WithTransportConfig(TransportConfig{
+		ForceHTTP2:        true,
+		HTTP2PingInterval: time.Second,
+	}))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, int32(2), atomic.LoadInt32(&proto))
+
+	// Relays without HTTP/2 are rejected when it is forced.
+	srvH1 := newServer(false)
+	defer srvH1.Close()
+	flashbot, err = New(nil, newApi(srvH1), WithTransportConfig(TransportConfig{ForceHTTP2: true}))
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+	testutil.NotOk(t, err)
+
+	// A new connection for every request without keep-alives.
+	atomic.StoreInt32(&conns, 0)
+	flashbot, err = New(nil, newApi(srvH1), WithTransportConfig(TransportConfig{
+		DisableKeepAlives:   true,
+		TLSHandshakeTimeout: time.Second,
+	}))
+	testutil.Ok(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = flashbot.SendBundle(context.Background(), []string{"0x1"}, 10, nil)
+		testutil.Ok(t, err)
+	}
+	testutil.Equals(t, int32(1), atomic.LoadInt32(&proto))
+	testutil.Equals(t, int32(3), atomic.LoadInt32(&conns))
+}
@@ -0,0 +1,77 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// BundleTx is a bundle transaction together with the key of the account sending it.
+// These keys are independent of the searcher identity key used for the relay requests
+// so a single identity can submit bundles built from many accounts.
+type BundleTx struct {
+	Tx     types.TxData
+	PrvKey *ecdsa.PrivateKey
+}
+
+// SignTx signs a transaction and returns it hex encoded as expected by SendBundle.
+func SignTx(chainID *big.Int, tx types.TxData, prvKey *ecdsa.PrivateKey) (string, error) {
+	if prvKey == nil {
+		return "", errors.New("private key can't be empty")
+	}
+	signed, err := types.SignNewTx(prvKey, types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return "", errors.Wrap(err, "signing tx")
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return "", errors.Wrap(err, "encoding tx")
+	}
+	return hexutil.Encode(raw), nil
+}
+
+// SignBundle signs every transaction with its own key and
+// returns them hex encoded in the same order.
+func SignBundle(chainID *big.Int, txs ...BundleTx) ([]string, error) {
+	txsHex := make([]string, 0, len(txs))
+	for i, tx := range txs {
+		txHex, err := SignTx(chainID, tx.Tx, tx.PrvKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		txsHex = append(txsHex, txHex)
+	}
+	return txsHex, nil
+}
+
+func decodeTx(txHex string) (*types.Transaction, error) {
+	raw, err := hexutil.Decode(txHex)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding tx hex")
+	}
+	tx := &types.Transaction{}
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrap(err, "decoding tx")
+	}
+	return tx, nil
+}
+
+// txSender recovers the sender with the signer of the chain the transaction was signed for.
+func txSender(tx *types.Transaction) (common.Address, error) {
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "recovering tx sender")
+	}
+	return sender, nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSignBundle(t *testing.T) {
+	chainID := big.NewInt(1)
+	identity, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	bot1, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	bot2, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+
+	to := common.HexToAddress("0x1")
+	txsHex, err := SignBundle(chainID,
+		BundleTx{Tx: &types.DynamicFeeTx{ChainID: chainID, Nonce: 1, To: &to, Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1)}, PrvKey: bot1},
+		BundleTx{Tx: &types.LegacyTx{Nonce: 2, To: &to, Gas: 21000, GasPrice: big.NewInt(1)}, PrvKey: bot2},
+	)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(txsHex))
+
+	for i, bot := range []*ecdsa.PrivateKey{bot1, bot2} {
+		raw, err := hexutil.Decode(txsHex[i])
+		testutil.Ok(t, err)
+		tx := &types.Transaction{}
+		testutil.Ok(t, tx.UnmarshalBinary(raw))
+		from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+		testutil.Ok(t, err)
+		testutil.Equals(t, crypto.PubkeyToAddress(bot.PublicKey), from)
+	}
+
+	_, err = SignBundle(chainID, BundleTx{Tx: &types.LegacyTx{}})
+	testutil.NotOk(t, err)
+
+	// The relay requests are signed with the identity key only.
+	var signer common.Address
+	srv := newSignatureServer(t, &signer)
+	defer srv.Close()
+	identitySigner, err := NewECDSASigner(identity)
+	testutil.Ok(t, err)
+	flashbot, err := NewWithSigner(identitySigner, &Api{URL: srv.URL})
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(context.Background(), txsHex, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, crypto.PubkeyToAddress(identity.PublicKey), signer)
+}
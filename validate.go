@@ -0,0 +1,178 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const blockGasLimitDefault = 30_000_000
+
+// ErrInvalidBundle is returned when the bundle fails the validation before sending it.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// ValidationConfig enables checking the bundle transactions before sending them.
+type ValidationConfig struct {
+	// ChainID is compared with the chain id of the replay protected transactions,
+	// nil skips the check.
+	ChainID *big.Int
+	// BlockGasLimit bounds the gas of the bundle, defaults to 30M.
+	BlockGasLimit uint64
+	// MaxTxs and MaxSize limit the number of transactions and
+	// their total encoded size in bytes as capped by the relay, 0 means no limit.
+	MaxTxs  int
+	MaxSize int
+}
+
+func (self ValidationConfig) gasLimit() uint64 {
+	if self.BlockGasLimit == 0 {
+		return blockGasLimitDefault
+	}
+	return self.BlockGasLimit
+}
+
+// ValidationIssue is a problem with a bundle transaction.
+type ValidationIssue struct {
+	// Index of the transaction in the bundle, -1 for issues with the whole bundle.
+	Index int
+	Hash  common.Hash
+	Msg   string
+}
+
+// ValidationError is the report of all issues found in a bundle.
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (self *ValidationError) Error() string {
+	issues := make([]string, 0, len(self.Issues))
+	for _, i := range self.Issues {
+		if i.Index < 0 {
+			issues = append(issues, fmt.Sprintf("[bundle: %v]", i.Msg))
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("[tx:%v hash:%v %v]", i.Index, i.Hash.Hex(), i.Msg))
+	}
+	return fmt.Sprintf("%v issues:%v", ErrInvalidBundle, strings.Join(issues, " "))
+}
+
+func (self *ValidationError) Is(target error) bool {
+	return target == ErrInvalidBundle
+}
+
+// ValidateBundle checks the transactions for problems that make the relay drop the bundle:
+// malformed encoding, wrong chain id, nonces of a sender that don't increase by one,
+// gas over the block gas limit, zero gas price and bundles over the size limits.
+// The returned error is a *ValidationError listing all issues.
+func ValidateBundle(txsHex []string, cfg ValidationConfig) error {
+	gasLimit := cfg.gasLimit()
+
+	report := &ValidationError{}
+	addIssue := func(i int, hash common.Hash, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, ValidationIssue{Index: i, Hash: hash, Msg: fmt.Sprintf(format, args...)})
+	}
+	if len(txsHex) == 0 {
+		addIssue(-1, common.Hash{}, "no transactions")
+	}
+
+	if cfg.MaxTxs > 0 && len(txsHex) > cfg.MaxTxs {
+		addIssue(-1, common.Hash{}, "txs:%v over the limit:%v", len(txsHex), cfg.MaxTxs)
+	}
+
+	var gas uint64
+	var size int
+	nonces := make(map[common.Address]uint64)
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			addIssue(i, common.Hash{}, "%v", err)
+			continue
+		}
+		hash := tx.Hash()
+		gas += tx.Gas()
+		size += int(tx.Size())
+
+		if cfg.ChainID != nil && tx.Protected() && tx.ChainId().Cmp(cfg.ChainID) != 0 {
+			addIssue(i, hash, "chain id expected:%v got:%v", cfg.ChainID, tx.ChainId())
+		}
+		if tx.Gas() > gasLimit {
+			addIssue(i, hash, "gas:%v over the block gas limit:%v", tx.Gas(), gasLimit)
+		}
+		if tx.GasFeeCap().Sign() == 0 {
+			addIssue(i, hash, "zero gas price")
+		}
+
+		sender, err := txSender(tx)
+		if err != nil {
+			addIssue(i, hash, "%v", err)
+			continue
+		}
+		if prev, ok := nonces[sender]; ok && tx.Nonce() != prev+1 {
+			addIssue(i, hash, "nonce sender:%v expected:%v got:%v", sender.Hex(), prev+1, tx.Nonce())
+		}
+		nonces[sender] = tx.Nonce()
+	}
+	if gas > gasLimit {
+		addIssue(-1, common.Hash{}, "total gas:%v over the block gas limit:%v", gas, gasLimit)
+	}
+	if cfg.MaxSize > 0 && size > cfg.MaxSize {
+		addIssue(-1, common.Hash{}, "size:%v over the limit:%v", size, cfg.MaxSize)
+	}
+
+	if len(report.Issues) > 0 {
+		return report
+	}
+	return nil
+}
+
+// BlockBundle is a bundle for a single block.
+type BlockBundle struct {
+	BlockNum uint64
+	Txs      []string
+}
+
+// SplitBundle splits the transactions in order into bundles within the limits of the config
+// which target consecutive blocks starting at fromBlock.
+// Transactions that exceed the limits on their own return an error.
+// The split bundles should be checked with ValidateBundle as the other checks aren't done.
+func SplitBundle(txsHex []string, fromBlock uint64, cfg ValidationConfig) ([]BlockBundle, error) {
+	gasLimit := cfg.gasLimit()
+
+	var (
+		bundles []BlockBundle
+		current []string
+		gas     uint64
+		size    int
+	)
+	for i, txHex := range txsHex {
+		tx, err := decodeTx(txHex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tx:%v", i)
+		}
+		txSize := int(tx.Size())
+		if tx.Gas() > gasLimit || (cfg.MaxSize > 0 && txSize > cfg.MaxSize) {
+			return nil, errors.Errorf("tx:%v exceeds the limits on its own gas:%v size:%v", i, tx.Gas(), txSize)
+		}
+
+		full := gas+tx.Gas() > gasLimit ||
+			(cfg.MaxSize > 0 && size+txSize > cfg.MaxSize) ||
+			(cfg.MaxTxs > 0 && len(current) == cfg.MaxTxs)
+		if full {
+			bundles = append(bundles, BlockBundle{BlockNum: fromBlock + uint64(len(bundles)), Txs: current})
+			current, gas, size = nil, 0, 0
+		}
+		current = append(current, txHex)
+		gas += tx.Gas()
+		size += txSize
+	}
+	if len(current) == 0 {
+		return nil, errors.New("bundle has no transactions")
+	}
+	return append(bundles, BlockBundle{BlockNum: fromBlock + uint64(len(bundles)), Txs: current}), nil
+}
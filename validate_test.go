@@ -0,0 +1,122 @@
+// Copyright (c) The Cryptorium Authors.
+// Licensed under the MIT License.
+
+package flashbot
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptoriums/packages/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+func TestValidateBundle(t *testing.T) {
+	prvKey, err := crypto.GenerateKey()
+	testutil.Ok(t, err)
+	to := common.HexToAddress("0x1")
+	newTx := func(chainID int64, nonce, gas uint64, feeCap int64) string {
+		txHex, err := SignTx(big.NewInt(chainID), &types.DynamicFeeTx{
+			ChainID:   big.NewInt(chainID),
+			Nonce:     nonce,
+			To:        &to,
+			Gas:       gas,
+			GasTipCap: big.NewInt(0),
+			GasFeeCap: big.NewInt(feeCap),
+		}, prvKey)
+		testutil.Ok(t, err)
+		return txHex
+	}
+	cfg := ValidationConfig{ChainID: big.NewInt(1), BlockGasLimit: 100_000}
+
+	testutil.Ok(t, ValidateBundle([]string{newTx(1, 0, 21000, 1), newTx(1, 1, 21000, 1)}, cfg))
+
+	err = ValidateBundle([]string{
+		newTx(5, 0, 21000, 1),
+		"0x1234",
+		newTx(1, 2, 21000, 0),
+		newTx(1, 3, 200_000, 1),
+	}, cfg)
+	var validationErr *ValidationError
+	testutil.Assert(t, errors.As(err, &validationErr), "expected a validation error got:%v", err)
+	testutil.Assert(t, errors.Is(err, ErrInvalidBundle), "unexpected error:%v", err)
+
+	var indexes []int
+	for _, i := range validationErr.Issues {
+		indexes = append(indexes, i.Index)
+	}
+	// Chain id, encoding, nonce, zero gas price, tx gas limit and total gas limit.
+	testutil.Equals(t, []int{0, 1, 2, 2, 3, -1}, indexes)
+	testutil.Assert(t, validationErr.Issues[0].Hash != common.Hash{}, "the hash should be set for decoded txs")
+
+	err = ValidateBundle(nil, cfg)
+	testutil.Assert(t, errors.As(err, &validationErr), "expected a validation error got:%v", err)
+	testutil.Equals(t, -1, validationErr.Issues[0].Index)
+
+	// Validated before reaching the relay.
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, err := w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"bundleHash":"0x1"}}`))
+		testutil.Ok(t, err)
+	}))
+	defer srv.Close()
+	flashbot, err := New(nil, &Api{URL: srv.URL, SkipFlashbotsSignature: true, Validation: &cfg})
+	testutil.Ok(t, err)
+	_, err = flashbot.SendBundle(context.Background(), []string{newTx(5, 0, 21000, 1)}, 10, nil)
+	testutil.Assert(t, errors.Is(err, ErrInvalidBundle), "unexpected error:%v", err)
+	testutil.Equals(t, 0, calls)
+	_, err = flashbot.SendBundle(context.Background(), []string{newTx(1, 0, 21000, 1)}, 10, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, calls)
+}
+
+func TestSplitBundle(t *testing.T) {
+	var txsHex []string
+	for i := 0; i < 5; i++ {
+		txsHex = append(txsHex, signedTxHex(t))
+	}
+	raw, err := decodeTx(txsHex[0])
+	testutil.Ok(t, err)
+	size := int(raw.Size())
+
+	bundles, err := SplitBundle(txsHex, 10, ValidationConfig{MaxTxs: 2})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []BlockBundle{
+		{BlockNum: 10, Txs: txsHex[:2]},
+		{BlockNum: 11, Txs: txsHex[2:4]},
+		{BlockNum: 12, Txs: txsHex[4:]},
+	}, bundles)
+
+	bundles, err = SplitBundle(txsHex, 10, ValidationConfig{BlockGasLimit: 3 * 21000})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(bundles))
+	testutil.Equals(t, txsHex[3:], bundles[1].Txs)
+
+	bundles, err = SplitBundle(txsHex, 10, ValidationConfig{MaxSize: 2*size + 1})
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(bundles))
+	for _, b := range bundles {
+		testutil.Ok(t, ValidateBundle(b.Txs, ValidationConfig{MaxSize: 2*size + 1}))
+	}
+
+	bundles, err = SplitBundle(txsHex, 10, ValidationConfig{})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []BlockBundle{{BlockNum: 10, Txs: txsHex}}, bundles)
+
+	err = ValidateBundle(txsHex, ValidationConfig{MaxTxs: 2, MaxSize: size})
+	var validationErr *ValidationError
+	testutil.Assert(t, errors.As(err, &validationErr), "expected a validation error got:%v", err)
+	testutil.Equals(t, 2, len(validationErr.Issues))
+
+	_, err = SplitBundle(txsHex, 10, ValidationConfig{BlockGasLimit: 20000})
+	testutil.NotOk(t, err)
+	_, err = SplitBundle(nil, 10, ValidationConfig{})
+	testutil.NotOk(t, err)
+}